@@ -60,12 +60,20 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error { // nolint
 
 	dst.Status.FailureDomains = restored.Status.FailureDomains
 	dst.Status.Bastion.OSDisk.DiffDiskSettings = restored.Status.Bastion.OSDisk.DiffDiskSettings
+	dst.Status.Bastion.SecondaryIPAddresses = restored.Status.Bastion.SecondaryIPAddresses
+	dst.Status.Bastion.PowerState = restored.Status.Bastion.PowerState
+	dst.Status.Bastion.VMAgentStatus = restored.Status.Bastion.VMAgentStatus
+	dst.Spec.NetworkSpec.PrivateDNSZone = restored.Spec.NetworkSpec.PrivateDNSZone
+	dst.Spec.AvailabilitySets = restored.Spec.AvailabilitySets
+	dst.Spec.AllowedFailureDomains = restored.Spec.AllowedFailureDomains
+	dst.Status.Network.APIServerPrivateDNSName = restored.Status.Network.APIServerPrivateDNSName
 
 	for _, restoredSubnet := range restored.Spec.NetworkSpec.Subnets {
 		if restoredSubnet != nil {
 			for _, dstSubnet := range dst.Spec.NetworkSpec.Subnets {
 				if dstSubnet != nil && dstSubnet.Name == restoredSubnet.Name {
 					dstSubnet.RouteTable = restoredSubnet.RouteTable
+					dstSubnet.StaticIPAddressPool = restoredSubnet.StaticIPAddressPool
 
 					dstSubnet.SecurityGroup.IngressRules = restoredSubnet.SecurityGroup.IngressRules
 				}
@@ -166,6 +174,11 @@ func Convert_v1alpha2_Network_To_v1alpha3_Network(in *Network, out *infrav1alpha
 	return autoConvert_v1alpha2_Network_To_v1alpha3_Network(in, out, s)
 }
 
+// Convert_v1alpha3_Network_To_v1alpha2_Network.
+func Convert_v1alpha3_Network_To_v1alpha2_Network(in *infrav1alpha3.Network, out *Network, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1alpha3_Network_To_v1alpha2_Network(in, out, s)
+}
+
 // Convert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec.
 func Convert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec(in *NetworkSpec, out *infrav1alpha3.NetworkSpec, s apiconversion.Scope) error { //nolint
 	if err := Convert_v1alpha2_VnetSpec_To_v1alpha3_VnetSpec(&in.Vnet, &out.Vnet, s); err != nil {
@@ -214,6 +227,11 @@ func Convert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(in *infrav1alpha3.Subnet
 	return autoConvert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(in, out, s)
 }
 
+// Convert_v1alpha3_VM_To_v1alpha2_VM.
+func Convert_v1alpha3_VM_To_v1alpha2_VM(in *infrav1alpha3.VM, out *VM, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1alpha3_VM_To_v1alpha2_VM(in, out, s)
+}
+
 func Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(in *infrav1alpha3.SecurityGroup, out *SecurityGroup, s apiconversion.Scope) error {
 	out.ID = in.ID
 	out.Name = in.Name
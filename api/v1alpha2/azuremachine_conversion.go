@@ -39,6 +39,15 @@ func (src *AzureMachine) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	}
 
 	restoreAzureMachineSpec(&restored.Spec, &dst.Spec)
+	dst.Status.SecondaryIPAddresses = restored.Status.SecondaryIPAddresses
+	dst.Status.StaticIPAddress = restored.Status.StaticIPAddress
+	dst.Status.VMExtensions = restored.Status.VMExtensions
+	dst.Status.VMProvisioning = restored.Status.VMProvisioning
+	dst.Status.ZonePlacement = restored.Status.ZonePlacement
+	dst.Status.Image = restored.Status.Image
+	dst.Status.SSHPublicKeysHash = restored.Status.SSHPublicKeysHash
+	dst.Status.PowerState = restored.Status.PowerState
+	dst.Status.VMAgentStatus = restored.Status.VMAgentStatus
 
 	// Manual conversion for conditions
 	dst.SetConditions(restored.GetConditions())
@@ -57,6 +66,20 @@ func restoreAzureMachineSpec(restored, dst *infrav1alpha3.AzureMachineSpec) {
 		dst.AcceleratedNetworking = restored.AcceleratedNetworking
 	}
 	dst.FailureDomain = restored.FailureDomain
+	dst.ZonePlacementPolicy = restored.ZonePlacementPolicy
+	dst.UpdatePolicy = restored.UpdatePolicy
+	dst.PowerStatePolicy = restored.PowerStatePolicy
+	dst.PinImageVersion = restored.PinImageVersion
+	dst.AdminUsername = restored.AdminUsername
+	if len(restored.AdditionalSSHPublicKeys) != 0 {
+		dst.AdditionalSSHPublicKeys = restored.AdditionalSSHPublicKeys
+	}
+	if restored.SSHPublicKeysSecretRef != nil {
+		dst.SSHPublicKeysSecretRef = restored.SSHPublicKeysSecretRef
+	}
+	if restored.Image != nil && restored.Image.Marketplace != nil && dst.Image != nil && dst.Image.Marketplace != nil {
+		dst.Image.Marketplace.Plan = restored.Image.Marketplace.Plan
+	}
 	if restored.SpotVMOptions != nil {
 		dst.SpotVMOptions = restored.SpotVMOptions.DeepCopy()
 	}
@@ -64,6 +87,24 @@ func restoreAzureMachineSpec(restored, dst *infrav1alpha3.AzureMachineSpec) {
 		dst.DataDisks = restored.DataDisks
 	}
 	dst.OSDisk.DiffDiskSettings = restored.OSDisk.DiffDiskSettings
+	dst.EnableIPForwarding = restored.EnableIPForwarding
+	dst.SecondaryIPCount = restored.SecondaryIPCount
+	if len(restored.StaticIPAddressPool) != 0 {
+		dst.StaticIPAddressPool = restored.StaticIPAddressPool
+	}
+	if len(restored.AdditionalNetworkInterfaces) != 0 {
+		dst.AdditionalNetworkInterfaces = restored.AdditionalNetworkInterfaces
+	}
+	if restored.WindowsConfiguration != nil {
+		dst.WindowsConfiguration = restored.WindowsConfiguration
+	}
+	if len(restored.VMExtensions) != 0 {
+		dst.VMExtensions = restored.VMExtensions
+	}
+	dst.VerifyBootstrap = restored.VerifyBootstrap
+	if restored.BootDiagnostics != nil {
+		dst.BootDiagnostics = restored.BootDiagnostics
+	}
 }
 
 // ConvertFrom converts from the Hub version (v1alpha3) to this version.
@@ -176,11 +176,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.Network)(nil), (*Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_Network_To_v1alpha2_Network(a.(*v1alpha3.Network), b.(*Network), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*OSDisk)(nil), (*v1alpha3.OSDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_OSDisk_To_v1alpha3_OSDisk(a.(*OSDisk), b.(*v1alpha3.OSDisk), scope)
 	}); err != nil {
@@ -201,11 +196,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.VM)(nil), (*VM)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_VM_To_v1alpha2_VM(a.(*v1alpha3.VM), b.(*VM), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*VnetSpec)(nil), (*v1alpha3.VnetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_VnetSpec_To_v1alpha3_VnetSpec(a.(*VnetSpec), b.(*v1alpha3.VnetSpec), scope)
 	}); err != nil {
@@ -301,6 +291,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha3.Network)(nil), (*Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Network_To_v1alpha2_Network(a.(*v1alpha3.Network), b.(*Network), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1alpha3.OSDisk)(nil), (*OSDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(a.(*v1alpha3.OSDisk), b.(*OSDisk), scope)
 	}); err != nil {
@@ -316,6 +311,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha3.VM)(nil), (*VM)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_VM_To_v1alpha2_VM(a.(*v1alpha3.VM), b.(*VM), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -434,6 +434,8 @@ func autoConvert_v1alpha3_AzureClusterSpec_To_v1alpha2_AzureClusterSpec(in *v1al
 	out.Location = in.Location
 	// WARNING: in.ControlPlaneEndpoint requires manual conversion: does not exist in peer-type
 	out.AdditionalTags = *(*Tags)(unsafe.Pointer(&in.AdditionalTags))
+	// WARNING: in.AvailabilitySets requires manual conversion: does not exist in peer-type
+	// WARNING: in.AllowedFailureDomains requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -568,6 +570,7 @@ func autoConvert_v1alpha3_AzureMachineSpec_To_v1alpha2_AzureMachineSpec(in *v1al
 	if err := Convert_v1alpha3_AvailabilityZone_To_v1alpha2_AvailabilityZone(&in.AvailabilityZone, &out.AvailabilityZone, s); err != nil {
 		return err
 	}
+	// WARNING: in.ZonePlacementPolicy requires manual conversion: does not exist in peer-type
 	if in.Image != nil {
 		in, out := &in.Image, &out.Image
 		*out = new(Image)
@@ -577,18 +580,32 @@ func autoConvert_v1alpha3_AzureMachineSpec_To_v1alpha2_AzureMachineSpec(in *v1al
 	} else {
 		out.Image = nil
 	}
+	// WARNING: in.PinImageVersion requires manual conversion: does not exist in peer-type
 	// WARNING: in.Identity requires manual conversion: does not exist in peer-type
 	// WARNING: in.UserAssignedIdentities requires manual conversion: does not exist in peer-type
 	if err := Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(&in.OSDisk, &out.OSDisk, s); err != nil {
 		return err
 	}
+	// WARNING: in.UpdatePolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.PowerStatePolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.WindowsConfiguration requires manual conversion: does not exist in peer-type
 	// WARNING: in.DataDisks requires manual conversion: does not exist in peer-type
 	out.Location = in.Location
 	out.SSHPublicKey = in.SSHPublicKey
+	// WARNING: in.AdminUsername requires manual conversion: does not exist in peer-type
+	// WARNING: in.AdditionalSSHPublicKeys requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHPublicKeysSecretRef requires manual conversion: does not exist in peer-type
 	out.AdditionalTags = *(*Tags)(unsafe.Pointer(&in.AdditionalTags))
 	out.AllocatePublicIP = in.AllocatePublicIP
 	// WARNING: in.AcceleratedNetworking requires manual conversion: does not exist in peer-type
+	// WARNING: in.EnableIPForwarding requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecondaryIPCount requires manual conversion: does not exist in peer-type
+	// WARNING: in.StaticIPAddressPool requires manual conversion: does not exist in peer-type
+	// WARNING: in.AdditionalNetworkInterfaces requires manual conversion: does not exist in peer-type
 	// WARNING: in.SpotVMOptions requires manual conversion: does not exist in peer-type
+	// WARNING: in.VMExtensions requires manual conversion: does not exist in peer-type
+	// WARNING: in.VerifyBootstrap requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootDiagnostics requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -604,7 +621,16 @@ func autoConvert_v1alpha2_AzureMachineStatus_To_v1alpha3_AzureMachineStatus(in *
 func autoConvert_v1alpha3_AzureMachineStatus_To_v1alpha2_AzureMachineStatus(in *v1alpha3.AzureMachineStatus, out *AzureMachineStatus, s conversion.Scope) error {
 	out.Ready = in.Ready
 	out.Addresses = *(*[]v1.NodeAddress)(unsafe.Pointer(&in.Addresses))
+	// WARNING: in.SecondaryIPAddresses requires manual conversion: does not exist in peer-type
+	// WARNING: in.StaticIPAddress requires manual conversion: does not exist in peer-type
 	out.VMState = (*VMState)(unsafe.Pointer(in.VMState))
+	// WARNING: in.PowerState requires manual conversion: does not exist in peer-type
+	// WARNING: in.VMAgentStatus requires manual conversion: does not exist in peer-type
+	// WARNING: in.VMExtensions requires manual conversion: does not exist in peer-type
+	// WARNING: in.VMProvisioning requires manual conversion: does not exist in peer-type
+	// WARNING: in.ZonePlacement requires manual conversion: does not exist in peer-type
+	// WARNING: in.Image requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHPublicKeysHash requires manual conversion: does not exist in peer-type
 	// WARNING: in.FailureReason requires manual conversion: does not exist in peer-type
 	// WARNING: in.FailureMessage requires manual conversion: does not exist in peer-type
 	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
@@ -915,14 +941,10 @@ func autoConvert_v1alpha3_Network_To_v1alpha2_Network(in *v1alpha3.Network, out
 	if err := Convert_v1alpha3_PublicIP_To_v1alpha2_PublicIP(&in.APIServerIP, &out.APIServerIP, s); err != nil {
 		return err
 	}
+	// WARNING: in.APIServerPrivateDNSName requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha3_Network_To_v1alpha2_Network is an autogenerated conversion function.
-func Convert_v1alpha3_Network_To_v1alpha2_Network(in *v1alpha3.Network, out *Network, s conversion.Scope) error {
-	return autoConvert_v1alpha3_Network_To_v1alpha2_Network(in, out, s)
-}
-
 func autoConvert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec(in *NetworkSpec, out *v1alpha3.NetworkSpec, s conversion.Scope) error {
 	if err := Convert_v1alpha2_VnetSpec_To_v1alpha3_VnetSpec(&in.Vnet, &out.Vnet, s); err != nil {
 		return err
@@ -958,6 +980,7 @@ func autoConvert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec(in *v1alpha3.Netwo
 	} else {
 		out.Subnets = nil
 	}
+	// WARNING: in.PrivateDNSZone requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1067,6 +1090,7 @@ func autoConvert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(in *v1alpha3.SubnetS
 	out.Name = in.Name
 	out.CidrBlock = in.CidrBlock
 	out.InternalLBIPAddress = in.InternalLBIPAddress
+	// WARNING: in.StaticIPAddressPool requires manual conversion: does not exist in peer-type
 	if err := Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(&in.SecurityGroup, &out.SecurityGroup, s); err != nil {
 		return err
 	}
@@ -1113,15 +1137,13 @@ func autoConvert_v1alpha3_VM_To_v1alpha2_VM(in *v1alpha3.VM, out *VM, s conversi
 	out.State = VMState(in.State)
 	out.Identity = VMIdentity(in.Identity)
 	out.Tags = *(*Tags)(unsafe.Pointer(&in.Tags))
+	// WARNING: in.PowerState requires manual conversion: does not exist in peer-type
+	// WARNING: in.VMAgentStatus requires manual conversion: does not exist in peer-type
 	out.Addresses = *(*[]v1.NodeAddress)(unsafe.Pointer(&in.Addresses))
+	// WARNING: in.SecondaryIPAddresses requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha3_VM_To_v1alpha2_VM is an autogenerated conversion function.
-func Convert_v1alpha3_VM_To_v1alpha2_VM(in *v1alpha3.VM, out *VM, s conversion.Scope) error {
-	return autoConvert_v1alpha3_VM_To_v1alpha2_VM(in, out, s)
-}
-
 func autoConvert_v1alpha2_VnetSpec_To_v1alpha3_VnetSpec(in *VnetSpec, out *v1alpha3.VnetSpec, s conversion.Scope) error {
 	out.ResourceGroup = in.ResourceGroup
 	out.ID = in.ID
@@ -18,6 +18,8 @@ package v1alpha3
 
 import (
 	"fmt"
+
+	"k8s.io/utils/pointer"
 )
 
 const (
@@ -27,10 +29,24 @@ const (
 	DefaultControlPlaneSubnetCIDR = "10.0.0.0/16"
 	// DefaultNodeSubnetCIDR is the default Node Subnet CIDR
 	DefaultNodeSubnetCIDR = "10.1.0.0/16"
+	// DefaultFaultDomainCount is the default number of fault domains of an availability set
+	DefaultFaultDomainCount = 2
+	// DefaultUpdateDomainCount is the default number of update domains of an availability set
+	DefaultUpdateDomainCount = 5
 )
 
 func (c *AzureCluster) setDefaults() {
 	c.setNetworkSpecDefaults()
+	c.setAvailabilitySetsDefaults()
+}
+
+func (c *AzureCluster) setAvailabilitySetsDefaults() {
+	if c.Spec.AvailabilitySets.FaultDomainCount == nil {
+		c.Spec.AvailabilitySets.FaultDomainCount = pointer.Int32Ptr(DefaultFaultDomainCount)
+	}
+	if c.Spec.AvailabilitySets.UpdateDomainCount == nil {
+		c.Spec.AvailabilitySets.UpdateDomainCount = pointer.Int32Ptr(DefaultUpdateDomainCount)
+	}
 }
 
 func (c *AzureCluster) setNetworkSpecDefaults() {
@@ -23,6 +23,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestResourceGroupDefault(t *testing.T) {
@@ -80,6 +81,58 @@ func TestResourceGroupDefault(t *testing.T) {
 	}
 }
 
+func TestAvailabilitySetsDefaults(t *testing.T) {
+	cases := map[string]struct {
+		cluster *AzureCluster
+		output  *AzureCluster
+	}{
+		"default empty availability sets": {
+			cluster: &AzureCluster{
+				Spec: AzureClusterSpec{},
+			},
+			output: &AzureCluster{
+				Spec: AzureClusterSpec{
+					AvailabilitySets: AvailabilitySetsSpec{
+						FaultDomainCount:  pointer.Int32Ptr(DefaultFaultDomainCount),
+						UpdateDomainCount: pointer.Int32Ptr(DefaultUpdateDomainCount),
+					},
+				},
+			},
+		},
+		"don't change if set": {
+			cluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					AvailabilitySets: AvailabilitySetsSpec{
+						FaultDomainCount:  pointer.Int32Ptr(3),
+						UpdateDomainCount: pointer.Int32Ptr(20),
+					},
+				},
+			},
+			output: &AzureCluster{
+				Spec: AzureClusterSpec{
+					AvailabilitySets: AvailabilitySetsSpec{
+						FaultDomainCount:  pointer.Int32Ptr(3),
+						UpdateDomainCount: pointer.Int32Ptr(20),
+					},
+				},
+			},
+		},
+	}
+
+	for name := range cases {
+		c := cases[name]
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c.cluster.setAvailabilitySetsDefaults()
+			if !reflect.DeepEqual(c.cluster, c.output) {
+				expected, _ := json.MarshalIndent(c.output, "", "\t")
+				actual, _ := json.MarshalIndent(c.cluster, "", "\t")
+				t.Errorf("Expected %s, got %s", string(expected), string(actual))
+			}
+		})
+	}
+}
+
 func TestVnetDefaults(t *testing.T) {
 	cases := []struct {
 		name    string
@@ -48,6 +48,16 @@ type AzureClusterSpec struct {
 	// ones added by default.
 	// +optional
 	AdditionalTags Tags `json:"additionalTags,omitempty"`
+
+	// AvailabilitySets configures the availability sets machines are placed in when the location has no availability zones.
+	// Immutable.
+	// +optional
+	AvailabilitySets AvailabilitySetsSpec `json:"availabilitySets,omitempty"`
+
+	// AllowedFailureDomains restricts the failure domains the cluster may use to the listed availability zones,
+	// or fault domains when the location has no availability zones. If empty, all failure domains are used.
+	// +optional
+	AllowedFailureDomains []string `json:"allowedFailureDomains,omitempty"`
 }
 
 // AzureClusterStatus defines the observed state of AzureCluster
@@ -59,6 +69,7 @@ type AzureClusterStatus struct {
 	// Availability Zone is a separate data center within a region and they can be used to ensure
 	// the cluster is more resilient to failure.
 	// See: https://docs.microsoft.com/en-us/azure/availability-zones/az-overview
+	// If the region has no Availability Zones, a FailureDomain maps to a fault domain of the availability sets.
 	// This list will be used by Cluster API to try and spread the machines across the failure domains.
 	FailureDomains clusterv1.FailureDomains `json:"failureDomains,omitempty"`
 
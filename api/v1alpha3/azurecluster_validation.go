@@ -18,10 +18,13 @@ package v1alpha3
 
 import (
 	"fmt"
+	"net"
+	"reflect"
 	"regexp"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -33,10 +36,16 @@ const (
 	ipv4Regex   = `^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`
 )
 
-// validateCluster validates a cluster
-func (c *AzureCluster) validateCluster() error {
+// validateCluster validates a cluster, and the changes made to it when old is not nil
+func (c *AzureCluster) validateCluster(old *AzureCluster) error {
 	var allErrs field.ErrorList
 	allErrs = append(allErrs, c.validateClusterSpec()...)
+	if old != nil {
+		allErrs = append(allErrs, validatePrivateDNSZoneUpdate(old.Spec.NetworkSpec.PrivateDNSZone, c.Spec.NetworkSpec.PrivateDNSZone,
+			field.NewPath("spec").Child("networkSpec").Child("privateDNSZone"))...)
+		allErrs = append(allErrs, validateAvailabilitySetsUpdate(old.Spec.AvailabilitySets, c.Spec.AvailabilitySets,
+			field.NewPath("spec").Child("availabilitySets"))...)
+	}
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -48,9 +57,14 @@ func (c *AzureCluster) validateCluster() error {
 
 // validateClusterSpec validates a ClusterSpec
 func (c *AzureCluster) validateClusterSpec() field.ErrorList {
-	return validateNetworkSpec(
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateNetworkSpec(
 		c.Spec.NetworkSpec,
-		field.NewPath("spec").Child("networkSpec"))
+		field.NewPath("spec").Child("networkSpec"))...)
+	allErrs = append(allErrs, validateAllowedFailureDomains(
+		c.Spec.AllowedFailureDomains,
+		field.NewPath("spec").Child("allowedFailureDomains"))...)
+	return allErrs
 }
 
 // validateNetworkSpec validates a NetworkSpec
@@ -66,6 +80,9 @@ func validateNetworkSpec(networkSpec NetworkSpec, fldPath *field.Path) field.Err
 		}
 		allErrs = append(allErrs, validateSubnets(networkSpec.Subnets, fldPath.Child("subnets"))...)
 	}
+	if networkSpec.PrivateDNSZone != nil {
+		allErrs = append(allErrs, validatePrivateDNSZone(networkSpec.PrivateDNSZone, fldPath.Child("privateDNSZone"))...)
+	}
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -104,6 +121,7 @@ func validateSubnets(subnets Subnets, fldPath *field.Path) field.ErrorList {
 				allErrs = append(allErrs, err)
 			}
 		}
+		allErrs = append(allErrs, validateSubnetStaticIPAddressPool(subnet, fldPath.Index(i).Child("staticIPAddressPool"))...)
 		for role := range requiredSubnetRoles {
 			if role == string(subnet.Role) {
 				requiredSubnetRoles[role] = true
@@ -150,6 +168,90 @@ func validateInternalLBIPAddress(address string, fldPath *field.Path) *field.Err
 	return nil
 }
 
+// validateSubnetStaticIPAddressPool validates the static IP address pool of a subnet
+func validateSubnetStaticIPAddressPool(subnet *SubnetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := ValidateStaticIPAddressPool(subnet.StaticIPAddressPool, fldPath)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	var cidr *net.IPNet
+	if subnet.CidrBlock != "" {
+		if _, parsed, err := net.ParseCIDR(subnet.CidrBlock); err == nil {
+			cidr = parsed
+		}
+	}
+	for i, address := range subnet.StaticIPAddressPool {
+		if address == subnet.InternalLBIPAddress {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), address, "the static IP address is already used by the internal load balancer"))
+		}
+		if cidr != nil && !cidr.Contains(net.ParseIP(address)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), address, fmt.Sprintf("the static IP address is not in the subnet CIDR block %s", subnet.CidrBlock)))
+		}
+	}
+	return allErrs
+}
+
+// validatePrivateDNSZone validates a PrivateDNSZone
+func validatePrivateDNSZone(zone *PrivateDNSZone, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if zone.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), "name of the private DNS zone is required"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(zone.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), zone.Name, msg))
+		}
+	}
+	if zone.ResourceGroup != "" {
+		if err := validateResourceGroup(zone.ResourceGroup, fldPath.Child("resourceGroup")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	if zone.APIServerRecordName != "" {
+		for _, msg := range validation.IsDNS1123Label(zone.APIServerRecordName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("apiServerRecordName"), zone.APIServerRecordName, msg))
+		}
+	}
+	return allErrs
+}
+
+// validatePrivateDNSZoneUpdate rejects changes to the private DNS zone of an existing cluster, whose control plane
+// endpoint may already be set to the name of the API server in the zone.
+func validatePrivateDNSZoneUpdate(old, new *PrivateDNSZone, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !reflect.DeepEqual(old, new) {
+		allErrs = append(allErrs, field.Invalid(fldPath, new, "changing the private DNS zone after cluster creation is not allowed"))
+	}
+	return allErrs
+}
+
+// validateAvailabilitySetsUpdate rejects changes to the availability sets configuration of an existing cluster, which
+// Azure does not allow on availability sets that are already created.
+func validateAvailabilitySetsUpdate(old, new AvailabilitySetsSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !reflect.DeepEqual(old, new) {
+		allErrs = append(allErrs, field.Invalid(fldPath, new, "changing the availability sets after cluster creation is not allowed"))
+	}
+	return allErrs
+}
+
+// validateAllowedFailureDomains validates the list of failure domains a cluster may use
+func validateAllowedFailureDomains(failureDomains []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := make(map[string]bool, len(failureDomains))
+	for i, fd := range failureDomains {
+		if fd == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), fd, "failure domain must not be empty"))
+			continue
+		}
+		if seen[fd] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), fd))
+		}
+		seen[fd] = true
+	}
+	return allErrs
+}
+
 // validateIngressRule validates an IngressRule
 func validateIngressRule(ingressRule *IngressRule, fldPath *field.Path) *field.Error {
 	if ingressRule.Priority < 100 || ingressRule.Priority > 4096 {
@@ -21,6 +21,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 )
 
 func TestClusterWithPreexistingVnetValid(t *testing.T) {
@@ -37,7 +38,7 @@ func TestClusterWithPreexistingVnetValid(t *testing.T) {
 	}
 
 	t.Run(testCase.name, func(t *testing.T) {
-		err := testCase.cluster.validateCluster()
+		err := testCase.cluster.validateCluster(nil)
 		g.Expect(err).To(BeNil())
 	})
 }
@@ -62,7 +63,7 @@ func TestClusterWithPreexistingVnetInvalid(t *testing.T) {
 	}
 
 	t.Run(testCase.name, func(t *testing.T) {
-		err := testCase.cluster.validateCluster()
+		err := testCase.cluster.validateCluster(nil)
 		g.Expect(err).ToNot(BeNil())
 	})
 }
@@ -85,7 +86,7 @@ func TestClusterWithoutPreexistingVnetValid(t *testing.T) {
 	testCase.cluster.Spec.NetworkSpec.Vnet.ResourceGroup = ""
 
 	t.Run(testCase.name, func(t *testing.T) {
-		err := testCase.cluster.validateCluster()
+		err := testCase.cluster.validateCluster(nil)
 		g.Expect(err).To(BeNil())
 	})
 }
@@ -359,6 +360,253 @@ func TestSubnetsInvalidInternalLBIPAddress(t *testing.T) {
 	})
 }
 
+func TestSubnetsStaticIPAddressPool(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name          string
+		cidrBlock     string
+		lbIPAddress   string
+		pool          []string
+		expectedField string
+	}{
+		{
+			name:      "valid static IP address pool",
+			cidrBlock: "10.0.0.0/16",
+			pool:      []string{"10.0.0.10", "10.0.0.11"},
+		},
+		{
+			name:          "invalid static IP address",
+			pool:          []string{"10.0.0.300"},
+			expectedField: "spec.networkSpec.subnets[0].staticIPAddressPool[0]",
+		},
+		{
+			name:          "duplicate static IP address",
+			pool:          []string{"10.0.0.10", "10.0.0.10"},
+			expectedField: "spec.networkSpec.subnets[0].staticIPAddressPool[1]",
+		},
+		{
+			name:          "static IP address used by the internal load balancer",
+			lbIPAddress:   "10.0.0.100",
+			pool:          []string{"10.0.0.100"},
+			expectedField: "spec.networkSpec.subnets[0].staticIPAddressPool[0]",
+		},
+		{
+			name:          "static IP address outside of the subnet",
+			cidrBlock:     "10.0.0.0/24",
+			pool:          []string{"10.0.1.10"},
+			expectedField: "spec.networkSpec.subnets[0].staticIPAddressPool[0]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			subnets := createValidSubnets()
+			subnets[0].CidrBlock = tc.cidrBlock
+			subnets[0].InternalLBIPAddress = tc.lbIPAddress
+			subnets[0].StaticIPAddressPool = tc.pool
+			errs := validateSubnets(subnets,
+				field.NewPath("spec").Child("networkSpec").Child("subnets"))
+			if tc.expectedField == "" {
+				g.Expect(errs).To(HaveLen(0))
+			} else {
+				g.Expect(errs).To(HaveLen(1))
+				g.Expect(errs[0].Field).To(Equal(tc.expectedField))
+			}
+		})
+	}
+}
+
+func TestPrivateDNSZone(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name          string
+		zone          PrivateDNSZone
+		expectedField string
+	}{
+		{
+			name: "valid managed private DNS zone",
+			zone: PrivateDNSZone{Name: "cluster.internal", NodeRecords: true},
+		},
+		{
+			name: "valid existing private DNS zone",
+			zone: PrivateDNSZone{Name: "cluster.internal", ResourceGroup: "dns-rg", APIServerRecordName: "api"},
+		},
+		{
+			name:          "missing zone name",
+			zone:          PrivateDNSZone{},
+			expectedField: "spec.networkSpec.privateDNSZone.name",
+		},
+		{
+			name:          "invalid zone name",
+			zone:          PrivateDNSZone{Name: "Cluster_Internal"},
+			expectedField: "spec.networkSpec.privateDNSZone.name",
+		},
+		{
+			name:          "invalid resource group",
+			zone:          PrivateDNSZone{Name: "cluster.internal", ResourceGroup: "dns rg"},
+			expectedField: "spec.networkSpec.privateDNSZone.resourceGroup",
+		},
+		{
+			name:          "invalid API server record name",
+			zone:          PrivateDNSZone{Name: "cluster.internal", APIServerRecordName: "api.server"},
+			expectedField: "spec.networkSpec.privateDNSZone.apiServerRecordName",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			zone := tc.zone
+			errs := validatePrivateDNSZone(&zone,
+				field.NewPath("spec").Child("networkSpec").Child("privateDNSZone"))
+			if tc.expectedField == "" {
+				g.Expect(errs).To(HaveLen(0))
+			} else {
+				g.Expect(errs).To(HaveLen(1))
+				g.Expect(errs[0].Field).To(Equal(tc.expectedField))
+			}
+		})
+	}
+}
+
+func TestPrivateDNSZoneUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	zone := &PrivateDNSZone{Name: "cluster.internal"}
+	tests := []struct {
+		name    string
+		old     *PrivateDNSZone
+		new     *PrivateDNSZone
+		wantErr bool
+	}{
+		{
+			name: "no private DNS zone",
+		},
+		{
+			name: "unchanged private DNS zone",
+			old:  zone,
+			new:  zone.DeepCopy(),
+		},
+		{
+			name:    "private DNS zone added",
+			new:     zone,
+			wantErr: true,
+		},
+		{
+			name:    "changed private DNS zone",
+			old:     zone,
+			new:     &PrivateDNSZone{Name: "other.internal"},
+			wantErr: true,
+		},
+		{
+			name:    "removed private DNS zone",
+			old:     zone,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validatePrivateDNSZoneUpdate(tc.old, tc.new,
+				field.NewPath("spec").Child("networkSpec").Child("privateDNSZone"))
+			if tc.wantErr {
+				g.Expect(errs).To(HaveLen(1))
+			} else {
+				g.Expect(errs).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAvailabilitySetsUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	availabilitySets := AvailabilitySetsSpec{
+		FaultDomainCount:  pointer.Int32Ptr(2),
+		UpdateDomainCount: pointer.Int32Ptr(5),
+	}
+	tests := []struct {
+		name    string
+		new     AvailabilitySetsSpec
+		wantErr bool
+	}{
+		{
+			name: "unchanged availability sets",
+			new:  *availabilitySets.DeepCopy(),
+		},
+		{
+			name: "changed fault domain count",
+			new: AvailabilitySetsSpec{
+				FaultDomainCount:  pointer.Int32Ptr(3),
+				UpdateDomainCount: pointer.Int32Ptr(5),
+			},
+			wantErr: true,
+		},
+		{
+			name: "changed update domain count",
+			new: AvailabilitySetsSpec{
+				FaultDomainCount:  pointer.Int32Ptr(2),
+				UpdateDomainCount: pointer.Int32Ptr(10),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateAvailabilitySetsUpdate(availabilitySets, tc.new, field.NewPath("spec").Child("availabilitySets"))
+			if tc.wantErr {
+				g.Expect(errs).To(HaveLen(1))
+			} else {
+				g.Expect(errs).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAllowedFailureDomains(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name           string
+		failureDomains []string
+		expectedField  string
+	}{
+		{
+			name:           "valid zones",
+			failureDomains: []string{"1", "2"},
+		},
+		{
+			name:           "valid fault domains",
+			failureDomains: []string{"fd-0", "fd-1"},
+		},
+		{
+			name:           "empty failure domain",
+			failureDomains: []string{"1", ""},
+			expectedField:  "spec.allowedFailureDomains[1]",
+		},
+		{
+			name:           "duplicate failure domain",
+			failureDomains: []string{"1", "1"},
+			expectedField:  "spec.allowedFailureDomains[1]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateAllowedFailureDomains(tc.failureDomains,
+				field.NewPath("spec").Child("allowedFailureDomains"))
+			if tc.expectedField == "" {
+				g.Expect(errs).To(HaveLen(0))
+			} else {
+				g.Expect(errs).To(HaveLen(1))
+				g.Expect(errs[0].Field).To(Equal(tc.expectedField))
+			}
+		})
+	}
+}
+
 func TestSubnetsInvalidLackRequiredSubnet(t *testing.T) {
 	g := NewWithT(t)
 
@@ -92,6 +92,15 @@ func TestAzureCluster_ValidateCreate(t *testing.T) {
 			}(),
 			wantErr: true,
 		},
+		{
+			name: "azurecluster with private DNS zone",
+			cluster: func() *AzureCluster {
+				cluster := createValidCluster()
+				cluster.Spec.NetworkSpec.PrivateDNSZone = &PrivateDNSZone{Name: "cluster.internal"}
+				return cluster
+			}(),
+			wantErr: false,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
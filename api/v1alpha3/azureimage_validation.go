@@ -112,6 +112,24 @@ func validateMarketplaceImage(image *Image, fldPath *field.Path) field.ErrorList
 	if image.Marketplace.Version == "" {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("Version"), "", "Version cannot be empty when specifying an AzureMarketplaceImage"))
 	}
+	if image.Marketplace.Plan != nil {
+		allErrs = append(allErrs, validateImagePlan(image.Marketplace.Plan, fldPath.Child("Marketplace", "Plan"))...)
+	}
+	return allErrs
+}
+
+func validateImagePlan(plan *ImagePlan, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if plan.Publisher == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Publisher"), "", "Publisher cannot be empty when specifying an ImagePlan"))
+	}
+	if plan.Product == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Product"), "", "Product cannot be empty when specifying an ImagePlan"))
+	}
+	if plan.Name == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Name"), "", "Name cannot be empty when specifying an ImagePlan"))
+	}
 	return allErrs
 }
 
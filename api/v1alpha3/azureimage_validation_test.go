@@ -131,6 +131,42 @@ func TestMarketPlaceImageValid(t *testing.T) {
 	}
 }
 
+func TestMarketPlaceImagePlanValid(t *testing.T) {
+	g := NewWithT(t)
+
+	testCases := map[string]struct {
+		plan           *ImagePlan
+		expectedErrors int
+	}{
+		"ImagePlan - fully specified": {
+			expectedErrors: 0,
+			plan:           &ImagePlan{Publisher: "PUB1234", Product: "OFFER1234", Name: "SKU1234"},
+		},
+		"ImagePlan - missing publisher": {
+			expectedErrors: 1,
+			plan:           &ImagePlan{Product: "OFFER1234", Name: "SKU1234"},
+		},
+		"ImagePlan - missing product": {
+			expectedErrors: 1,
+			plan:           &ImagePlan{Publisher: "PUB1234", Name: "SKU1234"},
+		},
+		"ImagePlan - missing name": {
+			expectedErrors: 1,
+			plan:           &ImagePlan{Publisher: "PUB1234", Product: "OFFER1234"},
+		},
+		"ImagePlan - accepting terms": {
+			expectedErrors: 0,
+			plan:           &ImagePlan{Publisher: "PUB1234", Product: "OFFER1234", Name: "SKU1234", AcceptTerms: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		image := createTestMarketPlaceImage("PUB1234", "OFFER1234", "SKU1234", "1.0.0")
+		image.Marketplace.Plan = tc.plan
+		g.Expect(ValidateImage(image, field.NewPath("image"))).To(HaveLen(tc.expectedErrors))
+	}
+}
+
 func TestImageByIDValid(t *testing.T) {
 	g := NewWithT(t)
 
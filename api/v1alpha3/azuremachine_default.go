@@ -16,33 +16,6 @@ limitations under the License.
 
 package v1alpha3
 
-import (
-	"crypto/rand"
-	"crypto/rsa"
-	"encoding/base64"
-	"github.com/pkg/errors"
-	"golang.org/x/crypto/ssh"
-)
-
-// SetDefaultSSHPublicKey sets the default SSHPublicKey for an AzureMachine
-func (m *AzureMachine) SetDefaultSSHPublicKey() error {
-	sshKeyData := m.Spec.SSHPublicKey
-	if sshKeyData == "" {
-		privateKey, perr := rsa.GenerateKey(rand.Reader, 2048)
-		if perr != nil {
-			return errors.Wrap(perr, "Failed to generate private key")
-		}
-
-		publicRsaKey, perr := ssh.NewPublicKey(&privateKey.PublicKey)
-		if perr != nil {
-			return errors.Wrap(perr, "Failed to generate public key")
-		}
-		m.Spec.SSHPublicKey = base64.StdEncoding.EncodeToString(ssh.MarshalAuthorizedKey(publicRsaKey))
-	}
-
-	return nil
-}
-
 // SetDefaultsDataDisks sets the data disk defaults for an AzureMachine
 func (m *AzureMachine) SetDataDisksDefaults() {
 	set := make(map[int32]struct{})
@@ -21,30 +21,8 @@ import (
 	"github.com/Azure/go-autorest/autorest/to"
 	"reflect"
 	"testing"
-
-	. "github.com/onsi/gomega"
 )
 
-func TestAzureMachine_SetDefaultSSHPublicKey(t *testing.T) {
-	g := NewWithT(t)
-
-	type test struct {
-		machine *AzureMachine
-	}
-
-	existingPublicKey := "testpublickey"
-	publicKeyExistTest := test{machine: createMachineWithSSHPublicKey(t, existingPublicKey)}
-	publicKeyNotExistTest := test{machine: createMachineWithSSHPublicKey(t, "")}
-
-	err := publicKeyExistTest.machine.SetDefaultSSHPublicKey()
-	g.Expect(err).To(BeNil())
-	g.Expect(publicKeyExistTest.machine.Spec.SSHPublicKey).To(Equal(existingPublicKey))
-
-	err = publicKeyNotExistTest.machine.SetDefaultSSHPublicKey()
-	g.Expect(err).To(BeNil())
-	g.Expect(publicKeyNotExistTest.machine.Spec.SSHPublicKey).To(Not(BeEmpty()))
-}
-
 func TestAzureMachine_SetDataDisksDefaults(t *testing.T) {
 	cases := []struct {
 		name   string
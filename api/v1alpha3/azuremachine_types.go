@@ -27,6 +27,10 @@ const (
 	// MachineFinalizer allows ReconcileAzureMachine to clean up Azure resources associated with AzureMachine before
 	// removing it from the apiserver.
 	MachineFinalizer = "azuremachine.infrastructure.cluster.x-k8s.io"
+
+	// ResetVMProvisioningAnnotation resets the failed VM provisioning attempts of an AzureMachine when set, so that
+	// its VM is created again without waiting for the backoff. The annotation is removed once handled.
+	ResetVMProvisioningAnnotation = "azuremachine.infrastructure.cluster.x-k8s.io/reset-vm-provisioning"
 )
 
 // AzureMachineSpec defines the desired state of AzureMachine
@@ -44,13 +48,27 @@ type AzureMachineSpec struct {
 	// DEPRECATED: use FailureDomain instead
 	AvailabilityZone AvailabilityZone `json:"availabilityZone,omitempty"`
 
+	// ZonePlacementPolicy defines what happens when the virtual machine cannot be placed in the availability zone of
+	// its failure domain, because its VM size is not offered in the zone or its allocation failed in the zone.
+	// Strict fails the creation of the virtual machine, Fallback places it in another availability zone of the
+	// cluster. Defaults to Strict.
+	// +kubebuilder:validation:Enum=Strict;Fallback
+	// +optional
+	ZonePlacementPolicy ZonePlacementPolicy `json:"zonePlacementPolicy,omitempty"`
+
 	// Image is used to provide details of an image to use during VM creation.
 	// If image details are omitted the image will default the Azure Marketplace "capi" offer,
-	// which is based on Ubuntu.
+	// which is based on Ubuntu, or the "capi-windows" offer for Windows machines.
 	// +kubebuilder:validation:nullable
 	// +optional
 	Image *Image `json:"image,omitempty"`
 
+	// PinImageVersion pins the image of the machine to the version resolved for the first machine created from the
+	// same AzureMachineTemplate when the version of its image is "latest", so that all the machines of a
+	// MachineDeployment boot the same image. The resolved image is recorded on the template.
+	// +optional
+	PinImageVersion bool `json:"pinImageVersion,omitempty"`
+
 	// Identity is the type of identity used for the virtual machine.
 	// The type 'SystemAssigned' is an implicitly created identity.
 	// The generated identity will be assigned a Subscription contributor role.
@@ -69,12 +87,48 @@ type AzureMachineSpec struct {
 	// OSDisk specifies the parameters for the operating system disk of the machine
 	OSDisk OSDisk `json:"osDisk"`
 
+	// UpdatePolicy defines how changes to the VM size and the size of the OS disk of the machine are applied.
+	// Immutable rejects the changes, so that the machine is replaced instead. InPlace resizes the virtual machine and
+	// grows its OS disk, deallocating and restarting it if needed. Defaults to Immutable.
+	// +kubebuilder:validation:Enum=Immutable;InPlace
+	// +optional
+	UpdatePolicy UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// PowerStatePolicy defines what happens to the virtual machine of the machine when it is found stopped or
+	// deallocated, e.g. from the portal. Ignore leaves it stopped, and the machine not ready. Start starts it again.
+	// Defaults to Ignore.
+	// +kubebuilder:validation:Enum=Ignore;Start
+	// +optional
+	PowerStatePolicy PowerStatePolicy `json:"powerStatePolicy,omitempty"`
+
+	// WindowsConfiguration specifies the operating system settings of the machine when OSDisk.OSType is Windows.
+	// +optional
+	WindowsConfiguration *WindowsConfiguration `json:"windowsConfiguration,omitempty"`
+
 	// DataDisk specifies the parameters that are used to add one or more data disks to the machine
 	DataDisks []DataDisk `json:"dataDisks,omitempty"`
 
 	Location string `json:"location"`
 
-	SSHPublicKey string `json:"sshPublicKey"`
+	// SSHPublicKey is the SSH public key string base64 encoded to add to the virtual machine. Defaults to the public
+	// key generated for the cluster.
+	// +optional
+	SSHPublicKey string `json:"sshPublicKey,omitempty"`
+
+	// AdminUsername is the name of the administrator account of the machine. Defaults to capi.
+	// +optional
+	AdminUsername string `json:"adminUsername,omitempty"`
+
+	// AdditionalSSHPublicKeys are SSH public keys in the OpenSSH authorized_keys format authorized for the
+	// administrator account, in addition to SSHPublicKey. Changes are pushed to the existing virtual machine.
+	// +optional
+	AdditionalSSHPublicKeys []string `json:"additionalSSHPublicKeys,omitempty"`
+
+	// SSHPublicKeysSecretRef is a reference to a Secret in the namespace of the machine holding additional SSH
+	// public keys in the OpenSSH authorized_keys format under the `authorized_keys` key. Changes to the Secret are
+	// pushed to the existing virtual machine at the next reconciliation of the machine.
+	// +optional
+	SSHPublicKeysSecretRef *v1.LocalObjectReference `json:"sshPublicKeysSecretRef,omitempty"`
 
 	// AdditionalTags is an optional set of tags to add to an instance, in addition to the ones added by default by the
 	// Azure provider. If both the AzureCluster and the AzureMachine specify the same tag name with different values, the
@@ -93,9 +147,50 @@ type AzureMachineSpec struct {
 	// +optional
 	AcceleratedNetworking *bool `json:"acceleratedNetworking,omitempty"`
 
+	// EnableIPForwarding enables IP forwarding on the machine's network interfaces, allowing the VM to
+	// send and receive traffic not addressed to one of its own IP addresses.
+	// +optional
+	EnableIPForwarding bool `json:"enableIPForwarding,omitempty"`
+
+	// SecondaryIPCount is the number of secondary private IP addresses to allocate on the machine's
+	// primary network interface, in addition to the primary IP configuration.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SecondaryIPCount int32 `json:"secondaryIPCount,omitempty"`
+
+	// StaticIPAddressPool is a list of private IP addresses to statically assign to the primary network interface.
+	// Each machine claims an address of the pool that is not used by another machine of the cluster, and releases
+	// it when deleted. It is typically set on an AzureMachineTemplate to share a pool across its machines.
+	// If omitted, the StaticIPAddressPool of the machine's subnet is used, if any.
+	// +optional
+	StaticIPAddressPool []string `json:"staticIPAddressPool,omitempty"`
+
+	// AdditionalNetworkInterfaces specifies network interfaces to attach to the machine in addition to the primary
+	// network interface. They are attached in the order listed, after the primary and public network interfaces.
+	// +optional
+	AdditionalNetworkInterfaces []NetworkInterface `json:"additionalNetworkInterfaces,omitempty"`
+
 	// SpotVMOptions allows the ability to specify the Machine should use a Spot VM
 	// +optional
 	SpotVMOptions *SpotVMOptions `json:"spotVMOptions,omitempty"`
+
+	// VMExtensions specifies the VM extensions to install on the virtual machine once it is running.
+	// Extensions are updated when their spec or protected settings change, and removed when they are
+	// removed from the list.
+	// +optional
+	VMExtensions []VMExtension `json:"vmExtensions,omitempty"`
+
+	// VerifyBootstrap installs a VM extension on Linux machines that waits for the bootstrap of the machine to
+	// complete, and only marks the machine as ready once the bootstrap provider reported its success.
+	// A failed bootstrap is reported in the BootstrapSucceeded condition and the failure reason of the machine.
+	// Cannot be changed after the machine is created.
+	// +optional
+	VerifyBootstrap bool `json:"verifyBootstrap,omitempty"`
+
+	// BootDiagnostics enables the boot diagnostics of the virtual machine. When the virtual machine fails to
+	// provision or to bootstrap, the end of its serial console log is stored in a Secret before it is deleted.
+	// +optional
+	BootDiagnostics *BootDiagnostics `json:"bootDiagnostics,omitempty"`
 }
 
 // SpotVMOptions defines the options relevant to running the Machine on Spot VMs
@@ -106,6 +201,71 @@ type SpotVMOptions struct {
 	MaxPrice *string `json:"maxPrice,omitempty"`
 }
 
+// ZonePlacementPolicy defines how the availability zone of a virtual machine is chosen when the zone of its failure
+// domain cannot be used.
+type ZonePlacementPolicy string
+
+const (
+	// ZonePlacementPolicyStrict fails the creation of a virtual machine that cannot be placed in its zone.
+	ZonePlacementPolicyStrict ZonePlacementPolicy = "Strict"
+	// ZonePlacementPolicyFallback places a virtual machine that cannot be placed in its zone in another zone of the
+	// failure domains of the cluster.
+	ZonePlacementPolicyFallback ZonePlacementPolicy = "Fallback"
+)
+
+// UpdatePolicy defines how changes to the size of a machine are applied.
+type UpdatePolicy string
+
+const (
+	// UpdatePolicyImmutable rejects changes to the size of a machine.
+	UpdatePolicyImmutable UpdatePolicy = "Immutable"
+	// UpdatePolicyInPlace resizes the virtual machine and grows the OS disk of an existing machine.
+	UpdatePolicyInPlace UpdatePolicy = "InPlace"
+)
+
+// PowerStatePolicy defines what happens to a virtual machine found stopped or deallocated.
+type PowerStatePolicy string
+
+const (
+	// PowerStatePolicyIgnore leaves a stopped or deallocated virtual machine as it is.
+	PowerStatePolicyIgnore PowerStatePolicy = "Ignore"
+	// PowerStatePolicyStart starts a stopped or deallocated virtual machine again.
+	PowerStatePolicyStart PowerStatePolicy = "Start"
+)
+
+// ZonePlacementReason describes why a virtual machine is placed in an availability zone.
+type ZonePlacementReason string
+
+const (
+	// ZonePlacementReasonFailureDomain is used when the virtual machine is placed in the zone of its failure domain.
+	ZonePlacementReasonFailureDomain ZonePlacementReason = "FailureDomain"
+	// ZonePlacementReasonSelected is used when the machine has no failure domain and the virtual machine is placed
+	// in the first zone offering its VM size.
+	ZonePlacementReasonSelected ZonePlacementReason = "Selected"
+	// ZonePlacementReasonFallback is used when the virtual machine is placed in another zone than the zone of its
+	// failure domain.
+	ZonePlacementReasonFallback ZonePlacementReason = "Fallback"
+	// ZonePlacementReasonNoZone is used when the virtual machine is not placed in an availability zone, because its
+	// VM size is not offered in any zone of the location, its failure domain is not a zone or zones are disabled.
+	ZonePlacementReasonNoZone ZonePlacementReason = "NoZone"
+)
+
+// ZonePlacementStatus describes the availability zone in which the virtual machine is placed.
+type ZonePlacementStatus struct {
+	// Zone is the availability zone of the virtual machine, empty if it is not placed in a zone.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+	// Reason describes why the virtual machine is placed in the zone.
+	Reason ZonePlacementReason `json:"reason"`
+	// Message gives the details of the placement, such as why the zone of the failure domain was not used.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// ExcludedZones are the zones in which the allocation of the virtual machine failed, which are not used again
+	// with the Fallback zone placement policy.
+	// +optional
+	ExcludedZones []string `json:"excludedZones,omitempty"`
+}
+
 // AzureMachineStatus defines the observed state of AzureMachine
 type AzureMachineStatus struct {
 	// Ready is true when the provider resource is ready.
@@ -115,10 +275,48 @@ type AzureMachineStatus struct {
 	// Addresses contains the Azure instance associated addresses.
 	Addresses []v1.NodeAddress `json:"addresses,omitempty"`
 
+	// SecondaryIPAddresses contains the secondary private IP addresses allocated to the machine's network interfaces.
+	// +optional
+	SecondaryIPAddresses []string `json:"secondaryIPAddresses,omitempty"`
+
+	// StaticIPAddress is the private IP address claimed by the machine from its static IP address pool.
+	// +optional
+	StaticIPAddress string `json:"staticIPAddress,omitempty"`
+
 	// VMState is the provisioning state of the Azure virtual machine.
 	// +optional
 	VMState *VMState `json:"vmState,omitempty"`
 
+	// PowerState is the power state of the Azure virtual machine, from its instance view.
+	// +optional
+	PowerState VMPowerState `json:"powerState,omitempty"`
+
+	// VMAgentStatus is the status of the VM agent running on the Azure virtual machine, from its instance view.
+	// +optional
+	VMAgentStatus string `json:"vmAgentStatus,omitempty"`
+
+	// VMExtensions is the provisioning state of the VM extensions installed on the virtual machine.
+	// +optional
+	VMExtensions []VMExtensionStatus `json:"vmExtensions,omitempty"`
+
+	// VMProvisioning records the failed attempts to provision the virtual machine, if any.
+	// +optional
+	VMProvisioning *VMProvisioningStatus `json:"vmProvisioning,omitempty"`
+
+	// ZonePlacement describes the availability zone chosen for the virtual machine.
+	// +optional
+	ZonePlacement *ZonePlacementStatus `json:"zonePlacement,omitempty"`
+
+	// Image is the image the virtual machine was created from, with a "latest" Marketplace image version resolved
+	// to the concrete version used.
+	// +optional
+	Image *Image `json:"image,omitempty"`
+
+	// SSHPublicKeysHash is a hash of the SSH public keys the virtual machine was created with. The keys are pushed to
+	// the virtual machine once they differ.
+	// +optional
+	SSHPublicKeysHash string `json:"sshPublicKeysHash,omitempty"`
+
 	// ErrorReason will be set in the event that there is a terminal problem
 	// reconciling the Machine and will contain a succinct value suitable
 	// for machine interpretation.
@@ -19,15 +19,46 @@ package v1alpha3
 import (
 	"encoding/base64"
 	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
 
 	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
-// ValidateSSHKey validates an SSHKey
+// MaxSecondaryIPCount is the maximum number of secondary IP configurations on a single network interface.
+const MaxSecondaryIPCount = 255
+
+// linuxAdminUsernameRegex matches the name of the administrator account of a Linux machine.
+var linuxAdminUsernameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// windowsAdminUsernameRegex matches the name of the administrator account of a Windows machine.
+var windowsAdminUsernameRegex = regexp.MustCompile(`^[^\\"/\[\]:|<>+=;,?*@]{1,20}$`)
+
+// reservedAdminUsernames are the names Azure does not allow for the administrator account of a virtual machine.
+var reservedAdminUsernames = map[string]struct{}{
+	"1": {}, "123": {}, "a": {}, "actuser": {}, "adm": {}, "admin": {}, "admin1": {}, "admin2": {},
+	"administrator": {}, "aspnet": {}, "backup": {}, "console": {}, "david": {}, "guest": {}, "john": {},
+	"owner": {}, "root": {}, "server": {}, "sql": {}, "support": {}, "support_388945a0": {}, "sys": {},
+	"test": {}, "test1": {}, "test2": {}, "test3": {}, "user": {}, "user1": {}, "user2": {}, "user3": {},
+	"user4": {}, "user5": {},
+}
+
+// storageAccountNameRegex matches the name of a storage account.
+var storageAccountNameRegex = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+
+// ValidateSSHKey validates an SSHKey. An empty key is valid, the machine then defaults to the public key generated
+// for its cluster.
 func ValidateSSHKey(sshKey string, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
+	if sshKey == "" {
+		return allErrs
+	}
+
 	decoded, err := base64.StdEncoding.DecodeString(sshKey)
 	if err != nil {
 		allErrs = append(allErrs, field.Invalid(fldPath, sshKey, "the SSH public key is not properly base64 encoded"))
@@ -42,6 +73,61 @@ func ValidateSSHKey(sshKey string, fldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
+// ValidateAdminUsername validates the name of the administrator account of a machine with the given OS type.
+func ValidateAdminUsername(osType, username string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if username == "" {
+		return allErrs
+	}
+
+	if _, ok := reservedAdminUsernames[strings.ToLower(username)]; ok {
+		allErrs = append(allErrs, field.Invalid(fieldPath, username, "the administrator username is reserved by Azure"))
+		return allErrs
+	}
+	if osType == WindowsOSType {
+		if !windowsAdminUsernameRegex.MatchString(username) || strings.HasSuffix(username, ".") {
+			allErrs = append(allErrs, field.Invalid(fieldPath, username,
+				`the administrator username of a Windows machine must be at most 20 characters, cannot contain any of \"/[]:|<>+=;,?*@ and cannot end with a period`))
+		}
+	} else if !linuxAdminUsernameRegex.MatchString(username) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, username,
+			"the administrator username of a Linux machine must start with a lowercase letter or an underscore, followed by at most 31 lowercase letters, digits, underscores or hyphens"))
+	}
+	return allErrs
+}
+
+// ValidateAdminUsernameUpdate validates that the administrator username of a machine is not changed.
+func ValidateAdminUsernameUpdate(old, new string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if old != new {
+		allErrs = append(allErrs, field.Invalid(fieldPath, new, "changing the administrator username after machine creation is not allowed"))
+	}
+	return allErrs
+}
+
+// ValidateAdditionalSSHPublicKeys validates a list of SSH public keys in the OpenSSH authorized_keys format.
+func ValidateAdditionalSSHPublicKeys(keys []string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, key := range keys {
+		_, _, _, rest, err := ssh.ParseAuthorizedKey([]byte(key))
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i), key, "the SSH public key is not valid"))
+		} else if strings.TrimSpace(string(rest)) != "" {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i), key, "each entry must hold a single SSH public key"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateSSHPublicKeysSecretRef validates the reference to the Secret holding additional SSH public keys.
+func ValidateSSHPublicKeysSecretRef(ref *corev1.LocalObjectReference, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if ref != nil && ref.Name == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("name"), "the name of the SSH public keys secret cannot be empty"))
+	}
+	return allErrs
+}
+
 // ValidateUserAssignedIdentity validates the user-assigned identities list
 func ValidateUserAssignedIdentity(identityType VMIdentity, userAssignedIdenteties []UserAssignedIdentity, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
@@ -52,12 +138,90 @@ func ValidateUserAssignedIdentity(identityType VMIdentity, userAssignedIdentetie
 	return allErrs
 }
 
+// ValidateSecondaryIPCount validates the number of secondary IP configurations requested for a network interface.
+// Azure allows at most 256 IP configurations per network interface, one of which is the primary.
+func ValidateSecondaryIPCount(count int32, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if count < 0 || count > MaxSecondaryIPCount {
+		allErrs = append(allErrs, field.Invalid(fldPath, count, fmt.Sprintf("the secondary IP count should be a value between 0 and %d", MaxSecondaryIPCount)))
+	}
+	return allErrs
+}
+
+// ValidateSpotVMOptions validates the Spot VM options of a machine. The compute API version of the Azure Stack Hub
+// profile has no priority on virtual machines, so Spot VMs are rejected.
+func ValidateSpotVMOptions(spotVMOptions *SpotVMOptions, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spotVMOptions != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "Spot VMs are not supported by the Azure Stack Hub API profile"))
+	}
+	return allErrs
+}
+
+// ValidateSpotVMOptionsUpdate validates the Spot VM options of a machine when they are changed. Machines created
+// with Spot VM options before they were rejected can still be updated, and deleted, as long as the options are
+// left unchanged.
+func ValidateSpotVMOptionsUpdate(old, new *SpotVMOptions, fldPath *field.Path) field.ErrorList {
+	if reflect.DeepEqual(old, new) {
+		return field.ErrorList{}
+	}
+	return ValidateSpotVMOptions(new, fldPath)
+}
+
+// ValidateNetworkInterfaces validates a list of additional network interfaces
+func ValidateNetworkInterfaces(nics []NetworkInterface, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	nameSet := make(map[string]struct{})
+	for i, nic := range nics {
+		nicPath := fieldPath.Index(i)
+
+		// validate that all names are unique and do not collide with the public network interface
+		if nic.NameSuffix == "" {
+			allErrs = append(allErrs, field.Required(nicPath.Child("nameSuffix"), "the name suffix cannot be empty"))
+		} else if nic.NameSuffix == "public" {
+			allErrs = append(allErrs, field.Invalid(nicPath.Child("nameSuffix"), nic.NameSuffix, "the name suffix is reserved for the public network interface"))
+		}
+		if _, ok := nameSet[nic.NameSuffix]; ok {
+			allErrs = append(allErrs, field.Duplicate(nicPath.Child("nameSuffix"), nic.NameSuffix))
+		} else {
+			nameSet[nic.NameSuffix] = struct{}{}
+		}
+
+		if nic.SubnetName == "" {
+			allErrs = append(allErrs, field.Required(nicPath.Child("subnetName"), "the subnet name cannot be empty"))
+		}
+
+		if nic.StaticIPAddress != "" && net.ParseIP(nic.StaticIPAddress) == nil {
+			allErrs = append(allErrs, field.Invalid(nicPath.Child("staticIPAddress"), nic.StaticIPAddress, "the static IP address is not a valid IP address"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateStaticIPAddressPool validates a pool of static private IP addresses
+func ValidateStaticIPAddressPool(pool []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	ipSet := make(map[string]struct{})
+	for i, address := range pool {
+		if net.ParseIP(address) == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), address, "the static IP address is not a valid IP address"))
+		}
+		if _, ok := ipSet[address]; ok {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), address))
+		} else {
+			ipSet[address] = struct{}{}
+		}
+	}
+	return allErrs
+}
+
 // ValidateDataDisks validates a list of data disks
 func ValidateDataDisks(dataDisks []DataDisk, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	lunSet := make(map[int32]struct{})
 	nameSet := make(map[string]struct{})
-	for _, disk := range dataDisks {
+	for i, disk := range dataDisks {
 		// validate that the disk size is between 4 and 32767.
 		if disk.DiskSizeGB < 4 || disk.DiskSizeGB > 32767 {
 			allErrs = append(allErrs, field.Invalid(fieldPath.Child("DiskSizeGB"), "", "the disk size should be a value between 4 and 32767"))
@@ -83,7 +247,49 @@ func ValidateDataDisks(dataDisks []DataDisk, fieldPath *field.Path) field.ErrorL
 		} else {
 			lunSet[*disk.Lun] = struct{}{}
 		}
+
+		allErrs = append(allErrs, validateDataDiskOptions(disk, fieldPath.Index(i))...)
+	}
+	return allErrs
+}
+
+// ValidateDataDiskRetention validates that none of the data disks of a machine pool are retained on delete,
+// as the data disks of a scale set instance are always deleted with the instance.
+func ValidateDataDiskRetention(dataDisks []DataDisk, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, disk := range dataDisks {
+		if disk.RetainOnDelete {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i).Child("retainOnDelete"), "data disks of machine pools cannot be retained on delete"))
+		}
+	}
+	return allErrs
+}
+
+func validateDataDiskOptions(disk DataDisk, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if disk.ManagedDisk != nil {
+		allErrs = append(allErrs, validateStorageAccountType(disk.ManagedDisk.StorageAccountType, fieldPath)...)
+	}
+
+	switch disk.CachingType {
+	case "", DataDiskCachingNone, DataDiskCachingReadOnly, DataDiskCachingReadWrite:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("cachingType"), disk.CachingType,
+			[]string{DataDiskCachingNone, DataDiskCachingReadOnly, DataDiskCachingReadWrite}))
+	}
+
+	if disk.WriteAcceleratorEnabled {
+		if disk.ManagedDisk == nil || disk.ManagedDisk.StorageAccountType != "Premium_LRS" {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("writeAcceleratorEnabled"), disk.WriteAcceleratorEnabled,
+				"write accelerator requires a Premium_LRS managed disk"))
+		}
+		if disk.CachingType == DataDiskCachingReadWrite {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("writeAcceleratorEnabled"), disk.WriteAcceleratorEnabled,
+				"write accelerator cannot be used with ReadWrite caching"))
+		}
 	}
+
 	return allErrs
 }
 
@@ -97,6 +303,8 @@ func ValidateOSDisk(osDisk OSDisk, fieldPath *field.Path) field.ErrorList {
 
 	if osDisk.OSType == "" {
 		allErrs = append(allErrs, field.Required(fieldPath.Child("OSType"), "the OS type cannot be empty"))
+	} else if osDisk.OSType != LinuxOSType && osDisk.OSType != WindowsOSType {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("OSType"), osDisk.OSType, []string{LinuxOSType, WindowsOSType}))
 	}
 
 	allErrs = append(allErrs, validateStorageAccountType(osDisk.ManagedDisk.StorageAccountType, fieldPath)...)
@@ -105,11 +313,26 @@ func ValidateOSDisk(osDisk OSDisk, fieldPath *field.Path) field.ErrorList {
 		allErrs = append(allErrs, errs...)
 	}
 
+	allErrs = append(allErrs, ValidateEphemeralOSDisk(osDisk, fieldPath)...)
+
+	return allErrs
+}
+
+// ValidateEphemeralOSDisk validates the DiffDiskSettings of an OSDisk. The compute API version of the Azure Stack Hub
+// profile has no diff disk settings on OS disks, so ephemeral OS disks are rejected.
+func ValidateEphemeralOSDisk(osDisk OSDisk, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if osDisk.DiffDiskSettings == nil {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, field.Forbidden(fieldPath.Child("diffDiskSettings"), "ephemeral OS disks are not supported by the Azure Stack Hub API profile"))
+
 	if err := validateDiffDiskSetings(osDisk.DiffDiskSettings, fieldPath.Child("diffDiskSettings")); err != nil {
 		allErrs = append(allErrs, err)
 	}
 
-	if osDisk.DiffDiskSettings != nil && osDisk.DiffDiskSettings.Option == "Local" && osDisk.ManagedDisk.StorageAccountType != "Standard_LRS" {
+	if osDisk.DiffDiskSettings.Option == "Local" && osDisk.ManagedDisk.StorageAccountType != "Standard_LRS" {
 		allErrs = append(allErrs, field.Invalid(
 			fieldPath.Child("managedDisks").Child("storageAccountType"),
 			osDisk.ManagedDisk.StorageAccountType,
@@ -120,6 +343,117 @@ func ValidateOSDisk(osDisk OSDisk, fieldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
+// ValidateWindowsConfiguration validates the WindowsConfiguration of a machine with the given OS type.
+func ValidateWindowsConfiguration(osType string, config *WindowsConfiguration, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if config == nil {
+		return allErrs
+	}
+
+	if osType != WindowsOSType {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "windowsConfiguration can only be set when the OS type is Windows"))
+		return allErrs
+	}
+
+	if config.AdminPasswordSecretRef != nil && config.AdminPasswordSecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("adminPasswordSecretRef", "name"), "the name of the admin password secret cannot be empty"))
+	}
+
+	switch config.RemoteAccess {
+	case "", WindowsRemoteAccessOpenSSH, WindowsRemoteAccessWinRM:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("remoteAccess"), config.RemoteAccess,
+			[]string{string(WindowsRemoteAccessOpenSSH), string(WindowsRemoteAccessWinRM)}))
+	}
+
+	return allErrs
+}
+
+// ValidateVerifyBootstrap validates that the bootstrap of a machine with the given OS type can be verified.
+func ValidateVerifyBootstrap(osType string, verifyBootstrap bool, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if verifyBootstrap && osType == WindowsOSType {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "the bootstrap of Windows machines cannot be verified"))
+	}
+	return allErrs
+}
+
+// ValidateVerifyBootstrapUpdate validates updates to the VerifyBootstrap field.
+func ValidateVerifyBootstrapUpdate(old, new bool, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if old != new {
+		allErrs = append(allErrs, field.Invalid(fieldPath, new, "changing bootstrap verification after machine creation is not allowed"))
+	}
+	return allErrs
+}
+
+// ValidateVMExtensions validates a list of VM extensions.
+func ValidateVMExtensions(extensions []VMExtension, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	nameSet := make(map[string]struct{})
+	for i, ext := range extensions {
+		extPath := fieldPath.Index(i)
+		if ext.Name == "" {
+			allErrs = append(allErrs, field.Required(extPath.Child("name"), "the extension name cannot be empty"))
+		} else if strings.HasPrefix(ext.Name, ReservedVMExtensionNamePrefix) {
+			allErrs = append(allErrs, field.Invalid(extPath.Child("name"), ext.Name,
+				fmt.Sprintf("the %q name prefix is reserved for the extensions installed by the provider", ReservedVMExtensionNamePrefix)))
+		} else if _, ok := nameSet[ext.Name]; ok {
+			allErrs = append(allErrs, field.Duplicate(extPath.Child("name"), ext.Name))
+		} else {
+			nameSet[ext.Name] = struct{}{}
+		}
+
+		if ext.Publisher == "" {
+			allErrs = append(allErrs, field.Required(extPath.Child("publisher"), "the extension publisher cannot be empty"))
+		}
+		if ext.Type == "" {
+			allErrs = append(allErrs, field.Required(extPath.Child("type"), "the extension type cannot be empty"))
+		}
+		if ext.Version == "" {
+			allErrs = append(allErrs, field.Required(extPath.Child("version"), "the extension version cannot be empty"))
+		}
+		if ext.ProtectedSettingsRef != nil && ext.ProtectedSettingsRef.Name == "" {
+			allErrs = append(allErrs, field.Required(extPath.Child("protectedSettingsRef", "name"), "the name of the protected settings secret cannot be empty"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateBootDiagnostics validates the boot diagnostics of a machine.
+func ValidateBootDiagnostics(bootDiagnostics *BootDiagnostics, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if bootDiagnostics == nil {
+		return allErrs
+	}
+
+	if !storageAccountNameRegex.MatchString(bootDiagnostics.StorageAccount.Name) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("storageAccount", "name"), bootDiagnostics.StorageAccount.Name,
+			"the storage account name must be between 3 and 24 characters long and use numbers and lower-case letters only"))
+	}
+	return allErrs
+}
+
+// ValidateSizeUpdate validates that the VM size and the OS disk size of a machine are only changed under the InPlace
+// update policy, and that the OS disk is never shrunk.
+func ValidateSizeUpdate(old, new AzureMachineSpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+	inPlace := new.UpdatePolicy == UpdatePolicyInPlace
+
+	if old.VMSize != new.VMSize && !inPlace {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("vmSize"), new.VMSize, "changing the VM size after machine creation is only allowed with the InPlace update policy"))
+	}
+
+	diskSizePath := field.NewPath("osDisk").Child("diskSizeGB")
+	if new.OSDisk.DiskSizeGB < old.OSDisk.DiskSizeGB {
+		allErrs = append(allErrs, field.Invalid(diskSizePath, new.OSDisk.DiskSizeGB, "shrinking the OS disk is not allowed"))
+	} else if old.OSDisk.DiskSizeGB != new.OSDisk.DiskSizeGB && !inPlace {
+		allErrs = append(allErrs, field.Invalid(diskSizePath, new.OSDisk.DiskSizeGB, "growing the OS disk after machine creation is only allowed with the InPlace update policy"))
+	}
+
+	return allErrs
+}
+
 // ValidateManagedDisk validates updates to the ManagedDisk field.
 func ValidateManagedDisk(old, new ManagedDisk, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
@@ -27,6 +27,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -48,6 +49,11 @@ func TestAzureMachine_ValidateSSHKey(t *testing.T) {
 			sshKey:  "invalid ssh key",
 			wantErr: true,
 		},
+		{
+			name:    "empty ssh key",
+			sshKey:  "",
+			wantErr: false,
+		},
 	}
 
 	for _, tc := range tests {
@@ -62,6 +68,667 @@ func TestAzureMachine_ValidateSSHKey(t *testing.T) {
 	}
 }
 
+func TestAzureMachine_ValidateSecondaryIPCount(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		count   int32
+		wantErr bool
+	}{
+		{
+			name:    "no secondary IPs",
+			count:   0,
+			wantErr: false,
+		},
+		{
+			name:    "maximum number of secondary IPs",
+			count:   MaxSecondaryIPCount,
+			wantErr: false,
+		},
+		{
+			name:    "negative secondary IP count",
+			count:   -1,
+			wantErr: true,
+		},
+		{
+			name:    "too many secondary IPs",
+			count:   MaxSecondaryIPCount + 1,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateSecondaryIPCount(tc.count, field.NewPath("secondaryIPCount"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateSpotVMOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name        string
+		spotOptions *SpotVMOptions
+		wantErr     bool
+	}{
+		{
+			name:        "no Spot VM options",
+			spotOptions: nil,
+			wantErr:     false,
+		},
+		{
+			name:        "Spot VM options with default values",
+			spotOptions: &SpotVMOptions{},
+			wantErr:     true,
+		},
+		{
+			name:        "Spot VM options with max price",
+			spotOptions: &SpotVMOptions{MaxPrice: to.StringPtr("0.04")},
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateSpotVMOptions(tc.spotOptions, field.NewPath("spotVMOptions"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateSpotVMOptionsUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		old     *SpotVMOptions
+		new     *SpotVMOptions
+		wantErr bool
+	}{
+		{
+			name:    "no Spot VM options",
+			old:     nil,
+			new:     nil,
+			wantErr: false,
+		},
+		{
+			name:    "unchanged Spot VM options",
+			old:     &SpotVMOptions{MaxPrice: to.StringPtr("0.04")},
+			new:     &SpotVMOptions{MaxPrice: to.StringPtr("0.04")},
+			wantErr: false,
+		},
+		{
+			name:    "added Spot VM options",
+			old:     nil,
+			new:     &SpotVMOptions{},
+			wantErr: true,
+		},
+		{
+			name:    "removed Spot VM options",
+			old:     &SpotVMOptions{},
+			new:     nil,
+			wantErr: false,
+		},
+		{
+			name:    "changed Spot VM options",
+			old:     &SpotVMOptions{},
+			new:     &SpotVMOptions{MaxPrice: to.StringPtr("0.04")},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateSpotVMOptionsUpdate(tc.old, tc.new, field.NewPath("spotVMOptions"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateWindowsConfiguration(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		osType  string
+		config  *WindowsConfiguration
+		wantErr bool
+	}{
+		{
+			name:    "no windows configuration",
+			osType:  LinuxOSType,
+			config:  nil,
+			wantErr: false,
+		},
+		{
+			name:    "default windows configuration",
+			osType:  WindowsOSType,
+			config:  &WindowsConfiguration{},
+			wantErr: false,
+		},
+		{
+			name:   "windows configuration with admin password secret and WinRM",
+			osType: WindowsOSType,
+			config: &WindowsConfiguration{
+				AdminPasswordSecretRef: &corev1.LocalObjectReference{Name: "admin-password"},
+				RemoteAccess:           WindowsRemoteAccessWinRM,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "windows configuration on a linux machine",
+			osType:  LinuxOSType,
+			config:  &WindowsConfiguration{},
+			wantErr: true,
+		},
+		{
+			name:    "windows configuration with empty admin password secret name",
+			osType:  WindowsOSType,
+			config:  &WindowsConfiguration{AdminPasswordSecretRef: &corev1.LocalObjectReference{}},
+			wantErr: true,
+		},
+		{
+			name:    "windows configuration with invalid remote access",
+			osType:  WindowsOSType,
+			config:  &WindowsConfiguration{RemoteAccess: "RDP"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateWindowsConfiguration(tc.osType, tc.config, field.NewPath("windowsConfiguration"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateVerifyBootstrap(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name            string
+		osType          string
+		verifyBootstrap bool
+		wantErr         bool
+	}{
+		{
+			name:            "verified Linux machine",
+			osType:          LinuxOSType,
+			verifyBootstrap: true,
+			wantErr:         false,
+		},
+		{
+			name:            "unverified Windows machine",
+			osType:          WindowsOSType,
+			verifyBootstrap: false,
+			wantErr:         false,
+		},
+		{
+			name:            "verified Windows machine",
+			osType:          WindowsOSType,
+			verifyBootstrap: true,
+			wantErr:         true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateVerifyBootstrap(tc.osType, tc.verifyBootstrap, field.NewPath("verifyBootstrap"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateBootDiagnostics(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name            string
+		bootDiagnostics *BootDiagnostics
+		wantErr         bool
+	}{
+		{
+			name:            "boot diagnostics disabled",
+			bootDiagnostics: nil,
+			wantErr:         false,
+		},
+		{
+			name: "storage account",
+			bootDiagnostics: &BootDiagnostics{
+				StorageAccount: BootDiagnosticsStorageAccount{Name: "mydiagnostics", ResourceGroup: "my-rg"},
+			},
+			wantErr: false,
+		},
+		{
+			name:            "no storage account",
+			bootDiagnostics: &BootDiagnostics{},
+			wantErr:         true,
+		},
+		{
+			name: "invalid storage account name",
+			bootDiagnostics: &BootDiagnostics{
+				StorageAccount: BootDiagnosticsStorageAccount{Name: "My-Diagnostics"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBootDiagnostics(tc.bootDiagnostics, field.NewPath("bootDiagnostics"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateAdminUsername(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name     string
+		osType   string
+		username string
+		wantErr  bool
+	}{
+		{
+			name:     "default username",
+			osType:   LinuxOSType,
+			username: "",
+			wantErr:  false,
+		},
+		{
+			name:     "valid linux username",
+			osType:   LinuxOSType,
+			username: "core",
+			wantErr:  false,
+		},
+		{
+			name:     "linux username with uppercase letters",
+			osType:   LinuxOSType,
+			username: "Core",
+			wantErr:  true,
+		},
+		{
+			name:     "linux username too long",
+			osType:   LinuxOSType,
+			username: "a23456789012345678901234567890123",
+			wantErr:  true,
+		},
+		{
+			name:     "reserved username",
+			osType:   LinuxOSType,
+			username: "root",
+			wantErr:  true,
+		},
+		{
+			name:     "valid windows username",
+			osType:   WindowsOSType,
+			username: "CapzAdmin",
+			wantErr:  false,
+		},
+		{
+			name:     "reserved windows username",
+			osType:   WindowsOSType,
+			username: "Administrator",
+			wantErr:  true,
+		},
+		{
+			name:     "windows username with forbidden character",
+			osType:   WindowsOSType,
+			username: "capz@admin",
+			wantErr:  true,
+		},
+		{
+			name:     "windows username ending with a period",
+			osType:   WindowsOSType,
+			username: "capzadmin.",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAdminUsername(tc.osType, tc.username, field.NewPath("adminUsername"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+
+	g.Expect(ValidateAdminUsernameUpdate("core", "core", field.NewPath("adminUsername"))).To(HaveLen(0))
+	g.Expect(ValidateAdminUsernameUpdate("", "core", field.NewPath("adminUsername"))).NotTo(HaveLen(0))
+}
+
+func TestAzureMachine_ValidateAdditionalSSHPublicKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	key := func() string {
+		decoded, _ := base64.StdEncoding.DecodeString(generateSSHPublicKey())
+		return string(decoded)
+	}
+
+	tests := []struct {
+		name    string
+		keys    []string
+		wantErr bool
+	}{
+		{
+			name:    "no keys",
+			keys:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "valid keys",
+			keys:    []string{key(), key()},
+			wantErr: false,
+		},
+		{
+			name:    "invalid key",
+			keys:    []string{key(), "ssh-rsa not-a-key"},
+			wantErr: true,
+		},
+		{
+			name:    "several keys in one entry",
+			keys:    []string{key() + key()},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAdditionalSSHPublicKeys(tc.keys, field.NewPath("additionalSSHPublicKeys"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+
+	g.Expect(ValidateSSHPublicKeysSecretRef(&corev1.LocalObjectReference{Name: "ssh-keys"}, field.NewPath("sshPublicKeysSecretRef"))).To(HaveLen(0))
+	g.Expect(ValidateSSHPublicKeysSecretRef(&corev1.LocalObjectReference{}, field.NewPath("sshPublicKeysSecretRef"))).NotTo(HaveLen(0))
+}
+
+func TestAzureMachine_ValidateSizeUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	spec := func(policy UpdatePolicy, vmSize string, diskSizeGB int32) AzureMachineSpec {
+		return AzureMachineSpec{
+			VMSize:       vmSize,
+			UpdatePolicy: policy,
+			OSDisk:       OSDisk{DiskSizeGB: diskSizeGB},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		old     AzureMachineSpec
+		new     AzureMachineSpec
+		wantErr bool
+	}{
+		{
+			name:    "no change",
+			old:     spec("", "Standard_D2s_v3", 30),
+			new:     spec("", "Standard_D2s_v3", 30),
+			wantErr: false,
+		},
+		{
+			name:    "VM size changed without update policy",
+			old:     spec("", "Standard_D2s_v3", 30),
+			new:     spec("", "Standard_D4s_v3", 30),
+			wantErr: true,
+		},
+		{
+			name:    "OS disk grown with the Immutable update policy",
+			old:     spec(UpdatePolicyImmutable, "Standard_D2s_v3", 30),
+			new:     spec(UpdatePolicyImmutable, "Standard_D2s_v3", 128),
+			wantErr: true,
+		},
+		{
+			name:    "VM size changed and OS disk grown with the InPlace update policy",
+			old:     spec("", "Standard_D2s_v3", 30),
+			new:     spec(UpdatePolicyInPlace, "Standard_D4s_v3", 128),
+			wantErr: false,
+		},
+		{
+			name:    "OS disk shrunk with the InPlace update policy",
+			old:     spec(UpdatePolicyInPlace, "Standard_D2s_v3", 128),
+			new:     spec(UpdatePolicyInPlace, "Standard_D2s_v3", 30),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateSizeUpdate(tc.old, tc.new)
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateVMExtensions(t *testing.T) {
+	g := NewWithT(t)
+
+	validExtension := VMExtension{
+		Name:      "monitoring-agent",
+		Publisher: "Microsoft.Azure.Monitor",
+		Type:      "AzureMonitorLinuxAgent",
+		Version:   "1.5",
+	}
+
+	tests := []struct {
+		name       string
+		extensions []VMExtension
+		wantErr    bool
+	}{
+		{
+			name:       "no extensions",
+			extensions: nil,
+			wantErr:    false,
+		},
+		{
+			name:       "valid extension",
+			extensions: []VMExtension{validExtension},
+			wantErr:    false,
+		},
+		{
+			name: "valid extension with protected settings",
+			extensions: []VMExtension{
+				{
+					Name:                 "gpu-driver",
+					Publisher:            "Microsoft.HpcCompute",
+					Type:                 "NvidiaGpuDriverLinux",
+					Version:              "1.3",
+					Settings:             Tags{"installCUDA": "true"},
+					ProtectedSettingsRef: &corev1.LocalObjectReference{Name: "gpu-driver-settings"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:       "duplicate extension names",
+			extensions: []VMExtension{validExtension, validExtension},
+			wantErr:    true,
+		},
+		{
+			name: "reserved extension name",
+			extensions: []VMExtension{
+				{
+					Name:      "capz-monitoring-agent",
+					Publisher: "Microsoft.Azure.Monitor",
+					Type:      "AzureMonitorLinuxAgent",
+					Version:   "1.5",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing publisher",
+			extensions: []VMExtension{
+				{
+					Name:    "monitoring-agent",
+					Type:    "AzureMonitorLinuxAgent",
+					Version: "1.5",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty protected settings secret name",
+			extensions: []VMExtension{
+				{
+					Name:                 "monitoring-agent",
+					Publisher:            "Microsoft.Azure.Monitor",
+					Type:                 "AzureMonitorLinuxAgent",
+					Version:              "1.5",
+					ProtectedSettingsRef: &corev1.LocalObjectReference{},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateVMExtensions(tc.extensions, field.NewPath("vmExtensions"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateNetworkInterfaces(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		nics    []NetworkInterface
+		wantErr bool
+	}{
+		{
+			name:    "no additional network interfaces",
+			nics:    nil,
+			wantErr: false,
+		},
+		{
+			name: "valid network interfaces",
+			nics: []NetworkInterface{
+				{
+					NameSuffix: "storage",
+					SubnetName: "storage-subnet",
+				},
+				{
+					NameSuffix:      "replication",
+					SubnetName:      "replication-subnet",
+					StaticIPAddress: "10.2.0.10",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing name suffix",
+			nics: []NetworkInterface{
+				{
+					SubnetName: "storage-subnet",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "reserved name suffix",
+			nics: []NetworkInterface{
+				{
+					NameSuffix: "public",
+					SubnetName: "storage-subnet",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name suffix",
+			nics: []NetworkInterface{
+				{
+					NameSuffix: "storage",
+					SubnetName: "storage-subnet",
+				},
+				{
+					NameSuffix: "storage",
+					SubnetName: "replication-subnet",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing subnet name",
+			nics: []NetworkInterface{
+				{
+					NameSuffix: "storage",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid static IP address",
+			nics: []NetworkInterface{
+				{
+					NameSuffix:      "storage",
+					SubnetName:      "storage-subnet",
+					StaticIPAddress: "10.2.0.300",
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateNetworkInterfaces(tc.nics, field.NewPath("additionalNetworkInterfaces"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
 func generateSSHPublicKey() string {
 	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
 	publicRsaKey, _ := ssh.NewPublicKey(&privateKey.PublicKey)
@@ -85,10 +752,10 @@ func TestAzureMachine_ValidateOSDisk(t *testing.T) {
 		},
 		{
 			name:    "valid ephemeral os disk spec",
-			wantErr: false,
+			wantErr: true,
 			osDisk: OSDisk{
 				DiskSizeGB: 30,
-				OSType:     "blah",
+				OSType:     "Linux",
 				DiffDiskSettings: &DiffDiskSettings{
 					Option: "Local",
 				},
@@ -97,6 +764,17 @@ func TestAzureMachine_ValidateOSDisk(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "valid windows os disk spec",
+			wantErr: false,
+			osDisk: OSDisk{
+				DiskSizeGB: 128,
+				OSType:     "Windows",
+				ManagedDisk: ManagedDisk{
+					StorageAccountType: "Premium_LRS",
+				},
+			},
+		},
 	}
 	testcases = append(testcases, generateNegativeTestCases()...)
 
@@ -134,6 +812,13 @@ func generateNegativeTestCases() []osDiskTestInput {
 			DiskSizeGB: 20,
 			OSType:     "",
 		},
+		{
+			DiskSizeGB: 30,
+			OSType:     "blah",
+			ManagedDisk: ManagedDisk{
+				StorageAccountType: "Premium_LRS",
+			},
+		},
 		{
 			DiskSizeGB:  30,
 			OSType:      "blah",
@@ -273,6 +958,72 @@ func TestAzureMachine_ValidateDataDisks(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid disk options",
+			disks: []DataDisk{
+				{
+					NameSuffix:              "my_disk",
+					DiskSizeGB:              64,
+					Lun:                     to.Int32Ptr(0),
+					ManagedDisk:             &ManagedDisk{StorageAccountType: "Premium_LRS"},
+					CachingType:             "ReadOnly",
+					WriteAcceleratorEnabled: true,
+					RetainOnDelete:          true,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid storage account type",
+			disks: []DataDisk{
+				{
+					NameSuffix:  "my_disk",
+					DiskSizeGB:  64,
+					Lun:         to.Int32Ptr(0),
+					ManagedDisk: &ManagedDisk{StorageAccountType: "invalid"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid caching type",
+			disks: []DataDisk{
+				{
+					NameSuffix:  "my_disk",
+					DiskSizeGB:  64,
+					Lun:         to.Int32Ptr(0),
+					CachingType: "invalid",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "write accelerator without premium storage",
+			disks: []DataDisk{
+				{
+					NameSuffix:              "my_disk",
+					DiskSizeGB:              64,
+					Lun:                     to.Int32Ptr(0),
+					ManagedDisk:             &ManagedDisk{StorageAccountType: "Standard_LRS"},
+					WriteAcceleratorEnabled: true,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "write accelerator with read-write caching",
+			disks: []DataDisk{
+				{
+					NameSuffix:              "my_disk",
+					DiskSizeGB:              64,
+					Lun:                     to.Int32Ptr(0),
+					ManagedDisk:             &ManagedDisk{StorageAccountType: "Premium_LRS"},
+					CachingType:             "ReadWrite",
+					WriteAcceleratorEnabled: true,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, test := range testcases {
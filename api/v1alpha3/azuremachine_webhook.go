@@ -56,6 +56,22 @@ func (m *AzureMachine) ValidateCreate() error {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := ValidateWindowsConfiguration(m.Spec.OSDisk.OSType, m.Spec.WindowsConfiguration, field.NewPath("windowsConfiguration")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateAdminUsername(m.Spec.OSDisk.OSType, m.Spec.AdminUsername, field.NewPath("adminUsername")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateAdditionalSSHPublicKeys(m.Spec.AdditionalSSHPublicKeys, field.NewPath("additionalSSHPublicKeys")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateSSHPublicKeysSecretRef(m.Spec.SSHPublicKeysSecretRef, field.NewPath("sshPublicKeysSecretRef")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if errs := ValidateUserAssignedIdentity(m.Spec.Identity, m.Spec.UserAssignedIdentities, field.NewPath("userAssignedIdentities")); len(errs) > 0 {
 		allErrs = append(allErrs, errs...)
 	}
@@ -64,6 +80,34 @@ func (m *AzureMachine) ValidateCreate() error {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := ValidateSecondaryIPCount(m.Spec.SecondaryIPCount, field.NewPath("secondaryIPCount")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateNetworkInterfaces(m.Spec.AdditionalNetworkInterfaces, field.NewPath("additionalNetworkInterfaces")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateStaticIPAddressPool(m.Spec.StaticIPAddressPool, field.NewPath("staticIPAddressPool")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateSpotVMOptions(m.Spec.SpotVMOptions, field.NewPath("spotVMOptions")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateVMExtensions(m.Spec.VMExtensions, field.NewPath("vmExtensions")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateVerifyBootstrap(m.Spec.OSDisk.OSType, m.Spec.VerifyBootstrap, field.NewPath("verifyBootstrap")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateBootDiagnostics(m.Spec.BootDiagnostics, field.NewPath("bootDiagnostics")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -81,6 +125,22 @@ func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object) error {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := ValidateWindowsConfiguration(m.Spec.OSDisk.OSType, m.Spec.WindowsConfiguration, field.NewPath("windowsConfiguration")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateAdminUsername(m.Spec.OSDisk.OSType, m.Spec.AdminUsername, field.NewPath("adminUsername")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateAdditionalSSHPublicKeys(m.Spec.AdditionalSSHPublicKeys, field.NewPath("additionalSSHPublicKeys")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateSSHPublicKeysSecretRef(m.Spec.SSHPublicKeysSecretRef, field.NewPath("sshPublicKeysSecretRef")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if errs := ValidateUserAssignedIdentity(m.Spec.Identity, m.Spec.UserAssignedIdentities, field.NewPath("userAssignedIdentities")); len(errs) > 0 {
 		allErrs = append(allErrs, errs...)
 	}
@@ -89,10 +149,50 @@ func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object) error {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := ValidateSecondaryIPCount(m.Spec.SecondaryIPCount, field.NewPath("secondaryIPCount")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateNetworkInterfaces(m.Spec.AdditionalNetworkInterfaces, field.NewPath("additionalNetworkInterfaces")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateStaticIPAddressPool(m.Spec.StaticIPAddressPool, field.NewPath("staticIPAddressPool")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateSpotVMOptionsUpdate(old.Spec.SpotVMOptions, m.Spec.SpotVMOptions, field.NewPath("spotVMOptions")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateVMExtensions(m.Spec.VMExtensions, field.NewPath("vmExtensions")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateVerifyBootstrap(m.Spec.OSDisk.OSType, m.Spec.VerifyBootstrap, field.NewPath("verifyBootstrap")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateBootDiagnostics(m.Spec.BootDiagnostics, field.NewPath("bootDiagnostics")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if errs := ValidateManagedDisk(old.Spec.OSDisk.ManagedDisk, m.Spec.OSDisk.ManagedDisk, field.NewPath("osDisk").Child("managedDisk")); len(errs) > 0 {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := ValidateVerifyBootstrapUpdate(old.Spec.VerifyBootstrap, m.Spec.VerifyBootstrap, field.NewPath("verifyBootstrap")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateAdminUsernameUpdate(old.Spec.AdminUsername, m.Spec.AdminUsername, field.NewPath("adminUsername")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateSizeUpdate(old.Spec, m.Spec); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if errs := validateDiffDiskSettingsUpdate(old.Spec.OSDisk.DiffDiskSettings, m.Spec.OSDisk.DiffDiskSettings, field.NewPath("osDisk").Child("diffDiskSettings")); len(errs) > 0 {
 		allErrs = append(allErrs, errs...)
 	}
@@ -114,9 +214,5 @@ func (m *AzureMachine) ValidateDelete() error {
 func (m *AzureMachine) Default() {
 	machinelog.Info("default", "name", m.Name)
 
-	err := m.SetDefaultSSHPublicKey()
-	if err != nil {
-		machinelog.Error(err, "SetDefaultSshPublicKey failed")
-	}
 	m.SetDataDisksDefaults()
 }
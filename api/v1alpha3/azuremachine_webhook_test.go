@@ -73,7 +73,7 @@ func TestAzureMachine_ValidateCreate(t *testing.T) {
 		{
 			name:    "azuremachine without SSHPublicKey",
 			machine: createMachineWithSSHPublicKey(t, ""),
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name:    "azuremachine with invalid SSHPublicKey",
@@ -122,7 +122,7 @@ func TestAzureMachine_ValidateUpdate(t *testing.T) {
 			name:       "azuremachine without SSHPublicKey",
 			oldMachine: createMachineWithSSHPublicKey(t, ""),
 			machine:    createMachineWithSSHPublicKey(t, ""),
-			wantErr:    true,
+			wantErr:    false,
 		},
 		{
 			name:       "azuremachine with invalid SSHPublicKey",
@@ -142,6 +142,40 @@ func TestAzureMachine_ValidateUpdate(t *testing.T) {
 			machine:    createMachineWithUserAssignedIdentities(t, []UserAssignedIdentity{}),
 			wantErr:    true,
 		},
+		{
+			name:       "azuremachine enabling bootstrap verification",
+			oldMachine: createMachineWithSSHPublicKey(t, validSSHPublicKey),
+			machine: func() *AzureMachine {
+				m := createMachineWithSSHPublicKey(t, validSSHPublicKey)
+				m.Spec.VerifyBootstrap = true
+				return m
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "azuremachine with unchanged Spot VM options",
+			oldMachine: func() *AzureMachine {
+				m := createMachineWithSSHPublicKey(t, validSSHPublicKey)
+				m.Spec.SpotVMOptions = &SpotVMOptions{}
+				return m
+			}(),
+			machine: func() *AzureMachine {
+				m := createMachineWithSSHPublicKey(t, validSSHPublicKey)
+				m.Spec.SpotVMOptions = &SpotVMOptions{}
+				return m
+			}(),
+			wantErr: false,
+		},
+		{
+			name:       "azuremachine adding Spot VM options",
+			oldMachine: createMachineWithSSHPublicKey(t, validSSHPublicKey),
+			machine: func() *AzureMachine {
+				m := createMachineWithSSHPublicKey(t, validSSHPublicKey)
+				m.Spec.SpotVMOptions = &SpotVMOptions{}
+				return m
+			}(),
+			wantErr: true,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -169,8 +203,9 @@ func TestAzureMachine_Default(t *testing.T) {
 	publicKeyExistTest.machine.Default()
 	g.Expect(publicKeyExistTest.machine.Spec.SSHPublicKey).To(Equal(existingPublicKey))
 
+	// the key generated for the cluster is set by the controller
 	publicKeyNotExistTest.machine.Default()
-	g.Expect(publicKeyNotExistTest.machine.Spec.SSHPublicKey).To(Not(BeEmpty()))
+	g.Expect(publicKeyNotExistTest.machine.Spec.SSHPublicKey).To(BeEmpty())
 }
 
 func createMachineWithSharedImage(t *testing.T, subscriptionID, resourceGroup, name, gallery, version string) *AzureMachine {
@@ -20,6 +20,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// ResolvedImageAnnotation is the annotation of an AzureMachineTemplate recording, as JSON, the image resolved for
+// the first machine created from the template that pins its image version.
+const ResolvedImageAnnotation = "azuremachinetemplate.infrastructure.cluster.x-k8s.io/resolved-image"
+
 // AzureMachineTemplateSpec defines the desired state of AzureMachineTemplate
 type AzureMachineTemplateSpec struct {
 	Template AzureMachineTemplateResource `json:"template"`
@@ -44,8 +44,34 @@ const (
 	VMStoppedReason = "VMStopped"
 	// VMProvisionFailedReason used for failures during vm provisioning.
 	VMProvisionFailedReason = "VMProvisionFailed"
+	// VMProvisionBackoffReason used when the vm is created again after a backoff following a failed provisioning.
+	VMProvisionBackoffReason = "VMProvisionBackoff"
+	// ZoneUnavailableReason used when the vm cannot be placed in an availability zone allowed by its zone placement policy.
+	ZoneUnavailableReason = "ZoneUnavailable"
 	// WaitingForClusterInfrastructureReason used when machine is waiting for cluster infrastructure to be ready before proceeding.
 	WaitingForClusterInfrastructureReason = "WaitingForClusterInfrastructure"
 	// WaitingForBootstrapDataReason used when machine is waiting for bootstrap data to be ready before proceeding.
 	WaitingForBootstrapDataReason = "WaitingForBootstrapData"
+	// StaticIPAddressUnavailableReason used when no free address is left in the machine's static IP address pool.
+	StaticIPAddressUnavailableReason = "StaticIPAddressUnavailable"
+
+	// BootstrapSucceededCondition reports whether the bootstrap of the machine succeeded, when verified.
+	BootstrapSucceededCondition clusterv1.ConditionType = "BootstrapSucceeded"
+	// BootstrapInProgressReason used when the bootstrap of the machine has not completed yet.
+	BootstrapInProgressReason = "BootstrapInProgress"
+	// BootstrapFailedReason used when the bootstrap of the machine failed.
+	BootstrapFailedReason = "BootstrapFailed"
+
+	// InPlaceUpdatedCondition reports whether the in-place update of the VM size or the OS disk size of the machine completed.
+	InPlaceUpdatedCondition clusterv1.ConditionType = "InPlaceUpdated"
+	// VMDeallocatingReason used when the vm is deallocated before being updated.
+	VMDeallocatingReason = "VMDeallocating"
+	// VMResizingReason used when the vm size is being changed.
+	VMResizingReason = "VMResizing"
+	// OSDiskGrowingReason used when the os disk is being grown.
+	OSDiskGrowingReason = "OSDiskGrowing"
+	// VMStartingReason used when the vm is started after being updated.
+	VMStartingReason = "VMStarting"
+	// InPlaceUpdateFailedReason used when the in-place update of the vm failed.
+	InPlaceUpdateFailedReason = "InPlaceUpdateFailed"
 )
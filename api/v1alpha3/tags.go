@@ -104,6 +104,14 @@ const (
 	// dedicated to this cluster api provider implementation.
 	NameAzureClusterAPIRole = NameAzureProviderPrefix + "role"
 
+	// NameAzureProviderMachine is the tag name we use to record the machine a retained
+	// data disk was attached to.
+	NameAzureProviderMachine = NameAzureProviderPrefix + "machine"
+
+	// NameAzureProviderDataDisk is the tag name we use to record the name suffix of a
+	// retained data disk.
+	NameAzureProviderDataDisk = NameAzureProviderPrefix + "data-disk"
+
 	// APIServerRole describes the value for the apiserver role
 	APIServerRole = "apiserver"
 
@@ -18,6 +18,7 @@ package v1alpha3
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -34,6 +35,10 @@ type Network struct {
 
 	// APIServerIP is the Kubernetes API server public IP address.
 	APIServerIP PublicIP `json:"apiServerIp,omitempty"`
+
+	// APIServerPrivateDNSName is the fully qualified domain name of the Kubernetes API server in the private DNS zone.
+	// +optional
+	APIServerPrivateDNSName string `json:"apiServerPrivateDNSName,omitempty"`
 }
 
 // NetworkSpec specifies what the Azure networking resources should look like.
@@ -45,6 +50,46 @@ type NetworkSpec struct {
 	// Subnets is the configuration for the control-plane subnet and the node subnet.
 	// +optional
 	Subnets Subnets `json:"subnets,omitempty"`
+
+	// PrivateDNSZone is the configuration for a private DNS zone linked to the virtual network.
+	// When set, the control plane endpoint resolves to the internal load balancer through this zone.
+	// +optional
+	PrivateDNSZone *PrivateDNSZone `json:"privateDNSZone,omitempty"`
+}
+
+// AvailabilitySetsSpec configures the availability sets created for the control plane and for each MachineDeployment.
+type AvailabilitySetsSpec struct {
+	// FaultDomainCount is the number of fault domains of each availability set. Defaults to 2.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3
+	// +optional
+	FaultDomainCount *int32 `json:"faultDomainCount,omitempty"`
+
+	// UpdateDomainCount is the number of update domains of each availability set. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	// +optional
+	UpdateDomainCount *int32 `json:"updateDomainCount,omitempty"`
+}
+
+// PrivateDNSZone configures an Azure private DNS zone.
+type PrivateDNSZone struct {
+	// Name is the name of the private DNS zone, e.g. cluster.internal.
+	Name string `json:"name"`
+
+	// ResourceGroup is the name of the resource group of an existing private DNS zone.
+	// If omitted, the zone is created in the cluster resource group and deleted with the cluster.
+	// +optional
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+
+	// APIServerRecordName is the name of the A record of the Kubernetes API server in the zone.
+	// Defaults to <clusterName>-apiserver.
+	// +optional
+	APIServerRecordName string `json:"apiServerRecordName,omitempty"`
+
+	// NodeRecords enables the creation of an A record for each machine, named after the machine.
+	// +optional
+	NodeRecords bool `json:"nodeRecords,omitempty"`
 }
 
 // VnetSpec configures an Azure virtual network.
@@ -194,6 +239,26 @@ const (
 	VMStateUpdating VMState = "Updating"
 )
 
+// VMPowerState describes the power state of an Azure virtual machine.
+type VMPowerState string
+
+const (
+	// VMPowerStateStarting ...
+	VMPowerStateStarting VMPowerState = "Starting"
+	// VMPowerStateRunning ...
+	VMPowerStateRunning VMPowerState = "Running"
+	// VMPowerStateStopping ...
+	VMPowerStateStopping VMPowerState = "Stopping"
+	// VMPowerStateStopped ...
+	VMPowerStateStopped VMPowerState = "Stopped"
+	// VMPowerStateDeallocating ...
+	VMPowerStateDeallocating VMPowerState = "Deallocating"
+	// VMPowerStateDeallocated ...
+	VMPowerStateDeallocated VMPowerState = "Deallocated"
+	// VMPowerStateUnknown ...
+	VMPowerStateUnknown VMPowerState = "Unknown"
+)
+
 // VM describes an Azure virtual machine.
 type VM struct {
 	ID               string `json:"id,omitempty"`
@@ -210,8 +275,17 @@ type VM struct {
 	Identity VMIdentity `json:"identity,omitempty"`
 	Tags     Tags       `json:"tags,omitempty"`
 
+	// PowerState is the power state of the VM, from its instance view.
+	PowerState VMPowerState `json:"powerState,omitempty"`
+
+	// VMAgentStatus is the status of the VM agent running on the VM, from its instance view.
+	VMAgentStatus string `json:"vmAgentStatus,omitempty"`
+
 	// Addresses contains the addresses associated with the Azure VM.
 	Addresses []corev1.NodeAddress `json:"addresses,omitempty"`
+
+	// SecondaryIPAddresses contains the private IP addresses of the secondary IP configurations of the Azure VM.
+	SecondaryIPAddresses []string `json:"secondaryIPAddresses,omitempty"`
 }
 
 // Image defines information about the image to use for VM creation.
@@ -251,6 +325,25 @@ type AzureMarketplaceImage struct {
 	// time even if a new version becomes available.
 	// +kubebuilder:validation:MinLength=1
 	Version string `json:"version"`
+	// Plan is the purchase plan of the image, required to deploy third-party images that are sold in the Marketplace.
+	// +optional
+	Plan *ImagePlan `json:"plan,omitempty"`
+}
+
+// ImagePlan defines the purchase plan of a Marketplace image.
+type ImagePlan struct {
+	// Publisher is the publisher of the plan, usually the publisher of the image.
+	// +kubebuilder:validation:MinLength=1
+	Publisher string `json:"publisher"`
+	// Product is the product of the plan, usually the offer of the image.
+	// +kubebuilder:validation:MinLength=1
+	Product string `json:"product"`
+	// Name is the name of the plan, usually the SKU of the image.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// AcceptTerms accepts the Marketplace terms of the plan in the subscription before the image is deployed.
+	// +optional
+	AcceptTerms bool `json:"acceptTerms,omitempty"`
 }
 
 // AzureSharedGalleryImage defines an image in a Shared Image Gallery to use for VM creation
@@ -305,8 +398,16 @@ type UserAssignedIdentity struct {
 	ProviderID string `json:"providerID"`
 }
 
+const (
+	// LinuxOSType is the OS type of Linux machines.
+	LinuxOSType = "Linux"
+	// WindowsOSType is the OS type of Windows machines.
+	WindowsOSType = "Windows"
+)
+
 // OSDisk defines the operating system disk for a VM.
 type OSDisk struct {
+	// OSType is the operating system of the machine, Linux or Windows. It selects the OS profile and the default image.
 	OSType           string            `json:"osType"`
 	DiskSizeGB       int32             `json:"diskSizeGB"`
 	ManagedDisk      ManagedDisk       `json:"managedDisk"`
@@ -323,6 +424,133 @@ type DataDisk struct {
 	// Lun Specifies the logical unit number of the data disk. This value is used to identify data disks within the VM and therefore must be unique for each data disk attached to a VM.
 	// The value must be between 0 and 63.
 	Lun *int32 `json:"lun,omitempty"`
+	// ManagedDisk specifies the managed disk parameters of the data disk.
+	// If omitted, the storage account type is chosen by Azure.
+	// +optional
+	ManagedDisk *ManagedDisk `json:"managedDisk,omitempty"`
+	// CachingType specifies the host caching of the data disk. Defaults to None.
+	// +kubebuilder:validation:Enum=None;ReadOnly;ReadWrite
+	// +optional
+	CachingType string `json:"cachingType,omitempty"`
+	// WriteAcceleratorEnabled enables write acceleration on the data disk. It requires a Premium_LRS
+	// disk, a VM size with write accelerator support and a caching type of None or ReadOnly.
+	// +optional
+	WriteAcceleratorEnabled bool `json:"writeAcceleratorEnabled,omitempty"`
+	// RetainOnDelete keeps the data disk when the machine is deleted. Retained disks are tagged with
+	// the cluster, machine and name suffix they belong to, and are attached again to a machine of the
+	// same name. It is not supported on machine pools, whose data disks are deleted with their instances.
+	// +optional
+	RetainOnDelete bool `json:"retainOnDelete,omitempty"`
+}
+
+const (
+	// DataDiskCachingNone disables host caching of a data disk.
+	DataDiskCachingNone = "None"
+	// DataDiskCachingReadOnly enables read-only host caching of a data disk.
+	DataDiskCachingReadOnly = "ReadOnly"
+	// DataDiskCachingReadWrite enables read-write host caching of a data disk.
+	DataDiskCachingReadWrite = "ReadWrite"
+)
+
+// ReservedVMExtensionNamePrefix is the name prefix of the VM extensions installed by the provider itself.
+const ReservedVMExtensionNamePrefix = "capz-"
+
+// VMExtension specifies the parameters of a VM extension installed on the machine.
+type VMExtension struct {
+	// Name is the name of the extension. It must be unique on the machine and must not start with "capz-",
+	// which is reserved for the extensions installed by the provider.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Publisher is the name of the extension handler publisher, e.g. Microsoft.Azure.Extensions.
+	// +kubebuilder:validation:MinLength=1
+	Publisher string `json:"publisher"`
+	// Type is the type of the extension handler, e.g. CustomScript.
+	// +kubebuilder:validation:MinLength=1
+	Type string `json:"type"`
+	// Version is the major and minor version of the extension handler, e.g. 2.1. Newer minor versions
+	// are used automatically when the extension is installed or updated.
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+	// Settings is the public configuration of the extension.
+	// +optional
+	Settings Tags `json:"settings,omitempty"`
+	// ProtectedSettingsRef references a Secret in the namespace of the machine whose keys and values are
+	// passed to the extension as its protected configuration. Protected settings are encrypted by Azure and
+	// are only decrypted on the virtual machine.
+	// +optional
+	ProtectedSettingsRef *corev1.LocalObjectReference `json:"protectedSettingsRef,omitempty"`
+}
+
+// VMExtensionStatus describes the provisioning state of a VM extension.
+type VMExtensionStatus struct {
+	// Name is the name of the extension.
+	Name string `json:"name"`
+	// ProvisioningState is the provisioning state of the extension, e.g. Succeeded or Failed.
+	// +optional
+	ProvisioningState string `json:"provisioningState,omitempty"`
+	// Message describes why the extension failed to be installed, updated or removed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VMProvisioningStatus describes the failed attempts to provision the virtual machine of a machine. A virtual machine
+// that fails to provision is deleted and created again after an exponential backoff.
+type VMProvisioningStatus struct {
+	// FailedAttempts is the number of times the virtual machine failed to provision.
+	FailedAttempts int32 `json:"failedAttempts"`
+	// LastError describes the error reported by Azure for the last failed attempt.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// NextAttemptTime is the earliest time at which the virtual machine is created again.
+	// +optional
+	NextAttemptTime *metav1.Time `json:"nextAttemptTime,omitempty"`
+}
+
+// NetworkInterface specifies the parameters of an additional network interface attached to the machine.
+type NetworkInterface struct {
+	// NameSuffix is the suffix to be appended to the machine name to generate the network interface name.
+	// Each network interface name will be in format <machineName>-<nameSuffix>-nic.
+	NameSuffix string `json:"nameSuffix"`
+	// SubnetName is the name of the subnet of the cluster virtual network the network interface is attached to.
+	SubnetName string `json:"subnetName"`
+	// AcceleratedNetworking enables or disables Azure accelerated networking on the network interface.
+	// If omitted, the machine's AcceleratedNetworking setting is used.
+	// +optional
+	AcceleratedNetworking *bool `json:"acceleratedNetworking,omitempty"`
+	// StaticIPAddress is the private IP address to assign to the network interface.
+	// If omitted, an address is allocated dynamically from the subnet.
+	// +optional
+	StaticIPAddress string `json:"staticIPAddress,omitempty"`
+	// LoadBalancerBackendPool adds the network interface to the backend pool of the load balancer used by the
+	// machine role: the internal API server load balancer for control plane machines and the outbound load
+	// balancer for nodes.
+	// +optional
+	LoadBalancerBackendPool bool `json:"loadBalancerBackendPool,omitempty"`
+}
+
+// WindowsRemoteAccess defines how Windows machines are reached remotely.
+type WindowsRemoteAccess string
+
+const (
+	// WindowsRemoteAccessOpenSSH configures the OpenSSH server of the machine and authorizes its SSH public key
+	// for the administrator account.
+	WindowsRemoteAccessOpenSSH WindowsRemoteAccess = "OpenSSH"
+	// WindowsRemoteAccessWinRM enables an HTTP WinRM listener on the machine.
+	WindowsRemoteAccessWinRM WindowsRemoteAccess = "WinRM"
+)
+
+// WindowsConfiguration defines the operating system settings of Windows machines.
+type WindowsConfiguration struct {
+	// AdminPasswordSecretRef is a reference to a Secret in the namespace of the machine holding the password of
+	// the administrator account under the `password` key. If omitted, a random password is generated and stored
+	// in a Secret named `<name>-admin-password`, owned by the AzureMachine or AzureMachinePool.
+	// +optional
+	AdminPasswordSecretRef *corev1.LocalObjectReference `json:"adminPasswordSecretRef,omitempty"`
+
+	// RemoteAccess selects how the machine is reached remotely. Defaults to OpenSSH.
+	// +kubebuilder:validation:Enum=OpenSSH;WinRM
+	// +optional
+	RemoteAccess WindowsRemoteAccess `json:"remoteAccess,omitempty"`
 }
 
 // ManagedDisk defines the managed disk options for a VM.
@@ -330,6 +558,22 @@ type ManagedDisk struct {
 	StorageAccountType string `json:"storageAccountType"`
 }
 
+// BootDiagnostics specifies the boot diagnostics of a virtual machine, which store its serial console log and
+// a screenshot of its console in a storage account.
+type BootDiagnostics struct {
+	// StorageAccount references the storage account storing the boot diagnostics.
+	StorageAccount BootDiagnosticsStorageAccount `json:"storageAccount"`
+}
+
+// BootDiagnosticsStorageAccount references an existing storage account in the subscription of the cluster.
+type BootDiagnosticsStorageAccount struct {
+	// Name is the name of the storage account.
+	Name string `json:"name"`
+	// ResourceGroup is the resource group of the storage account. Defaults to the resource group of the cluster.
+	// +optional
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+}
+
 // DiffDiskSettings describe ephemeral disk settings for the os disk.
 type DiffDiskSettings struct {
 	// Option enables ephemeral OS when set to "Local"
@@ -370,6 +614,12 @@ type SubnetSpec struct {
 	// +optional
 	InternalLBIPAddress string `json:"internalLBIPAddress,omitempty"`
 
+	// StaticIPAddressPool is a list of private IP addresses of this subnet that are statically assigned to the
+	// machines attached to it, typically the control plane machines. Each machine claims a free address of the pool.
+	// Machines that define their own StaticIPAddressPool use it instead.
+	// +optional
+	StaticIPAddressPool []string `json:"staticIPAddressPool,omitempty"`
+
 	// SecurityGroup defines the NSG (network security group) that should be attached to this subnet.
 	// +optional
 	SecurityGroup SecurityGroup `json:"securityGroup,omitempty"`
@@ -27,6 +27,31 @@ import (
 	"sigs.k8s.io/cluster-api/errors"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AvailabilitySetsSpec) DeepCopyInto(out *AvailabilitySetsSpec) {
+	*out = *in
+	if in.FaultDomainCount != nil {
+		in, out := &in.FaultDomainCount, &out.FaultDomainCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UpdateDomainCount != nil {
+		in, out := &in.UpdateDomainCount, &out.UpdateDomainCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AvailabilitySetsSpec.
+func (in *AvailabilitySetsSpec) DeepCopy() *AvailabilitySetsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AvailabilitySetsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AvailabilityZone) DeepCopyInto(out *AvailabilityZone) {
 	*out = *in
@@ -123,6 +148,12 @@ func (in *AzureClusterSpec) DeepCopyInto(out *AzureClusterSpec) {
 			(*out)[key] = val
 		}
 	}
+	in.AvailabilitySets.DeepCopyInto(&out.AvailabilitySets)
+	if in.AllowedFailureDomains != nil {
+		in, out := &in.AllowedFailureDomains, &out.AllowedFailureDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureClusterSpec.
@@ -250,6 +281,11 @@ func (in *AzureMachineSpec) DeepCopyInto(out *AzureMachineSpec) {
 		copy(*out, *in)
 	}
 	in.OSDisk.DeepCopyInto(&out.OSDisk)
+	if in.WindowsConfiguration != nil {
+		in, out := &in.WindowsConfiguration, &out.WindowsConfiguration
+		*out = new(WindowsConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DataDisks != nil {
 		in, out := &in.DataDisks, &out.DataDisks
 		*out = make([]DataDisk, len(*in))
@@ -257,6 +293,16 @@ func (in *AzureMachineSpec) DeepCopyInto(out *AzureMachineSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AdditionalSSHPublicKeys != nil {
+		in, out := &in.AdditionalSSHPublicKeys, &out.AdditionalSSHPublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SSHPublicKeysSecretRef != nil {
+		in, out := &in.SSHPublicKeysSecretRef, &out.SSHPublicKeysSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	if in.AdditionalTags != nil {
 		in, out := &in.AdditionalTags, &out.AdditionalTags
 		*out = make(Tags, len(*in))
@@ -269,11 +315,35 @@ func (in *AzureMachineSpec) DeepCopyInto(out *AzureMachineSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.StaticIPAddressPool != nil {
+		in, out := &in.StaticIPAddressPool, &out.StaticIPAddressPool
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdditionalNetworkInterfaces != nil {
+		in, out := &in.AdditionalNetworkInterfaces, &out.AdditionalNetworkInterfaces
+		*out = make([]NetworkInterface, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.SpotVMOptions != nil {
 		in, out := &in.SpotVMOptions, &out.SpotVMOptions
 		*out = new(SpotVMOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.VMExtensions != nil {
+		in, out := &in.VMExtensions, &out.VMExtensions
+		*out = make([]VMExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BootDiagnostics != nil {
+		in, out := &in.BootDiagnostics, &out.BootDiagnostics
+		*out = new(BootDiagnostics)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachineSpec.
@@ -294,11 +364,36 @@ func (in *AzureMachineStatus) DeepCopyInto(out *AzureMachineStatus) {
 		*out = make([]v1.NodeAddress, len(*in))
 		copy(*out, *in)
 	}
+	if in.SecondaryIPAddresses != nil {
+		in, out := &in.SecondaryIPAddresses, &out.SecondaryIPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.VMState != nil {
 		in, out := &in.VMState, &out.VMState
 		*out = new(VMState)
 		**out = **in
 	}
+	if in.VMExtensions != nil {
+		in, out := &in.VMExtensions, &out.VMExtensions
+		*out = make([]VMExtensionStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.VMProvisioning != nil {
+		in, out := &in.VMProvisioning, &out.VMProvisioning
+		*out = new(VMProvisioningStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ZonePlacement != nil {
+		in, out := &in.ZonePlacement, &out.ZonePlacement
+		*out = new(ZonePlacementStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(Image)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.FailureReason != nil {
 		in, out := &in.FailureReason, &out.FailureReason
 		*out = new(errors.MachineStatusError)
@@ -421,6 +516,11 @@ func (in *AzureMachineTemplateSpec) DeepCopy() *AzureMachineTemplateSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMarketplaceImage) DeepCopyInto(out *AzureMarketplaceImage) {
 	*out = *in
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(ImagePlan)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMarketplaceImage.
@@ -463,6 +563,37 @@ func (in *BackendPool) DeepCopy() *BackendPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootDiagnostics) DeepCopyInto(out *BootDiagnostics) {
+	*out = *in
+	out.StorageAccount = in.StorageAccount
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootDiagnostics.
+func (in *BootDiagnostics) DeepCopy() *BootDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(BootDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootDiagnosticsStorageAccount) DeepCopyInto(out *BootDiagnosticsStorageAccount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootDiagnosticsStorageAccount.
+func (in *BootDiagnosticsStorageAccount) DeepCopy() *BootDiagnosticsStorageAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(BootDiagnosticsStorageAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildParams) DeepCopyInto(out *BuildParams) {
 	*out = *in
@@ -503,6 +634,11 @@ func (in *DataDisk) DeepCopyInto(out *DataDisk) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ManagedDisk != nil {
+		in, out := &in.ManagedDisk, &out.ManagedDisk
+		*out = new(ManagedDisk)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataDisk.
@@ -561,7 +697,7 @@ func (in *Image) DeepCopyInto(out *Image) {
 	if in.Marketplace != nil {
 		in, out := &in.Marketplace, &out.Marketplace
 		*out = new(AzureMarketplaceImage)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -575,6 +711,21 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePlan) DeepCopyInto(out *ImagePlan) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePlan.
+func (in *ImagePlan) DeepCopy() *ImagePlan {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressRule) DeepCopyInto(out *IngressRule) {
 	*out = *in
@@ -691,6 +842,26 @@ func (in *Network) DeepCopy() *Network {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterface) DeepCopyInto(out *NetworkInterface) {
+	*out = *in
+	if in.AcceleratedNetworking != nil {
+		in, out := &in.AcceleratedNetworking, &out.AcceleratedNetworking
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterface.
+func (in *NetworkInterface) DeepCopy() *NetworkInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 	*out = *in
@@ -706,6 +877,11 @@ func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 			}
 		}
 	}
+	if in.PrivateDNSZone != nil {
+		in, out := &in.PrivateDNSZone, &out.PrivateDNSZone
+		*out = new(PrivateDNSZone)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
@@ -739,6 +915,21 @@ func (in *OSDisk) DeepCopy() *OSDisk {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateDNSZone) DeepCopyInto(out *PrivateDNSZone) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateDNSZone.
+func (in *PrivateDNSZone) DeepCopy() *PrivateDNSZone {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateDNSZone)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PublicIP) DeepCopyInto(out *PublicIP) {
 	*out = *in
@@ -825,6 +1016,11 @@ func (in *SpotVMOptions) DeepCopy() *SpotVMOptions {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
 	*out = *in
+	if in.StaticIPAddressPool != nil {
+		in, out := &in.StaticIPAddressPool, &out.StaticIPAddressPool
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.SecurityGroup.DeepCopyInto(&out.SecurityGroup)
 	out.RouteTable = in.RouteTable
 }
@@ -917,6 +1113,11 @@ func (in *VM) DeepCopyInto(out *VM) {
 		*out = make([]v1.NodeAddress, len(*in))
 		copy(*out, *in)
 	}
+	if in.SecondaryIPAddresses != nil {
+		in, out := &in.SecondaryIPAddresses, &out.SecondaryIPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VM.
@@ -929,6 +1130,67 @@ func (in *VM) DeepCopy() *VM {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMExtension) DeepCopyInto(out *VMExtension) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(Tags, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ProtectedSettingsRef != nil {
+		in, out := &in.ProtectedSettingsRef, &out.ProtectedSettingsRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMExtension.
+func (in *VMExtension) DeepCopy() *VMExtension {
+	if in == nil {
+		return nil
+	}
+	out := new(VMExtension)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMExtensionStatus) DeepCopyInto(out *VMExtensionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMExtensionStatus.
+func (in *VMExtensionStatus) DeepCopy() *VMExtensionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VMExtensionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMProvisioningStatus) DeepCopyInto(out *VMProvisioningStatus) {
+	*out = *in
+	if in.NextAttemptTime != nil {
+		in, out := &in.NextAttemptTime, &out.NextAttemptTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMProvisioningStatus.
+func (in *VMProvisioningStatus) DeepCopy() *VMProvisioningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VMProvisioningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VnetSpec) DeepCopyInto(out *VnetSpec) {
 	*out = *in
@@ -950,3 +1212,43 @@ func (in *VnetSpec) DeepCopy() *VnetSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WindowsConfiguration) DeepCopyInto(out *WindowsConfiguration) {
+	*out = *in
+	if in.AdminPasswordSecretRef != nil {
+		in, out := &in.AdminPasswordSecretRef, &out.AdminPasswordSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WindowsConfiguration.
+func (in *WindowsConfiguration) DeepCopy() *WindowsConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(WindowsConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZonePlacementStatus) DeepCopyInto(out *ZonePlacementStatus) {
+	*out = *in
+	if in.ExcludedZones != nil {
+		in, out := &in.ExcludedZones, &out.ExcludedZones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZonePlacementStatus.
+func (in *ZonePlacementStatus) DeepCopy() *ZonePlacementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ZonePlacementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
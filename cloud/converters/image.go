@@ -70,3 +70,16 @@ func specificImageToSDK(image *infrav1.Image) (*compute.ImageReference, error) {
 		ID: image.ID,
 	}, nil
 }
+
+// ImageToPlan converts the purchase plan of a CAPZ Marketplace image to an Azure SDK Plan, or returns nil if the image
+// has no plan.
+func ImageToPlan(image *infrav1.Image) *compute.Plan {
+	if image == nil || image.Marketplace == nil || image.Marketplace.Plan == nil {
+		return nil
+	}
+	return &compute.Plan{
+		Publisher: &image.Marketplace.Plan.Publisher,
+		Product:   &image.Marketplace.Plan.Product,
+		Name:      &image.Marketplace.Plan.Name,
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters_test
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+)
+
+func Test_ImageToPlan(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Image  *infrav1.Image
+		Expect *compute.Plan
+	}{
+		{
+			Name: "ShouldConvertMarketplacePlan",
+			Image: &infrav1.Image{
+				Marketplace: &infrav1.AzureMarketplaceImage{
+					Publisher: "fortinet",
+					Offer:     "fortinet_fortigate-vm_v5",
+					SKU:       "fortinet_fg-vm",
+					Version:   "latest",
+					Plan: &infrav1.ImagePlan{
+						Publisher: "fortinet",
+						Product:   "fortinet_fortigate-vm_v5",
+						Name:      "fortinet_fg-vm",
+					},
+				},
+			},
+			Expect: &compute.Plan{
+				Publisher: to.StringPtr("fortinet"),
+				Product:   to.StringPtr("fortinet_fortigate-vm_v5"),
+				Name:      to.StringPtr("fortinet_fg-vm"),
+			},
+		},
+		{
+			Name: "ShouldReturnNilWithoutPlan",
+			Image: &infrav1.Image{
+				Marketplace: &infrav1.AzureMarketplaceImage{
+					Publisher: "cncf-upstream",
+					Offer:     "capi",
+					SKU:       "k8s-1dot18dot8-ubuntu-1804",
+					Version:   "latest",
+				},
+			},
+		},
+		{
+			Name:  "ShouldReturnNilForImageID",
+			Image: &infrav1.Image{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/images/my-image")},
+		},
+		{
+			Name: "ShouldReturnNilForNilImage",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			g.Expect(converters.ImageToPlan(c.Image)).To(gomega.Equal(c.Expect))
+		})
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package converters
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
@@ -42,5 +45,59 @@ func SDKToVM(v compute.VirtualMachine) (*infrav1.VM, error) {
 		vm.Tags = MapToTags(v.Tags)
 	}
 
+	if v.VirtualMachineProperties != nil && v.VirtualMachineProperties.InstanceView != nil {
+		vm.PowerState, vm.VMAgentStatus = SDKToVMInstanceView(*v.VirtualMachineProperties.InstanceView)
+	}
+
 	return vm, nil
 }
+
+// powerStates maps the power state codes of the instance view of a virtual machine to the CAPZ VM power states.
+var powerStates = map[string]infrav1.VMPowerState{
+	"starting":     infrav1.VMPowerStateStarting,
+	"running":      infrav1.VMPowerStateRunning,
+	"stopping":     infrav1.VMPowerStateStopping,
+	"stopped":      infrav1.VMPowerStateStopped,
+	"deallocating": infrav1.VMPowerStateDeallocating,
+	"deallocated":  infrav1.VMPowerStateDeallocated,
+}
+
+// SDKToVMInstanceView returns the power state of a virtual machine and the status of its VM agent from the statuses
+// of its instance view. The power state is empty when the instance view does not report it, and Unknown when it is
+// not a known power state.
+func SDKToVMInstanceView(view compute.VirtualMachineInstanceView) (infrav1.VMPowerState, string) {
+	var powerState infrav1.VMPowerState
+	if view.Statuses != nil {
+		for _, status := range *view.Statuses {
+			code := to.String(status.Code)
+			if !strings.HasPrefix(code, "PowerState/") {
+				continue
+			}
+			var ok bool
+			if powerState, ok = powerStates[strings.ToLower(strings.TrimPrefix(code, "PowerState/"))]; !ok {
+				powerState = infrav1.VMPowerStateUnknown
+			}
+		}
+	}
+
+	var agentStatus string
+	if view.VMAgent != nil && view.VMAgent.Statuses != nil && len(*view.VMAgent.Statuses) > 0 {
+		agentStatus = to.String((*view.VMAgent.Statuses)[0].DisplayStatus)
+	}
+
+	return powerState, agentStatus
+}
+
+// SSHPublicKeysToSDK converts SSH public keys in the OpenSSH authorized_keys format to the Azure SDK SSH public keys
+// authorized for an administrator account.
+func SSHPublicKeysToSDK(adminUsername string, keys []string) *[]compute.SSHPublicKey {
+	path := fmt.Sprintf("/home/%s/.ssh/authorized_keys", adminUsername)
+	publicKeys := make([]compute.SSHPublicKey, 0, len(keys))
+	for _, key := range keys {
+		publicKeys = append(publicKeys, compute.SSHPublicKey{
+			Path:    to.StringPtr(path),
+			KeyData: to.StringPtr(key),
+		})
+	}
+	return &publicKeys
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters_test
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+)
+
+func Test_SDKToVMPowerState(t *testing.T) {
+	cases := []struct {
+		Name              string
+		InstanceView      *compute.VirtualMachineInstanceView
+		ExpectPowerState  infrav1.VMPowerState
+		ExpectAgentStatus string
+	}{
+		{
+			Name: "ShouldMapRunningVM",
+			InstanceView: &compute.VirtualMachineInstanceView{
+				Statuses: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr("ProvisioningState/succeeded")},
+					{Code: to.StringPtr("PowerState/running")},
+				},
+				VMAgent: &compute.VirtualMachineAgentInstanceView{
+					Statuses: &[]compute.InstanceViewStatus{
+						{Code: to.StringPtr("ProvisioningState/succeeded"), DisplayStatus: to.StringPtr("Ready")},
+					},
+				},
+			},
+			ExpectPowerState:  infrav1.VMPowerStateRunning,
+			ExpectAgentStatus: "Ready",
+		},
+		{
+			Name: "ShouldMapDeallocatedVM",
+			InstanceView: &compute.VirtualMachineInstanceView{
+				Statuses: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr("ProvisioningState/succeeded")},
+					{Code: to.StringPtr("PowerState/deallocated")},
+				},
+				VMAgent: &compute.VirtualMachineAgentInstanceView{
+					Statuses: &[]compute.InstanceViewStatus{
+						{Code: to.StringPtr("ProvisioningState/Unavailable"), DisplayStatus: to.StringPtr("Not Ready")},
+					},
+				},
+			},
+			ExpectPowerState:  infrav1.VMPowerStateDeallocated,
+			ExpectAgentStatus: "Not Ready",
+		},
+		{
+			Name: "ShouldMapUnknownPowerState",
+			InstanceView: &compute.VirtualMachineInstanceView{
+				Statuses: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr("PowerState/hibernated")},
+				},
+			},
+			ExpectPowerState: infrav1.VMPowerStateUnknown,
+		},
+		{
+			Name: "ShouldNotReportPowerStateWithoutInstanceView",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			vm, err := converters.SDKToVM(compute.VirtualMachine{
+				Name: to.StringPtr("my-vm"),
+				VirtualMachineProperties: &compute.VirtualMachineProperties{
+					ProvisioningState: to.StringPtr("Succeeded"),
+					InstanceView:      c.InstanceView,
+				},
+			})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(vm.State).To(gomega.Equal(infrav1.VMStateSucceeded))
+			g.Expect(vm.PowerState).To(gomega.Equal(c.ExpectPowerState))
+			g.Expect(vm.VMAgentStatus).To(gomega.Equal(c.ExpectAgentStatus))
+		})
+	}
+}
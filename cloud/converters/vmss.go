@@ -47,6 +47,19 @@ func SDKToVMSS(sdkvmss compute.VirtualMachineScaleSet, sdkinstances []compute.Vi
 		vmss.Tags = MapToTags(sdkvmss.Tags)
 	}
 
+	if sdkvmss.VirtualMachineScaleSetProperties != nil && sdkvmss.VirtualMachineProfile != nil &&
+		sdkvmss.VirtualMachineProfile.ExtensionProfile != nil && sdkvmss.VirtualMachineProfile.ExtensionProfile.Extensions != nil {
+		for _, ext := range *sdkvmss.VirtualMachineProfile.ExtensionProfile.Extensions {
+			status := infrav1.VMExtensionStatus{
+				Name: to.String(ext.Name),
+			}
+			if ext.VirtualMachineScaleSetExtensionProperties != nil {
+				status.ProvisioningState = to.String(ext.ProvisioningState)
+			}
+			vmss.Extensions = append(vmss.Extensions, status)
+		}
+	}
+
 	if len(sdkinstances) > 0 {
 		vmss.Instances = make([]infrav1exp.VMSSVM, len(sdkinstances))
 		for i, vm := range sdkinstances {
@@ -17,10 +17,21 @@ limitations under the License.
 package azure
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
 	"fmt"
+	"hash/fnv"
+	"math/big"
+	"strings"
+	"unicode/utf16"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	"sigs.k8s.io/cluster-api-provider-azure/version"
 )
@@ -30,36 +41,135 @@ const (
 	DefaultUserName = "capi"
 	// DefaultInternalLBIPAddress is the default internal load balancer ip address
 	DefaultInternalLBIPAddress = "10.0.0.100"
+	// WindowsComputerNameMaxLength is the maximum length of the computer name of a Windows VM
+	WindowsComputerNameMaxLength = 15
+	// WindowsComputerNamePrefixMaxLength is the maximum length of the computer name prefix of a Windows scale set
+	WindowsComputerNamePrefixMaxLength = 9
 )
 
 const (
 	// DefaultImageOfferID is the default Azure Marketplace offer ID
 	DefaultImageOfferID = "capi"
+	// DefaultWindowsImageOfferID is the default Azure Marketplace offer ID for Windows
+	DefaultWindowsImageOfferID = "capi-windows"
 	// DefaultImagePublisherID is the default Azure Marketplace publisher ID
 	DefaultImagePublisherID = "cncf-upstream"
 	// LatestVersion is the image version latest
 	LatestVersion = "latest"
 )
 
-// SupportedAvailabilityZoneLocations is a slice of the locations where Availability Zones are supported.
-// This is used to validate whether a virtual machine should leverage an Availability Zone.
-// Based on the Availability Zones listed in https://docs.microsoft.com/en-us/azure/availability-zones/az-overview
-var SupportedAvailabilityZoneLocations = []string{
-	// Americas
-	"centralus",
-	"eastus",
-	"eastus2",
-	"westus2",
+const (
+	// WindowsOpenSSHExtensionName is the name of the VM extension configuring OpenSSH on Windows machines
+	WindowsOpenSSHExtensionName = "capz-windows-openssh"
+	// CustomScriptExtensionPublisher is the publisher of the Windows custom script extension
+	CustomScriptExtensionPublisher = "Microsoft.Compute"
+	// CustomScriptExtensionType is the type of the Windows custom script extension
+	CustomScriptExtensionType = "CustomScriptExtension"
+	// CustomScriptExtensionVersion is the version of the Windows custom script extension
+	CustomScriptExtensionVersion = "1.9"
+)
+
+const (
+	// BootstrapCheckExtensionName is the name of the VM extension checking that a Linux machine bootstrapped successfully
+	BootstrapCheckExtensionName = "capz-bootstrap-check"
+	// LinuxCustomScriptExtensionPublisher is the publisher of the Linux custom script extension
+	LinuxCustomScriptExtensionPublisher = "Microsoft.Azure.Extensions"
+	// LinuxCustomScriptExtensionType is the type of the Linux custom script extension
+	LinuxCustomScriptExtensionType = "CustomScript"
+	// LinuxCustomScriptExtensionVersion is the version of the Linux custom script extension
+	LinuxCustomScriptExtensionVersion = "2.0"
+	// BootstrapSentinelFile is the file written by the kubeadm bootstrap provider once the machine bootstrapped successfully
+	BootstrapSentinelFile = "/run/cluster-api/bootstrap-success.complete"
+	// BootstrapCheckTimeoutSeconds is how long the bootstrap check waits for the bootstrap sentinel file
+	BootstrapCheckTimeoutSeconds = 900
+)
+
+const (
+	// SSHAccessExtensionName is the name of the VM extension pushing the SSH public keys of a Linux machine
+	// after it was created
+	SSHAccessExtensionName = "capz-ssh-access"
+	// VMAccessExtensionPublisher is the publisher of the Linux VM access extension
+	VMAccessExtensionPublisher = "Microsoft.OSTCExtensions"
+	// VMAccessExtensionType is the type of the Linux VM access extension
+	VMAccessExtensionType = "VMAccessForLinux"
+	// VMAccessExtensionVersion is the version of the Linux VM access extension
+	VMAccessExtensionVersion = "1.5"
+)
+
+// bootstrapCheckScript waits for the bootstrap sentinel file, and fails with the end of the cloud-init output
+// if cloud-init reports an error or the sentinel file does not appear in time.
+const bootstrapCheckScript = `for i in $(seq 1 %[2]d); do
+  if [ -f %[1]s ]; then
+    echo "bootstrap succeeded"
+    exit 0
+  fi
+  if cloud-init status 2>/dev/null | grep -q "status: error"; then
+    echo "cloud-init reported an error:"
+    tail -n 20 /var/log/cloud-init-output.log
+    exit 1
+  fi
+  sleep 5
+done
+echo "timed out waiting for %[1]s:"
+tail -n 20 /var/log/cloud-init-output.log
+exit 1
+`
+
+// GenerateBootstrapCheckCommand generates the command of the custom script extension that waits for a Linux
+// machine to bootstrap successfully.
+func GenerateBootstrapCheckCommand() string {
+	script := fmt.Sprintf(bootstrapCheckScript, BootstrapSentinelFile, BootstrapCheckTimeoutSeconds/5)
+	return fmt.Sprintf("/bin/bash -c \"echo %s | base64 -d | /bin/bash\"", base64.StdEncoding.EncodeToString([]byte(script)))
+}
+
+// windowsOpenSSHScript installs and starts the OpenSSH server if needed, authorizes the SSH public keys for
+// the members of the Administrators group and opens the SSH port in the Windows firewall.
+const windowsOpenSSHScript = `$ErrorActionPreference = 'Stop'
+if (-not (Get-Service -Name sshd -ErrorAction SilentlyContinue)) {
+  Add-WindowsCapability -Online -Name OpenSSH.Server~~~~0.0.1.0 | Out-Null
+}
+Set-Service -Name sshd -StartupType Automatic
+Start-Service -Name sshd
+$path = Join-Path $env:ProgramData 'ssh\administrators_authorized_keys'
+Set-Content -Path $path -Value '%s' -Encoding ascii
+icacls.exe $path /inheritance:r /grant 'Administrators:F' /grant 'SYSTEM:F' | Out-Null
+if (-not (Get-NetFirewallRule -Name sshd -ErrorAction SilentlyContinue)) {
+  New-NetFirewallRule -Name sshd -DisplayName 'OpenSSH Server (sshd)' -Direction Inbound -Protocol TCP -LocalPort 22 -Action Allow | Out-Null
+}
+`
+
+// GenerateWindowsOpenSSHCommand generates the command of the custom script extension that configures OpenSSH on a
+// Windows machine and authorizes the given SSH public keys for the administrator account.
+func GenerateWindowsOpenSSHCommand(sshPublicKeys []string) string {
+	keys := make([]string, 0, len(sshPublicKeys))
+	for _, key := range sshPublicKeys {
+		keys = append(keys, strings.ReplaceAll(strings.TrimSpace(key), "'", "''"))
+	}
+	script := fmt.Sprintf(windowsOpenSSHScript, strings.Join(keys, "\n"))
 
-	// Europe
-	"francecentral",
-	"northeurope",
-	"uksouth",
-	"westeurope",
+	// PowerShell expects encoded commands in base64 encoded UTF-16LE
+	encoded := utf16.Encode([]rune(script))
+	buf := make([]byte, 2*len(encoded))
+	for i, r := range encoded {
+		binary.LittleEndian.PutUint16(buf[2*i:], r)
+	}
+	return fmt.Sprintf("powershell.exe -NoProfile -ExecutionPolicy Unrestricted -EncodedCommand %s", base64.StdEncoding.EncodeToString(buf))
+}
+
+// GenerateSSHKeySecretName generates the name of the Secret storing the SSH key pair generated for a cluster.
+func GenerateSSHKeySecretName(clusterName string) string {
+	return fmt.Sprintf("%s-ssh", clusterName)
+}
 
-	// Asia Pacific
-	"japaneast",
-	"southeastasia",
+// GenerateAdminPasswordSecretName generates the name of the Secret storing the administrator password generated for
+// a Windows machine or machine pool.
+func GenerateAdminPasswordSecretName(name string) string {
+	return fmt.Sprintf("%s-admin-password", name)
+}
+
+// GenerateBootDiagnosticsSecretName generates the name of the Secret storing the boot diagnostics of a machine.
+func GenerateBootDiagnosticsSecretName(machineName string) string {
+	return fmt.Sprintf("%s-boot-diagnostics", machineName)
 }
 
 // GenerateInternalLBName generates a internal load balancer name, based on the cluster name.
@@ -87,6 +197,16 @@ func GenerateNodePublicIPName(machineName string) string {
 	return fmt.Sprintf("pip-%s", machineName)
 }
 
+// GenerateAPIServerRecordName generates the name of the API server record in a private DNS zone, based on the cluster name.
+func GenerateAPIServerRecordName(clusterName string) string {
+	return fmt.Sprintf("%s-apiserver", clusterName)
+}
+
+// GenerateVNetLinkName generates the name of a private DNS zone virtual network link, based on the cluster name.
+func GenerateVNetLinkName(clusterName string) string {
+	return fmt.Sprintf("%s-vnet-link", clusterName)
+}
+
 // GenerateNICName generates the name of a network interface based on the name of a VM.
 func GenerateNICName(machineName string) string {
 	return fmt.Sprintf("%s-nic", machineName)
@@ -97,6 +217,16 @@ func GeneratePublicNICName(machineName string) string {
 	return fmt.Sprintf("%s-public-nic", machineName)
 }
 
+// GenerateAdditionalNICName generates the name of an additional network interface based on the name of a VM.
+func GenerateAdditionalNICName(machineName, nameSuffix string) string {
+	return fmt.Sprintf("%s-%s-nic", machineName, nameSuffix)
+}
+
+// GenerateSecondaryIPConfigName generates the name of a secondary IP configuration of a network interface based on its index.
+func GenerateSecondaryIPConfigName(index int32) string {
+	return fmt.Sprintf("ipConfig-secondary-%d", index)
+}
+
 // GenerateOSDiskName generates the name of an OS disk based on the name of a VM.
 func GenerateOSDiskName(machineName string) string {
 	return fmt.Sprintf("%s_OSDisk", machineName)
@@ -107,18 +237,56 @@ func GenerateDataDiskName(machineName, nameSuffix string) string {
 	return fmt.Sprintf("%s_%s", machineName, nameSuffix)
 }
 
-// GetDefaultImageSKUID gets the SKU ID of the image to use for the provided version of Kubernetes.
-func getDefaultImageSKUID(k8sVersion string) (string, error) {
+// AcceptsMarketplaceTerms returns true if the image is a Marketplace image whose plan terms should be accepted.
+func AcceptsMarketplaceTerms(image *infrav1.Image) bool {
+	return image != nil && image.Marketplace != nil && image.Marketplace.Plan != nil && image.Marketplace.Plan.AcceptTerms
+}
+
+// GenerateSSHPublicKeysHash generates a hash of a list of SSH public keys in the OpenSSH authorized_keys format.
+func GenerateSSHPublicKeysHash(sshPublicKeys []string) string {
+	h := fnv.New64a()
+	for _, key := range sshPublicKeys {
+		_, _ = h.Write([]byte(strings.TrimSpace(key) + "\n"))
+	}
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// GetDataDiskCachingType returns the host caching of a data disk, defaulting to none.
+func GetDataDiskCachingType(disk infrav1.DataDisk) string {
+	if disk.CachingType == "" {
+		return infrav1.DataDiskCachingNone
+	}
+	return disk.CachingType
+}
+
+// GenerateAvailabilitySetName generates the name of the availability set of a node group, i.e. the control plane
+// or a MachineDeployment, based on the cluster name.
+func GenerateAvailabilitySetName(clusterName, nodeGroupName string) string {
+	return fmt.Sprintf("%s_%s-as", clusterName, nodeGroupName)
+}
+
+// GenerateFaultDomainName generates the name of the failure domain of an availability set fault domain.
+func GenerateFaultDomainName(index int32) string {
+	return fmt.Sprintf("fd-%d", index)
+}
+
+// AvailabilitySetID returns the azure resource ID for a given availability set.
+func AvailabilitySetID(subscriptionID, resourceGroup, availabilitySetName string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/availabilitySets/%s", subscriptionID, resourceGroup, availabilitySetName)
+}
+
+// GetDefaultImageSKUID gets the SKU ID of the image to use for the provided version of Kubernetes and OS.
+func getDefaultImageSKUID(k8sVersion, osAndVersion string) (string, error) {
 	version, err := semver.ParseTolerant(k8sVersion)
 	if err != nil {
 		return "", errors.Wrapf(err, "unable to parse Kubernetes version \"%s\" in spec, expected valid SemVer string", k8sVersion)
 	}
-	return fmt.Sprintf("k8s-%ddot%ddot%d-ubuntu-1804", version.Major, version.Minor, version.Patch), nil
+	return fmt.Sprintf("k8s-%ddot%ddot%d-%s", version.Major, version.Minor, version.Patch, osAndVersion), nil
 }
 
 // GetDefaultUbuntuImage returns the default image spec for Ubuntu.
 func GetDefaultUbuntuImage(k8sVersion string) (*infrav1.Image, error) {
-	skuID, err := getDefaultImageSKUID(k8sVersion)
+	skuID, err := getDefaultImageSKUID(k8sVersion, "ubuntu-1804")
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get default image")
 	}
@@ -135,6 +303,99 @@ func GetDefaultUbuntuImage(k8sVersion string) (*infrav1.Image, error) {
 	return defaultImage, nil
 }
 
+// GetDefaultWindowsImage returns the default image spec for Windows.
+func GetDefaultWindowsImage(k8sVersion string) (*infrav1.Image, error) {
+	skuID, err := getDefaultImageSKUID(k8sVersion, "windows-2019")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get default Windows image")
+	}
+
+	defaultImage := &infrav1.Image{
+		Marketplace: &infrav1.AzureMarketplaceImage{
+			Publisher: DefaultImagePublisherID,
+			Offer:     DefaultWindowsImageOfferID,
+			SKU:       skuID,
+			Version:   LatestVersion,
+		},
+	}
+
+	return defaultImage, nil
+}
+
+// GetDefaultImage returns the default image spec for the given OS type.
+func GetDefaultImage(osType, k8sVersion string) (*infrav1.Image, error) {
+	if osType == infrav1.WindowsOSType {
+		return GetDefaultWindowsImage(k8sVersion)
+	}
+	return GetDefaultUbuntuImage(k8sVersion)
+}
+
+// GenerateWindowsComputerName generates the computer name of a Windows VM, based on the machine name.
+// Names longer than WindowsComputerNameMaxLength are shortened and suffixed with a hash of the full name to stay unique.
+func GenerateWindowsComputerName(machineName string) string {
+	return shortenName(machineName, WindowsComputerNameMaxLength)
+}
+
+// GenerateWindowsComputerNamePrefix generates the computer name prefix of a Windows scale set, based on its name.
+func GenerateWindowsComputerNamePrefix(scaleSetName string) string {
+	return shortenName(scaleSetName, WindowsComputerNamePrefixMaxLength)
+}
+
+// shortenName truncates a name to maxLength, replacing its end with a hash of the full name.
+func shortenName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	suffix := fmt.Sprintf("%08x", h.Sum32())[:6]
+	return name[:maxLength-len(suffix)] + suffix
+}
+
+// GenerateAdminPassword generates a random password meeting the complexity requirements of Windows administrator accounts.
+func GenerateAdminPassword() (string, error) {
+	const (
+		length  = 32
+		lower   = "abcdefghijklmnopqrstuvwxyz"
+		upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		digits  = "0123456789"
+		special = "!@#$%^&*()-_=+"
+	)
+	// draw one character of each class, then fill up from all classes
+	classes := []string{lower, upper, digits, special}
+	password := make([]byte, 0, length)
+	for i := 0; i < length; i++ {
+		charset := lower + upper + digits + special
+		if i < len(classes) {
+			charset = classes[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", errors.Wrap(err, "failed to generate admin password")
+		}
+		password = append(password, charset[n.Int64()])
+	}
+	return string(password), nil
+}
+
+// GenerateSSHKeyPair generates an RSA SSH key pair, returning the PEM encoded private key and the public key in the
+// OpenSSH authorized_keys format. Azure only accepts RSA keys for the administrator account of virtual machines.
+func GenerateSSHKeyPair() ([]byte, []byte, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate private key")
+	}
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate public key")
+	}
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+	return privateKeyPEM, ssh.MarshalAuthorizedKey(publicKey), nil
+}
+
 // UserAgent specifies a string to append to the agent identifier.
 func UserAgent() string {
 	return fmt.Sprintf("cluster-api-provider-azure/%s", version.Get().String())
@@ -17,9 +17,15 @@ limitations under the License.
 package azure
 
 import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
 	"testing"
+	"unicode/utf16"
 
 	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 )
 
 func TestGetDefaultImageSKUID(t *testing.T) {
@@ -74,7 +80,7 @@ func TestGetDefaultImageSKUID(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.k8sVersion, func(t *testing.T) {
-			id, err := getDefaultImageSKUID(test.k8sVersion)
+			id, err := getDefaultImageSKUID(test.k8sVersion, "ubuntu-1804")
 
 			if test.expectedError {
 				g.Expect(err).To(HaveOccurred())
@@ -85,3 +91,93 @@ func TestGetDefaultImageSKUID(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDefaultImage(t *testing.T) {
+	g := NewWithT(t)
+
+	image, err := GetDefaultImage(infrav1.LinuxOSType, "v1.18.8")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image.Marketplace.Offer).To(Equal(DefaultImageOfferID))
+	g.Expect(image.Marketplace.SKU).To(Equal("k8s-1dot18dot8-ubuntu-1804"))
+
+	image, err = GetDefaultImage(infrav1.WindowsOSType, "v1.18.8")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image.Marketplace.Offer).To(Equal(DefaultWindowsImageOfferID))
+	g.Expect(image.Marketplace.SKU).To(Equal("k8s-1dot18dot8-windows-2019"))
+}
+
+func TestGenerateWindowsComputerName(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(GenerateWindowsComputerName("win-md-0-abcde")).To(Equal("win-md-0-abcde"))
+
+	name := GenerateWindowsComputerName("my-cluster-md-win-7c9f5-abcde")
+	g.Expect(name).To(HaveLen(WindowsComputerNameMaxLength))
+	g.Expect(name).To(HavePrefix("my-cluste"))
+	g.Expect(name).NotTo(Equal(GenerateWindowsComputerName("my-cluster-md-win-7c9f5-fghij")))
+
+	g.Expect(GenerateWindowsComputerNamePrefix("my-cluster-mp-win")).To(HaveLen(WindowsComputerNamePrefixMaxLength))
+}
+
+func TestGenerateAdminPassword(t *testing.T) {
+	g := NewWithT(t)
+
+	password, err := GenerateAdminPassword()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(password).To(HaveLen(32))
+	g.Expect(password).To(MatchRegexp("[a-z]"))
+	g.Expect(password).To(MatchRegexp("[A-Z]"))
+	g.Expect(password).To(MatchRegexp("[0-9]"))
+	g.Expect(password).To(MatchRegexp("[^a-zA-Z0-9]"))
+}
+
+func TestGenerateWindowsOpenSSHCommand(t *testing.T) {
+	g := NewWithT(t)
+
+	command := GenerateWindowsOpenSSHCommand([]string{"ssh-rsa AAAA user's key\n", "ssh-ed25519 BBBB"})
+	g.Expect(command).To(HavePrefix("powershell.exe -NoProfile -ExecutionPolicy Unrestricted -EncodedCommand "))
+
+	encoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(command, "powershell.exe -NoProfile -ExecutionPolicy Unrestricted -EncodedCommand "))
+	g.Expect(err).NotTo(HaveOccurred())
+	runes := make([]uint16, len(encoded)/2)
+	for i := range runes {
+		runes[i] = binary.LittleEndian.Uint16(encoded[2*i:])
+	}
+	g.Expect(string(utf16.Decode(runes))).To(ContainSubstring("-Value 'ssh-rsa AAAA user''s key\nssh-ed25519 BBBB' -Encoding ascii"))
+}
+
+func TestGenerateSSHKeyPair(t *testing.T) {
+	g := NewWithT(t)
+
+	privateKeyPEM, publicKey, err := GenerateSSHKeyPair()
+	g.Expect(err).NotTo(HaveOccurred())
+	signer, err := ssh.ParsePrivateKey(privateKeyPEM)
+	g.Expect(err).NotTo(HaveOccurred())
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(parsed.Type()).To(Equal(ssh.KeyAlgoRSA))
+	g.Expect(parsed.Marshal()).To(Equal(signer.PublicKey().Marshal()))
+}
+
+func TestGenerateSSHPublicKeysHash(t *testing.T) {
+	g := NewWithT(t)
+
+	hash := GenerateSSHPublicKeysHash([]string{"ssh-rsa AAAA", "ssh-ed25519 BBBB"})
+	g.Expect(GenerateSSHPublicKeysHash([]string{"ssh-rsa AAAA\n", " ssh-ed25519 BBBB"})).To(Equal(hash))
+	g.Expect(GenerateSSHPublicKeysHash([]string{"ssh-rsa AAAA"})).NotTo(Equal(hash))
+	g.Expect(GenerateSSHPublicKeysHash([]string{"ssh-rsa AAAA", "ssh-ed25519 CCCC"})).NotTo(Equal(hash))
+}
+
+func TestGenerateBootstrapCheckCommand(t *testing.T) {
+	g := NewWithT(t)
+
+	command := GenerateBootstrapCheckCommand()
+	g.Expect(command).To(HavePrefix(`/bin/bash -c "echo `))
+	g.Expect(command).To(HaveSuffix(` | base64 -d | /bin/bash"`))
+
+	encoded := strings.TrimSuffix(strings.TrimPrefix(command, `/bin/bash -c "echo `), ` | base64 -d | /bin/bash"`)
+	script, err := base64.StdEncoding.DecodeString(encoded)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(script)).To(ContainSubstring("if [ -f /run/cluster-api/bootstrap-success.complete ]; then"))
+	g.Expect(string(script)).To(ContainSubstring("for i in $(seq 1 180); do"))
+}
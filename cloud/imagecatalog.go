@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"os"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+)
+
+// ImageCatalog maps the Azure environment, OS type and Kubernetes version of machines to the image used when they
+// don't specify one. Machines matching no entry use the default images of the Azure Marketplace.
+type ImageCatalog struct {
+	// Images are the entries of the catalog, the first matching entry being used.
+	Images []ImageCatalogEntry `json:"images"`
+
+	// environment is the Azure environment of the controller.
+	environment string
+}
+
+// ImageCatalogEntry is the default image of the machines of an environment, OS type and Kubernetes version range.
+type ImageCatalogEntry struct {
+	// Environment is the name of the Azure environment, e.g. AzureStackCloud. Empty matches any environment.
+	Environment string `json:"environment,omitempty"`
+	// OSType is the OS type of the machines, Linux or Windows. Empty matches Linux.
+	OSType string `json:"osType,omitempty"`
+	// KubernetesVersion is a range of Kubernetes versions, e.g. ">=1.18.0 <1.19.0". Empty matches any version.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Image is the image of the machines, referenced by ID, in the Marketplace or in a Shared Image Gallery.
+	Image infrav1.Image `json:"image"`
+}
+
+// LoadImageCatalog reads an image catalog from a YAML or JSON file, keeping the entries for the given environment.
+func LoadImageCatalog(path, environment string) (*ImageCatalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open image catalog %s", path)
+	}
+	defer f.Close()
+
+	catalog := &ImageCatalog{environment: environment}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(catalog); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse image catalog %s", path)
+	}
+	if err := catalog.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid image catalog %s", path)
+	}
+	return catalog, nil
+}
+
+// validate validates the OS type, Kubernetes version range and image of each entry of the catalog.
+func (c *ImageCatalog) validate() error {
+	allErrs := field.ErrorList{}
+	for i, entry := range c.Images {
+		fldPath := field.NewPath("images").Index(i)
+		if entry.OSType != "" && entry.OSType != infrav1.LinuxOSType && entry.OSType != infrav1.WindowsOSType {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("osType"), entry.OSType, []string{infrav1.LinuxOSType, infrav1.WindowsOSType}))
+		}
+		if entry.KubernetesVersion != "" {
+			if _, err := semver.ParseRange(entry.KubernetesVersion); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("kubernetesVersion"), entry.KubernetesVersion, err.Error()))
+			}
+		}
+		image := entry.Image
+		allErrs = append(allErrs, infrav1.ValidateImage(&image, fldPath.Child("image"))...)
+	}
+	return allErrs.ToAggregate()
+}
+
+// GetDefaultImage returns the image of the first entry of the catalog matching the environment of the controller,
+// the OS type and the Kubernetes version, or the default Marketplace image if no entry matches. A nil catalog always
+// returns the default Marketplace image.
+func (c *ImageCatalog) GetDefaultImage(osType, k8sVersion string) (*infrav1.Image, error) {
+	if c == nil || len(c.Images) == 0 {
+		return GetDefaultImage(osType, k8sVersion)
+	}
+	if osType == "" {
+		osType = infrav1.LinuxOSType
+	}
+	version, err := semver.ParseTolerant(k8sVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse Kubernetes version \"%s\" in spec, expected valid SemVer string", k8sVersion)
+	}
+
+	for _, entry := range c.Images {
+		if entry.Environment != "" && !strings.EqualFold(entry.Environment, c.environment) {
+			continue
+		}
+		entryOSType := entry.OSType
+		if entryOSType == "" {
+			entryOSType = infrav1.LinuxOSType
+		}
+		if entryOSType != osType {
+			continue
+		}
+		if entry.KubernetesVersion != "" {
+			versionRange, err := semver.ParseRange(entry.KubernetesVersion)
+			if err != nil || !versionRange(version) {
+				continue
+			}
+		}
+		return entry.Image.DeepCopy(), nil
+	}
+	return GetDefaultImage(osType, k8sVersion)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+)
+
+const testImageCatalog = `
+images:
+- environment: AzureStackCloud
+  kubernetesVersion: ">=1.18.0 <1.19.0"
+  image:
+    marketplace:
+      publisher: AzureStack
+      offer: Test
+      sku: capz-test-1804
+      version: 1.0.0
+- environment: AzureStackCloud
+  osType: Windows
+  image:
+    id: /subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/capz-windows-2019
+- environment: AzurePublicCloud
+  image:
+    sharedGallery:
+      subscriptionID: "123"
+      resourceGroup: images
+      gallery: capz
+      name: capz-ubuntu-1804
+      version: 0.3.0
+`
+
+func writeImageCatalog(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "catalog.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImageCatalogGetDefaultImage(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "imagecatalog")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	path := writeImageCatalog(t, dir, testImageCatalog)
+	stack, err := LoadImageCatalog(path, "AzureStackCloud")
+	g.Expect(err).NotTo(HaveOccurred())
+	public, err := LoadImageCatalog(path, "AzurePublicCloud")
+	g.Expect(err).NotTo(HaveOccurred())
+	ubuntu, err := GetDefaultUbuntuImage("v1.19.1")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tests := []struct {
+		name       string
+		catalog    *ImageCatalog
+		osType     string
+		k8sVersion string
+		expected   *infrav1.Image
+	}{
+		{
+			name:       "Linux image of the environment and Kubernetes version",
+			catalog:    stack,
+			osType:     infrav1.LinuxOSType,
+			k8sVersion: "v1.18.8",
+			expected: &infrav1.Image{
+				Marketplace: &infrav1.AzureMarketplaceImage{Publisher: "AzureStack", Offer: "Test", SKU: "capz-test-1804", Version: "1.0.0"},
+			},
+		},
+		{
+			name:       "Windows image of the environment",
+			catalog:    stack,
+			osType:     infrav1.WindowsOSType,
+			k8sVersion: "v1.18.8",
+			expected:   &infrav1.Image{ID: to.StringPtr("/subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/capz-windows-2019")},
+		},
+		{
+			name:       "default image for a Kubernetes version not in the catalog",
+			catalog:    stack,
+			osType:     infrav1.LinuxOSType,
+			k8sVersion: "v1.19.1",
+			expected:   ubuntu,
+		},
+		{
+			name:       "image of another environment",
+			catalog:    public,
+			k8sVersion: "v1.19.1",
+			expected: &infrav1.Image{
+				SharedGallery: &infrav1.AzureSharedGalleryImage{SubscriptionID: "123", ResourceGroup: "images", Gallery: "capz", Name: "capz-ubuntu-1804", Version: "0.3.0"},
+			},
+		},
+		{
+			name:       "default image without catalog",
+			catalog:    nil,
+			osType:     infrav1.LinuxOSType,
+			k8sVersion: "v1.19.1",
+			expected:   ubuntu,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			image, err := tc.catalog.GetDefaultImage(tc.osType, tc.k8sVersion)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(image).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestLoadImageCatalogInvalid(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "imagecatalog")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	_, err = LoadImageCatalog(writeImageCatalog(t, dir, `
+images:
+- osType: Solaris
+  kubernetesVersion: "1.18"
+  image: {}
+`), "AzureStackCloud")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("images[0].osType"))
+	g.Expect(err.Error()).To(ContainSubstring("images[0].kubernetesVersion"))
+	g.Expect(err.Error()).To(ContainSubstring("images[0].image"))
+
+	_, err = LoadImageCatalog(filepath.Join(dir, "does-not-exist.yaml"), "AzureStackCloud")
+	g.Expect(err).To(HaveOccurred())
+}
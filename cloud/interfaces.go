@@ -52,11 +52,13 @@ type CredentialGetter interface {
 	GetCredentials(ctx context.Context, group string, cluster string) ([]byte, error)
 }
 
-// Authorizer is an interface which can get the subscription ID, base URI, and authorizer for an Azure service.
+// Authorizer is an interface which can get the subscription ID, base URI, authorizer and cloud environment for an
+// Azure service.
 type Authorizer interface {
 	SubscriptionID() string
 	BaseURI() string
 	Authorizer() autorest.Authorizer
+	CloudEnvironment() string
 }
 
 // ClusterDescriber is an interface which can get common Azure Cluster information
@@ -71,4 +73,6 @@ type ClusterDescriber interface {
 	NodeSubnet() *infrav1.SubnetSpec
 	ControlPlaneSubnet() *infrav1.SubnetSpec
 	RouteTable() *infrav1.RouteTable
+	PrivateDNSZone() *infrav1.PrivateDNSZone
+	AvailabilitySets() infrav1.AvailabilitySetsSpec
 }
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"strings"
+
+	autorestazure "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+)
+
+// ProfileFeatures are the features requested by a virtual machine, scale set or cluster that depend on the API
+// versions of the Azure Stack Hub API profile the provider is built against, or on resource providers outside of the
+// profile that only the Azure clouds serve.
+type ProfileFeatures struct {
+	// SpotVM is whether the virtual machine uses the Spot priority.
+	SpotVM bool
+	// EphemeralOSDisk is whether the OS disk is an ephemeral disk.
+	EphemeralOSDisk bool
+	// MarketplaceTerms is whether the terms of the plan of a Marketplace image are accepted.
+	MarketplaceTerms bool
+	// PrivateDNSZone is whether a private DNS zone, or records in it, are managed.
+	PrivateDNSZone bool
+}
+
+// ValidateProfileFeatures returns an error naming the requested features that the Azure Stack Hub API profile, or the
+// cloud environment, does not support. The webhooks reject the features the profile does not support, but the VM,
+// scale set and private DNS services check them again before creating a resource, so that an object admitted while
+// the webhooks were bypassed fails instead of silently losing the features. The features that depend on the cloud
+// environment are only checked by the services, since the webhooks do not know the environment.
+func ValidateProfileFeatures(kind, name, environment string, features ProfileFeatures) error {
+	var unsupported []string
+	if features.SpotVM {
+		unsupported = append(unsupported, "Spot VMs")
+	}
+	if features.EphemeralOSDisk {
+		unsupported = append(unsupported, "ephemeral OS disks")
+	}
+	if len(unsupported) > 0 {
+		return errors.Errorf("cannot create %s %s: the Azure Stack Hub API profile does not support %s", kind, name, strings.Join(unsupported, ", "))
+	}
+
+	if features.MarketplaceTerms && !IsAzureCloud(environment) {
+		unsupported = append(unsupported, "accepting Marketplace terms")
+	}
+	if features.PrivateDNSZone && !IsAzureCloud(environment) {
+		unsupported = append(unsupported, "private DNS zones")
+	}
+	if len(unsupported) > 0 {
+		return errors.Errorf("cannot create %s %s: the %s environment does not support %s", kind, name, environment, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// IsAzureCloud returns true if the cloud environment is one of the Azure clouds rather than Azure Stack Hub. Only the
+// Azure clouds serve the resource providers outside of the Azure Stack Hub API profile, such as private DNS zones and
+// Marketplace ordering.
+func IsAzureCloud(environment string) bool {
+	for _, env := range []autorestazure.Environment{autorestazure.PublicCloud, autorestazure.USGovernmentCloud, autorestazure.ChinaCloud, autorestazure.GermanCloud} {
+		if strings.EqualFold(environment, env.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValidateProfileFeatures(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name          string
+		environment   string
+		features      ProfileFeatures
+		expectedError string
+	}{
+		{
+			name:     "no features",
+			features: ProfileFeatures{},
+		},
+		{
+			name:          "one unsupported feature",
+			features:      ProfileFeatures{SpotVM: true},
+			expectedError: "cannot create VM my-vm: the Azure Stack Hub API profile does not support Spot VMs",
+		},
+		{
+			name:          "several unsupported features",
+			features:      ProfileFeatures{SpotVM: true, EphemeralOSDisk: true},
+			expectedError: "cannot create VM my-vm: the Azure Stack Hub API profile does not support Spot VMs, ephemeral OS disks",
+		},
+		{
+			name:        "Marketplace terms on Azure",
+			environment: "AzurePublicCloud",
+			features:    ProfileFeatures{MarketplaceTerms: true},
+		},
+		{
+			name:          "Marketplace terms on Azure Stack Hub",
+			environment:   "AzureStackCloud",
+			features:      ProfileFeatures{MarketplaceTerms: true},
+			expectedError: "cannot create VM my-vm: the AzureStackCloud environment does not support accepting Marketplace terms",
+		},
+		{
+			name:        "private DNS zone on Azure",
+			environment: "AzurePublicCloud",
+			features:    ProfileFeatures{PrivateDNSZone: true},
+		},
+		{
+			name:          "private DNS zone on Azure Stack Hub",
+			environment:   "AzureStackCloud",
+			features:      ProfileFeatures{PrivateDNSZone: true},
+			expectedError: "cannot create VM my-vm: the AzureStackCloud environment does not support private DNS zones",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateProfileFeatures("VM", "my-vm", tc.environment, tc.features)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			}
+		})
+	}
+}
@@ -45,6 +45,7 @@ type AzureClients struct {
 	SubscriptionID             string
 	ResourceManagerEndpoint    string
 	ResourceManagerVMDNSSuffix string
+	EnvironmentName            string
 	Authorizer                 autorest.Authorizer
 }
 
@@ -63,6 +64,7 @@ func (c *AzureClients) setCredentials(subscriptionID string) error {
 
 	c.ResourceManagerEndpoint = env.ResourceManagerEndpoint
 	c.ResourceManagerVMDNSSuffix = GetAzureDNSZoneForEnvironment("AzureStackCloud")
+	c.EnvironmentName = getEnvironmentName()
 	c.Authorizer, err = getAuthorizerForResource(env)
 	return err
 }
@@ -78,6 +80,14 @@ func getSubscriptionID(subscriptionID string) (string, error) {
 	return subscriptionID, nil
 }
 
+// getEnvironmentName returns the name of the cloud environment given by AZURE_ENVIRONMENT, AzureStackCloud if unset.
+func getEnvironmentName() string {
+	if name := os.Getenv("AZURE_ENVIRONMENT"); name != "" {
+		return name
+	}
+	return AzureStackCloud
+}
+
 // GetAzureDNSZoneForEnvironment returnes the DNSZone to be used with the
 // cloud environment, the default is the public cloud
 func GetAzureDNSZoneForEnvironment(environmentName string) string {
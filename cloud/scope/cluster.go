@@ -19,10 +19,15 @@ package scope
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/klogr"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
@@ -31,6 +36,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// SSHPublicKeySecretKey is the key of the public key in the Secret storing the SSH key pair generated for a cluster,
+// next to the private key under the ssh-privatekey key.
+const SSHPublicKeySecretKey = "ssh-publickey"
+
 // ClusterScopeParams defines the input parameters used to create a new Scope.
 type ClusterScopeParams struct {
 	AzureClients
@@ -100,6 +109,11 @@ func (s *ClusterScope) Authorizer() autorest.Authorizer {
 	return s.AzureClients.Authorizer
 }
 
+// CloudEnvironment returns the name of the Azure cloud environment.
+func (s *ClusterScope) CloudEnvironment() string {
+	return s.AzureClients.EnvironmentName
+}
+
 // Network returns the cluster network object.
 func (s *ClusterScope) Network() *infrav1.Network {
 	return &s.AzureCluster.Status.Network
@@ -237,6 +251,73 @@ func (s *ClusterScope) Location() string {
 	return s.AzureCluster.Spec.Location
 }
 
+// AvailabilitySets returns the configuration of the availability sets of the cluster.
+func (s *ClusterScope) AvailabilitySets() infrav1.AvailabilitySetsSpec {
+	return s.AzureCluster.Spec.AvailabilitySets
+}
+
+// IsFailureDomainAllowed returns true if the cluster may use the given failure domain.
+// All failure domains are allowed unless the cluster restricts them.
+func (s *ClusterScope) IsFailureDomainAllowed(name string) bool {
+	if len(s.AzureCluster.Spec.AllowedFailureDomains) == 0 {
+		return true
+	}
+	for _, fd := range s.AzureCluster.Spec.AllowedFailureDomains {
+		if fd == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FailureDomains returns the failure domains reported to Cluster API for the cluster.
+func (s *ClusterScope) FailureDomains() clusterv1.FailureDomains {
+	return s.AzureCluster.Status.FailureDomains
+}
+
+// PrivateDNSZone returns the cluster private DNS zone configuration.
+func (s *ClusterScope) PrivateDNSZone() *infrav1.PrivateDNSZone {
+	return s.AzureCluster.Spec.NetworkSpec.PrivateDNSZone
+}
+
+// PrivateDNSSpec returns the private DNS zone spec, with the API server record.
+func (s *ClusterScope) PrivateDNSSpec() *azure.PrivateDNSSpec {
+	zone := s.PrivateDNSZone()
+	if zone == nil {
+		return nil
+	}
+	spec := &azure.PrivateDNSSpec{
+		ZoneName:          zone.Name,
+		ZoneResourceGroup: zone.ResourceGroup,
+		ManageZone:        zone.ResourceGroup == "",
+		LinkName:          azure.GenerateVNetLinkName(s.ClusterName()),
+		Records: []azure.PrivateDNSRecordSpec{
+			{
+				Hostname:                 s.APIServerRecordName(),
+				InternalLoadBalancerName: azure.GenerateInternalLBName(s.ClusterName()),
+				APIServer:                true,
+			},
+		},
+	}
+	if spec.ManageZone {
+		spec.ZoneResourceGroup = s.ResourceGroup()
+	}
+	return spec
+}
+
+// APIServerRecordName returns the name of the API server record in the private DNS zone.
+func (s *ClusterScope) APIServerRecordName() string {
+	if zone := s.PrivateDNSZone(); zone != nil && zone.APIServerRecordName != "" {
+		return zone.APIServerRecordName
+	}
+	return azure.GenerateAPIServerRecordName(s.ClusterName())
+}
+
+// SetAPIServerPrivateDNSName sets the fully qualified domain name of the API server in the private DNS zone.
+func (s *ClusterScope) SetAPIServerPrivateDNSName(fqdn string) {
+	s.Network().APIServerPrivateDNSName = fqdn
+}
+
 // GenerateFQDN generates a fully qualified domain name, based on the public IP name and cluster location.
 func (s *ClusterScope) GenerateFQDN() string {
 	return fmt.Sprintf("%s.%s.%s", s.Network().APIServerIP.Name, s.Location(), s.AzureClients.ResourceManagerVMDNSSuffix)
@@ -276,6 +357,11 @@ func (s *ClusterScope) APIServerPort() int32 {
 	return 6443
 }
 
+// ClearFailureDomains removes the failure domains reported in the status of the cluster.
+func (s *ClusterScope) ClearFailureDomains() {
+	s.AzureCluster.Status.FailureDomains = nil
+}
+
 // SetFailureDomain will set the spec for a for a given key
 func (s *ClusterScope) SetFailureDomain(id string, spec clusterv1.FailureDomainSpec) {
 	if s.AzureCluster.Status.FailureDomains == nil {
@@ -283,3 +369,60 @@ func (s *ClusterScope) SetFailureDomain(id string, spec clusterv1.FailureDomainS
 	}
 	s.AzureCluster.Status.FailureDomains[id] = spec
 }
+
+// ReconcileSSHKeySecret generates the SSH key pair of the cluster and stores it in a Secret owned by the AzureCluster,
+// unless the Secret already exists.
+func (s *ClusterScope) ReconcileSSHKeySecret(ctx context.Context) error {
+	return reconcileSSHKeySecret(ctx, s.client, s.Namespace(), s.ClusterName(),
+		*metav1.NewControllerRef(s.AzureCluster, infrav1.GroupVersion.WithKind("AzureCluster")))
+}
+
+// reconcileSSHKeySecret generates the SSH key pair of a cluster and stores it in a Secret with the given owner, unless
+// the Secret already exists. The key pair is never regenerated, as the machines of the cluster authorize its public key.
+func reconcileSSHKeySecret(ctx context.Context, c client.Client, namespace, clusterName string, owner metav1.OwnerReference) error {
+	key := types.NamespacedName{Namespace: namespace, Name: azure.GenerateSSHKeySecretName(clusterName)}
+	err := c.Get(ctx, key, &corev1.Secret{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get SSH key secret %s/%s", namespace, key.Name)
+	}
+
+	privateKey, publicKey, err := azure.GenerateSSHKeyPair()
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            key.Name,
+			Namespace:       namespace,
+			Labels:          map[string]string{clusterv1.ClusterLabelName: clusterName},
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Type: corev1.SecretTypeSSHAuth,
+		Data: map[string][]byte{
+			corev1.SSHAuthPrivateKey: privateKey,
+			SSHPublicKeySecretKey:    publicKey,
+		},
+	}
+	if err := c.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create SSH key secret %s/%s", namespace, key.Name)
+	}
+	return nil
+}
+
+// getClusterSSHPublicKey returns the public key of the SSH key pair generated for a cluster, in the OpenSSH
+// authorized_keys format.
+func getClusterSSHPublicKey(ctx context.Context, c client.Client, namespace, clusterName string) (string, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: azure.GenerateSSHKeySecretName(clusterName)}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return "", errors.Wrapf(err, "failed to retrieve SSH key secret %s/%s", namespace, key.Name)
+	}
+	publicKey, ok := secret.Data[SSHPublicKeySecretKey]
+	if !ok {
+		return "", errors.Errorf("error retrieving SSH public key: secret %s/%s is missing the %s key", namespace, key.Name, SSHPublicKeySecretKey)
+	}
+	return strings.TrimSpace(string(publicKey)), nil
+}
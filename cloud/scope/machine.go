@@ -19,11 +19,18 @@ package scope
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
 	"k8s.io/apimachinery/pkg/util/uuid"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/klogr"
 	"k8s.io/utils/pointer"
@@ -35,6 +42,22 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SerialConsoleLogKey is the key of the serial console log in the Secret storing the boot diagnostics of a machine.
+const SerialConsoleLogKey = "serial-console.log"
+
+// AdminPasswordSecretKey is the key of the password in the Secret storing the administrator password of Windows
+// machines.
+const AdminPasswordSecretKey = "password"
+
+const (
+	// vmProvisioningBackoffBase is the delay before creating the VM of a machine again after its first failed
+	// provisioning attempt. The delay doubles with each failed attempt.
+	vmProvisioningBackoffBase = 30 * time.Second
+	// vmProvisioningBackoffMax is the maximum delay before creating the VM of a machine again.
+	vmProvisioningBackoffMax = 30 * time.Minute
 )
 
 // MachineScopeParams defines the input parameters used to create a new MachineScope.
@@ -120,8 +143,11 @@ func (m *MachineScope) NICSpecs() []azure.NICSpec {
 		VNetName:              m.Vnet().Name,
 		VNetResourceGroup:     m.Vnet().ResourceGroup,
 		SubnetName:            m.Subnet().Name,
+		StaticIPAddress:       m.AzureMachine.Status.StaticIPAddress,
 		VMSize:                m.AzureMachine.Spec.VMSize,
 		AcceleratedNetworking: m.AzureMachine.Spec.AcceleratedNetworking,
+		EnableIPForwarding:    m.AzureMachine.Spec.EnableIPForwarding,
+		SecondaryIPCount:      m.AzureMachine.Spec.SecondaryIPCount,
 	}
 	if m.Role() == infrav1.ControlPlane {
 		spec.PublicLoadBalancerName = azure.GeneratePublicLBName(m.ClusterName())
@@ -141,19 +167,57 @@ func (m *MachineScope) NICSpecs() []azure.NICSpec {
 			PublicIPName:          azure.GenerateNodePublicIPName(m.Name()),
 			VMSize:                m.AzureMachine.Spec.VMSize,
 			AcceleratedNetworking: m.AzureMachine.Spec.AcceleratedNetworking,
+			EnableIPForwarding:    m.AzureMachine.Spec.EnableIPForwarding,
 		})
 	}
+	for _, nic := range m.AzureMachine.Spec.AdditionalNetworkInterfaces {
+		additionalSpec := azure.NICSpec{
+			Name:                  azure.GenerateAdditionalNICName(m.Name(), nic.NameSuffix),
+			MachineName:           m.Name(),
+			MachineRole:           m.Role(),
+			VNetName:              m.Vnet().Name,
+			VNetResourceGroup:     m.Vnet().ResourceGroup,
+			SubnetName:            nic.SubnetName,
+			StaticIPAddress:       nic.StaticIPAddress,
+			VMSize:                m.AzureMachine.Spec.VMSize,
+			AcceleratedNetworking: m.AzureMachine.Spec.AcceleratedNetworking,
+			EnableIPForwarding:    m.AzureMachine.Spec.EnableIPForwarding,
+		}
+		if nic.AcceleratedNetworking != nil {
+			additionalSpec.AcceleratedNetworking = nic.AcceleratedNetworking
+		}
+		if nic.LoadBalancerBackendPool {
+			// inbound NAT rules only target the primary network interface, so control plane machines
+			// only join the internal load balancer on additional network interfaces.
+			if m.Role() == infrav1.ControlPlane {
+				additionalSpec.InternalLoadBalancerName = azure.GenerateInternalLBName(m.ClusterName())
+			} else {
+				additionalSpec.PublicLoadBalancerName = m.ClusterName()
+			}
+		}
+		specs = append(specs, additionalSpec)
+	}
 
 	return specs
 }
 
 // DiskSpecs returns the disk specs.
 func (m *MachineScope) DiskSpecs() []azure.DiskSpec {
-	spec := azure.DiskSpec{
-		Name: azure.GenerateOSDiskName(m.Name()),
+	specs := []azure.DiskSpec{
+		{
+			Name: azure.GenerateOSDiskName(m.Name()),
+		},
+	}
+	for _, disk := range m.AzureMachine.Spec.DataDisks {
+		specs = append(specs, azure.DiskSpec{
+			Name:           azure.GenerateDataDiskName(m.Name(), disk.NameSuffix),
+			MachineName:    m.Name(),
+			NameSuffix:     disk.NameSuffix,
+			RetainOnDelete: disk.RetainOnDelete,
+		})
 	}
 
-	return []azure.DiskSpec{spec}
+	return specs
 }
 
 // RoleAssignmentSpecs returns the role assignment specs.
@@ -197,6 +261,50 @@ func (m *MachineScope) AvailabilityZone() string {
 	return ""
 }
 
+// ZonePlacementPolicy returns the zone placement policy of the machine, defaulting to Strict.
+func (m *MachineScope) ZonePlacementPolicy() infrav1.ZonePlacementPolicy {
+	if m.AzureMachine.Spec.ZonePlacementPolicy == "" {
+		return infrav1.ZonePlacementPolicyStrict
+	}
+	return m.AzureMachine.Spec.ZonePlacementPolicy
+}
+
+// SetZonePlacement records the availability zone chosen for the VM of the machine and why.
+func (m *MachineScope) SetZonePlacement(zone string, reason infrav1.ZonePlacementReason, message string) {
+	placement := m.AzureMachine.Status.ZonePlacement
+	if placement == nil {
+		placement = &infrav1.ZonePlacementStatus{}
+	}
+	placement.Zone = zone
+	placement.Reason = reason
+	placement.Message = message
+	m.AzureMachine.Status.ZonePlacement = placement
+}
+
+// IsZoneExcluded returns true if the allocation of the VM of the machine failed in the given zone.
+func (m *MachineScope) IsZoneExcluded(zone string) bool {
+	if m.AzureMachine.Status.ZonePlacement == nil {
+		return false
+	}
+	for _, excluded := range m.AzureMachine.Status.ZonePlacement.ExcludedZones {
+		if excluded == zone {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludeZone records that the allocation of the VM of the machine failed in the given zone.
+func (m *MachineScope) ExcludeZone(zone string) {
+	if m.IsZoneExcluded(zone) {
+		return
+	}
+	if m.AzureMachine.Status.ZonePlacement == nil {
+		m.AzureMachine.Status.ZonePlacement = &infrav1.ZonePlacementStatus{}
+	}
+	m.AzureMachine.Status.ZonePlacement.ExcludedZones = append(m.AzureMachine.Status.ZonePlacement.ExcludedZones, zone)
+}
+
 // Name returns the AzureMachine name.
 func (m *MachineScope) Name() string {
 	return m.AzureMachine.Name
@@ -252,6 +360,12 @@ func (m *MachineScope) SetVMState(v infrav1.VMState) {
 	m.AzureMachine.Status.VMState = &v
 }
 
+// SetVMPowerState sets the power state of the VM and the status of its VM agent.
+func (m *MachineScope) SetVMPowerState(powerState infrav1.VMPowerState, agentStatus string) {
+	m.AzureMachine.Status.PowerState = powerState
+	m.AzureMachine.Status.VMAgentStatus = agentStatus
+}
+
 // SetReady sets the AzureMachine Ready Status to true.
 func (m *MachineScope) SetReady() {
 	m.AzureMachine.Status.Ready = true
@@ -285,6 +399,562 @@ func (m *MachineScope) SetAddresses(addrs []corev1.NodeAddress) {
 	m.AzureMachine.Status.Addresses = addrs
 }
 
+// AvailabilitySetSpec returns the spec of the availability set of the machine, used when the VM is not placed in
+// an availability zone: one availability set for the control plane and one per MachineDeployment.
+// It returns nil for machines that do not belong to either.
+func (m *MachineScope) AvailabilitySetSpec() *azure.AvailabilitySetSpec {
+	var nodeGroupName string
+	if m.IsControlPlane() {
+		nodeGroupName = infrav1.ControlPlane
+	} else if mdName, ok := m.Machine.Labels[clusterv1.MachineDeploymentLabelName]; ok {
+		nodeGroupName = mdName
+	} else {
+		return nil
+	}
+	availabilitySets := m.AvailabilitySets()
+	spec := &azure.AvailabilitySetSpec{
+		Name:              azure.GenerateAvailabilitySetName(m.ClusterName(), nodeGroupName),
+		FaultDomainCount:  infrav1.DefaultFaultDomainCount,
+		UpdateDomainCount: infrav1.DefaultUpdateDomainCount,
+	}
+	if availabilitySets.FaultDomainCount != nil {
+		spec.FaultDomainCount = *availabilitySets.FaultDomainCount
+	}
+	if availabilitySets.UpdateDomainCount != nil {
+		spec.UpdateDomainCount = *availabilitySets.UpdateDomainCount
+	}
+	return spec
+}
+
+// PrivateDNSSpec returns the private DNS spec with the machine record, if node records are enabled.
+func (m *MachineScope) PrivateDNSSpec() *azure.PrivateDNSSpec {
+	zone := m.PrivateDNSZone()
+	if zone == nil || !zone.NodeRecords {
+		return nil
+	}
+	spec := &azure.PrivateDNSSpec{
+		ZoneName:          zone.Name,
+		ZoneResourceGroup: zone.ResourceGroup,
+		Records: []azure.PrivateDNSRecordSpec{
+			{
+				Hostname: m.Name(),
+			},
+		},
+	}
+	if spec.ZoneResourceGroup == "" {
+		spec.ZoneResourceGroup = m.ResourceGroup()
+	}
+	for _, address := range m.AzureMachine.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			spec.Records[0].IP = address.Address
+			break
+		}
+	}
+	return spec
+}
+
+// VMExtensionSpecs returns the specs of the VM extensions installed on the machine: the extensions installed
+// by the provider, followed by the extensions of the machine spec.
+func (m *MachineScope) VMExtensionSpecs(ctx context.Context) ([]azure.VMExtensionSpec, error) {
+	var specs []azure.VMExtensionSpec
+	if m.IsWindows() && m.WindowsRemoteAccess() == infrav1.WindowsRemoteAccessOpenSSH {
+		sshKeys, err := m.GetSSHPublicKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, azure.VMExtensionSpec{
+			Name:      azure.WindowsOpenSSHExtensionName,
+			VMName:    m.Name(),
+			Publisher: azure.CustomScriptExtensionPublisher,
+			Type:      azure.CustomScriptExtensionType,
+			Version:   azure.CustomScriptExtensionVersion,
+			ProtectedSettings: map[string]interface{}{
+				"commandToExecute": azure.GenerateWindowsOpenSSHCommand(sshKeys),
+			},
+		})
+	}
+	if !m.IsWindows() {
+		sshKeys, err := m.GetSSHPublicKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// The keys of a Linux machine are only provisioned when its VM is created. Once they changed, they are
+		// pushed by the VM access extension, which is kept so that it keeps replacing the authorized keys.
+		if azure.GenerateSSHPublicKeysHash(sshKeys) != m.SSHPublicKeysHash() || m.hasVMExtensionStatus(azure.SSHAccessExtensionName) {
+			specs = append(specs, azure.VMExtensionSpec{
+				Name:      azure.SSHAccessExtensionName,
+				VMName:    m.Name(),
+				Publisher: azure.VMAccessExtensionPublisher,
+				Type:      azure.VMAccessExtensionType,
+				Version:   azure.VMAccessExtensionVersion,
+				ProtectedSettings: map[string]interface{}{
+					"username":          m.AdminUsername(),
+					"ssh_key":           strings.Join(sshKeys, "\n"),
+					"remove_prior_keys": true,
+				},
+			})
+		}
+	}
+	if m.VerifyBootstrap() {
+		specs = append(specs, azure.VMExtensionSpec{
+			Name:      azure.BootstrapCheckExtensionName,
+			VMName:    m.Name(),
+			Publisher: azure.LinuxCustomScriptExtensionPublisher,
+			Type:      azure.LinuxCustomScriptExtensionType,
+			Version:   azure.LinuxCustomScriptExtensionVersion,
+			ProtectedSettings: map[string]interface{}{
+				"commandToExecute": azure.GenerateBootstrapCheckCommand(),
+			},
+		})
+	}
+
+	extSpecs, err := getVMExtensionSpecs(ctx, m.client, m.Namespace(), m.Name(), m.AzureMachine.Spec.VMExtensions)
+	if err != nil {
+		return nil, err
+	}
+	return append(specs, extSpecs...), nil
+}
+
+// VerifyBootstrap returns true if the bootstrap of the machine is verified before it is marked as ready.
+// The bootstrap of Windows machines is not verified.
+func (m *MachineScope) VerifyBootstrap() bool {
+	return m.AzureMachine.Spec.VerifyBootstrap && !m.IsWindows()
+}
+
+// BootstrapCheckStatus returns the status of the VM extension checking that the machine bootstrapped
+// successfully, or nil if it has not been installed.
+func (m *MachineScope) BootstrapCheckStatus() *infrav1.VMExtensionStatus {
+	for i := range m.AzureMachine.Status.VMExtensions {
+		if m.AzureMachine.Status.VMExtensions[i].Name == azure.BootstrapCheckExtensionName {
+			return &m.AzureMachine.Status.VMExtensions[i]
+		}
+	}
+	return nil
+}
+
+// hasVMExtensionStatus returns true if the status of the machine reports a VM extension with the given name.
+func (m *MachineScope) hasVMExtensionStatus(name string) bool {
+	for _, status := range m.AzureMachine.Status.VMExtensions {
+		if status.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// VMExtensionStatuses returns the last reported provisioning state of the VM extensions of the machine.
+func (m *MachineScope) VMExtensionStatuses() []infrav1.VMExtensionStatus {
+	return m.AzureMachine.Status.VMExtensions
+}
+
+// SetVMExtensionStatuses sets the provisioning state of the VM extensions of the machine.
+func (m *MachineScope) SetVMExtensionStatuses(statuses []infrav1.VMExtensionStatus) {
+	m.AzureMachine.Status.VMExtensions = statuses
+}
+
+// VMProvisioningFailedAttempts returns the number of times the VM of the machine failed to provision.
+func (m *MachineScope) VMProvisioningFailedAttempts() int32 {
+	if m.AzureMachine.Status.VMProvisioning == nil {
+		return 0
+	}
+	return m.AzureMachine.Status.VMProvisioning.FailedAttempts
+}
+
+// VMProvisioningLastError returns the error reported by Azure for the last failed provisioning of the VM of the machine.
+func (m *MachineScope) VMProvisioningLastError() string {
+	if m.AzureMachine.Status.VMProvisioning == nil {
+		return ""
+	}
+	return m.AzureMachine.Status.VMProvisioning.LastError
+}
+
+// VMProvisioningBackoff returns how long to wait before creating the VM of the machine again after a failed
+// provisioning, or zero if it can be created right away.
+func (m *MachineScope) VMProvisioningBackoff() time.Duration {
+	status := m.AzureMachine.Status.VMProvisioning
+	if status == nil || status.NextAttemptTime == nil {
+		return 0
+	}
+	if backoff := time.Until(status.NextAttemptTime.Time); backoff > 0 {
+		return backoff
+	}
+	return 0
+}
+
+// RecordVMProvisioningFailure records a failed provisioning of the VM of the machine with the error reported by
+// Azure, and schedules the next attempt after an exponential backoff.
+func (m *MachineScope) RecordVMProvisioningFailure(message string) {
+	status := m.AzureMachine.Status.VMProvisioning
+	if status == nil {
+		status = &infrav1.VMProvisioningStatus{}
+	}
+	status.FailedAttempts++
+	status.LastError = message
+	next := metav1.NewTime(time.Now().Add(vmProvisioningBackoff(status.FailedAttempts)))
+	status.NextAttemptTime = &next
+	m.AzureMachine.Status.VMProvisioning = status
+}
+
+// ResetVMProvisioning clears the failed provisioning attempts of the VM of the machine, along with the annotation
+// requesting it.
+func (m *MachineScope) ResetVMProvisioning() {
+	m.AzureMachine.Status.VMProvisioning = nil
+	delete(m.AzureMachine.Annotations, infrav1.ResetVMProvisioningAnnotation)
+}
+
+// vmProvisioningBackoff returns the delay before creating a VM again after the given number of failed attempts.
+func vmProvisioningBackoff(failedAttempts int32) time.Duration {
+	backoff := vmProvisioningBackoffBase
+	for i := int32(1); i < failedAttempts && backoff < vmProvisioningBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > vmProvisioningBackoffMax {
+		return vmProvisioningBackoffMax
+	}
+	return backoff
+}
+
+// getVMExtensionSpecs returns the specs of VM extensions, with their protected settings read from their Secrets.
+func getVMExtensionSpecs(ctx context.Context, c client.Client, namespace, vmName string, extensions []infrav1.VMExtension) ([]azure.VMExtensionSpec, error) {
+	specs := make([]azure.VMExtensionSpec, 0, len(extensions))
+	for _, ext := range extensions {
+		spec := azure.VMExtensionSpec{
+			Name:      ext.Name,
+			VMName:    vmName,
+			Publisher: ext.Publisher,
+			Type:      ext.Type,
+			Version:   ext.Version,
+		}
+		if len(ext.Settings) > 0 {
+			spec.Settings = make(map[string]interface{}, len(ext.Settings))
+			for k, v := range ext.Settings {
+				spec.Settings[k] = v
+			}
+		}
+		if ext.ProtectedSettingsRef != nil {
+			secret := &corev1.Secret{}
+			key := types.NamespacedName{Namespace: namespace, Name: ext.ProtectedSettingsRef.Name}
+			if err := c.Get(ctx, key, secret); err != nil {
+				return nil, errors.Wrapf(err, "failed to retrieve protected settings secret %s/%s of VM extension %s", namespace, key.Name, ext.Name)
+			}
+			spec.ProtectedSettings = make(map[string]interface{}, len(secret.Data))
+			for k, v := range secret.Data {
+				spec.ProtectedSettings[k] = string(v)
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// BootDiagnostics returns the boot diagnostics of the machine.
+func (m *MachineScope) BootDiagnostics() *infrav1.BootDiagnostics {
+	return m.AzureMachine.Spec.BootDiagnostics
+}
+
+// StoreSerialConsoleLog stores the serial console log of the machine in a Secret owned by the AzureMachine, and
+// returns the name of the Secret.
+func (m *MachineScope) StoreSerialConsoleLog(ctx context.Context, log []byte) (string, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      azure.GenerateBootDiagnosticsSecretName(m.Name()),
+			Namespace: m.Namespace(),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(m.AzureMachine, infrav1.GroupVersion.WithKind("AzureMachine")),
+			},
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, m.client, secret, func() error {
+		secret.Data = map[string][]byte{
+			SerialConsoleLogKey: log,
+		}
+		return nil
+	}); err != nil {
+		return "", errors.Wrapf(err, "failed to store serial console log in secret %s/%s", secret.Namespace, secret.Name)
+	}
+	return secret.Name, nil
+}
+
+// ResolvedImage returns the image the VM of the machine was created from, as recorded in its status.
+func (m *MachineScope) ResolvedImage() *infrav1.Image {
+	return m.AzureMachine.Status.Image
+}
+
+// SetResolvedImage records the image the VM of the machine is created from.
+func (m *MachineScope) SetResolvedImage(image *infrav1.Image) {
+	m.AzureMachine.Status.Image = image
+}
+
+// PinImageVersion returns true if the image version of the machine is pinned to the version resolved for its
+// AzureMachineTemplate.
+func (m *MachineScope) PinImageVersion() bool {
+	return m.AzureMachine.Spec.PinImageVersion
+}
+
+// GetPinnedImage returns the image recorded on the AzureMachineTemplate the machine was created from, or nil if the
+// machine was not created from an AzureMachineTemplate or no image is recorded yet.
+func (m *MachineScope) GetPinnedImage(ctx context.Context) (*infrav1.Image, error) {
+	template, err := m.getTemplate(ctx)
+	if err != nil || template == nil {
+		return nil, err
+	}
+	data, ok := template.Annotations[infrav1.ResolvedImageAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	image := &infrav1.Image{}
+	if err := json.Unmarshal([]byte(data), image); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse resolved image of AzureMachineTemplate %s/%s", template.Namespace, template.Name)
+	}
+	return image, nil
+}
+
+// PinImage records the image resolved for the machine on the AzureMachineTemplate it was created from, unless an
+// image is already recorded. The update fails if another machine recorded its image concurrently, so that the machine
+// uses the image of the other machine when reconciled again.
+func (m *MachineScope) PinImage(ctx context.Context, image *infrav1.Image) error {
+	template, err := m.getTemplate(ctx)
+	if err != nil || template == nil {
+		return err
+	}
+	if _, ok := template.Annotations[infrav1.ResolvedImageAnnotation]; ok {
+		return nil
+	}
+	data, err := json.Marshal(image)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal resolved image")
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[infrav1.ResolvedImageAnnotation] = string(data)
+	if err := m.client.Update(ctx, template); err != nil {
+		return errors.Wrapf(err, "failed to record resolved image on AzureMachineTemplate %s/%s", template.Namespace, template.Name)
+	}
+	return nil
+}
+
+// getTemplate returns the AzureMachineTemplate the machine was created from, or nil if the machine was not created
+// from an AzureMachineTemplate or the template no longer exists.
+func (m *MachineScope) getTemplate(ctx context.Context) (*infrav1.AzureMachineTemplate, error) {
+	annotations := m.AzureMachine.GetAnnotations()
+	name, ok := annotations[clusterv1.TemplateClonedFromNameAnnotation]
+	if !ok || annotations[clusterv1.TemplateClonedFromGroupKindAnnotation] != infrav1.GroupVersion.WithKind("AzureMachineTemplate").GroupKind().String() {
+		return nil, nil
+	}
+	template := &infrav1.AzureMachineTemplate{}
+	if err := m.client.Get(ctx, client.ObjectKey{Namespace: m.Namespace(), Name: name}, template); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get AzureMachineTemplate %s/%s", m.Namespace(), name)
+	}
+	return template, nil
+}
+
+// IsWindows returns true if the machine runs Windows.
+func (m *MachineScope) IsWindows() bool {
+	return m.AzureMachine.Spec.OSDisk.OSType == infrav1.WindowsOSType
+}
+
+// WindowsRemoteAccess returns how the Windows machine is reached remotely.
+func (m *MachineScope) WindowsRemoteAccess() infrav1.WindowsRemoteAccess {
+	return windowsRemoteAccess(m.AzureMachine.Spec.WindowsConfiguration)
+}
+
+// GetWindowsAdminPassword returns the password of the administrator account of the Windows machine.
+func (m *MachineScope) GetWindowsAdminPassword(ctx context.Context) (string, error) {
+	return getWindowsAdminPassword(ctx, m.client, m.Namespace(), m.Name(),
+		*metav1.NewControllerRef(m.AzureMachine, infrav1.GroupVersion.WithKind("AzureMachine")), m.AzureMachine.Spec.WindowsConfiguration)
+}
+
+// AdminUsername returns the name of the administrator account of the machine.
+func (m *MachineScope) AdminUsername() string {
+	return adminUsername(m.AzureMachine.Spec.AdminUsername)
+}
+
+// GetSSHPublicKeys returns the SSH public keys authorized for the administrator account of the machine.
+func (m *MachineScope) GetSSHPublicKeys(ctx context.Context) ([]string, error) {
+	spec := m.AzureMachine.Spec
+	return getSSHPublicKeys(ctx, m.client, m.Namespace(), spec.SSHPublicKey, spec.AdditionalSSHPublicKeys, spec.SSHPublicKeysSecretRef)
+}
+
+// SetDefaultSSHPublicKey sets the SSH public key of the machine to the public key generated for its cluster, unless
+// the machine has keys already.
+func (m *MachineScope) SetDefaultSSHPublicKey(ctx context.Context) error {
+	spec := m.AzureMachine.Spec
+	if hasSSHPublicKeys(spec.SSHPublicKey, spec.AdditionalSSHPublicKeys, spec.SSHPublicKeysSecretRef) {
+		return nil
+	}
+	publicKey, err := getClusterSSHPublicKey(ctx, m.client, m.Namespace(), m.ClusterName())
+	if err != nil {
+		return err
+	}
+	m.AzureMachine.Spec.SSHPublicKey = base64.StdEncoding.EncodeToString([]byte(publicKey))
+	return nil
+}
+
+// SSHPublicKeysHash returns the hash of the SSH public keys the VM of the machine was created with.
+func (m *MachineScope) SSHPublicKeysHash() string {
+	return m.AzureMachine.Status.SSHPublicKeysHash
+}
+
+// SetSSHPublicKeysHash sets the hash of the SSH public keys the VM of the machine was created with.
+func (m *MachineScope) SetSSHPublicKeysHash(hash string) {
+	m.AzureMachine.Status.SSHPublicKeysHash = hash
+}
+
+// adminUsername returns the given administrator username, or the default username if it is empty.
+func adminUsername(username string) string {
+	if username == "" {
+		return azure.DefaultUserName
+	}
+	return username
+}
+
+// hasSSHPublicKeys reports whether a spec sets an SSH public key, additional SSH public keys or an SSH public keys
+// Secret.
+func hasSSHPublicKeys(sshPublicKey string, additional []string, secretRef *corev1.LocalObjectReference) bool {
+	return sshPublicKey != "" || len(additional) > 0 || secretRef != nil
+}
+
+// getSSHPublicKeys returns the base64 encoded SSH public key of a spec, decoded, followed by its additional SSH
+// public keys and the keys of its SSH public keys Secret, if any, without blank lines, comments and duplicates.
+func getSSHPublicKeys(ctx context.Context, c client.Client, namespace, sshPublicKey string, additional []string, secretRef *corev1.LocalObjectReference) ([]string, error) {
+	var keys []string
+	if sshPublicKey != "" {
+		decoded, err := base64.StdEncoding.DecodeString(sshPublicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode ssh public key")
+		}
+		keys = append(keys, string(decoded))
+	}
+	keys = append(keys, additional...)
+
+	if secretRef != nil {
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: namespace, Name: secretRef.Name}
+		if err := c.Get(ctx, key, secret); err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve SSH public keys secret %s/%s", namespace, key.Name)
+		}
+		value, ok := secret.Data["authorized_keys"]
+		if !ok {
+			return nil, errors.Errorf("error retrieving SSH public keys: secret %s/%s is missing the authorized_keys key", namespace, key.Name)
+		}
+		for i, line := range strings.Split(string(value), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line)); err != nil {
+				return nil, errors.Wrapf(err, "invalid SSH public key on line %d of secret %s/%s", i+1, namespace, key.Name)
+			}
+			keys = append(keys, line)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(keys))
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if _, ok := seen[key]; ok || key == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+// windowsRemoteAccess returns the remote access of a Windows configuration, OpenSSH if unset.
+func windowsRemoteAccess(config *infrav1.WindowsConfiguration) infrav1.WindowsRemoteAccess {
+	if config == nil || config.RemoteAccess == "" {
+		return infrav1.WindowsRemoteAccessOpenSSH
+	}
+	return config.RemoteAccess
+}
+
+// getWindowsAdminPassword reads the administrator password of a Windows configuration from its Secret. If the
+// configuration does not reference a Secret, the password is read from the Secret generated for the machine or
+// machine pool called name, which is created with the given owner on first use.
+func getWindowsAdminPassword(ctx context.Context, c client.Client, namespace, name string, owner metav1.OwnerReference, config *infrav1.WindowsConfiguration) (string, error) {
+	secretName := azure.GenerateAdminPasswordSecretName(name)
+	if config != nil && config.AdminPasswordSecretRef != nil {
+		secretName = config.AdminPasswordSecretRef.Name
+	} else if err := reconcileAdminPasswordSecret(ctx, c, namespace, secretName, owner); err != nil {
+		return "", err
+	}
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: secretName}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return "", errors.Wrapf(err, "failed to retrieve admin password secret %s/%s", namespace, key.Name)
+	}
+	value, ok := secret.Data[AdminPasswordSecretKey]
+	if !ok {
+		return "", errors.Errorf("error retrieving admin password: secret %s/%s is missing the %s key", namespace, key.Name, AdminPasswordSecretKey)
+	}
+	return string(value), nil
+}
+
+// reconcileAdminPasswordSecret generates a random administrator password and stores it in a Secret with the given
+// owner, unless the Secret already exists. The password is never regenerated, as it is the password of the
+// administrator account of the existing virtual machines.
+func reconcileAdminPasswordSecret(ctx context.Context, c client.Client, namespace, name string, owner metav1.OwnerReference) error {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	err := c.Get(ctx, key, &corev1.Secret{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get admin password secret %s/%s", namespace, name)
+	}
+
+	password, err := azure.GenerateAdminPassword()
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Data: map[string][]byte{
+			AdminPasswordSecretKey: []byte(password),
+		},
+	}
+	if err := c.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create admin password secret %s/%s", namespace, name)
+	}
+	return nil
+}
+
+// StaticIPAddressPool returns the pool of static IP addresses the machine claims its primary IP address from.
+func (m *MachineScope) StaticIPAddressPool() []string {
+	if len(m.AzureMachine.Spec.StaticIPAddressPool) > 0 {
+		return m.AzureMachine.Spec.StaticIPAddressPool
+	}
+	if subnet := m.Subnet(); subnet != nil {
+		return subnet.StaticIPAddressPool
+	}
+	return nil
+}
+
+// SetStaticIPAddress sets the static IP address claimed by the machine.
+func (m *MachineScope) SetStaticIPAddress(address string) {
+	m.AzureMachine.Status.StaticIPAddress = address
+}
+
+// SetSecondaryIPAddresses sets the secondary private IP addresses status.
+func (m *MachineScope) SetSecondaryIPAddresses(ips []string) {
+	m.AzureMachine.Status.SecondaryIPAddresses = ips
+}
+
+// SetAPIServerPrivateDNSName does nothing: the private DNS spec of a machine only has the machine record, the
+// API server record is reconciled with the cluster.
+func (m *MachineScope) SetAPIServerPrivateDNSName(fqdn string) {}
+
 // PatchObject persists the machine spec and status.
 func (m *MachineScope) PatchObject(ctx context.Context) error {
 	return m.patchHelper.Patch(ctx, m.AzureMachine)
@@ -0,0 +1,315 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestVMProvisioningBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := map[int32]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		6:  16 * time.Minute,
+		7:  30 * time.Minute,
+		20: 30 * time.Minute,
+	}
+	for failedAttempts, expected := range tests {
+		g.Expect(vmProvisioningBackoff(failedAttempts)).To(Equal(expected), "failed attempts: %d", failedAttempts)
+	}
+}
+
+func TestRecordVMProvisioningFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &MachineScope{
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{infrav1.ResetVMProvisioningAnnotation: ""},
+			},
+		},
+	}
+	g.Expect(m.VMProvisioningFailedAttempts()).To(BeZero())
+	g.Expect(m.VMProvisioningBackoff()).To(BeZero())
+
+	m.RecordVMProvisioningFailure("OperationNotAllowed: quota exceeded")
+	g.Expect(m.VMProvisioningFailedAttempts()).To(BeEquivalentTo(1))
+	g.Expect(m.VMProvisioningLastError()).To(Equal("OperationNotAllowed: quota exceeded"))
+	g.Expect(m.VMProvisioningBackoff()).To(BeNumerically("~", 30*time.Second, time.Second))
+
+	m.RecordVMProvisioningFailure("AllocationFailed: no capacity")
+	g.Expect(m.VMProvisioningFailedAttempts()).To(BeEquivalentTo(2))
+	g.Expect(m.VMProvisioningLastError()).To(Equal("AllocationFailed: no capacity"))
+	g.Expect(m.VMProvisioningBackoff()).To(BeNumerically("~", time.Minute, time.Second))
+
+	m.ResetVMProvisioning()
+	g.Expect(m.VMProvisioningFailedAttempts()).To(BeZero())
+	g.Expect(m.VMProvisioningBackoff()).To(BeZero())
+	g.Expect(m.AzureMachine.Annotations).NotTo(HaveKey(infrav1.ResetVMProvisioningAnnotation))
+}
+
+func TestPinImage(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+	template := &infrav1.AzureMachineTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-template", Namespace: "default"},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, template)
+
+	newMachineScope := func(name string) *MachineScope {
+		return &MachineScope{
+			client: c,
+			AzureMachine: &infrav1.AzureMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Annotations: map[string]string{
+						clusterv1.TemplateClonedFromNameAnnotation:      "my-template",
+						clusterv1.TemplateClonedFromGroupKindAnnotation: "AzureMachineTemplate.infrastructure.cluster.x-k8s.io",
+					},
+				},
+			},
+		}
+	}
+	image := func(version string) *infrav1.Image {
+		return &infrav1.Image{
+			Marketplace: &infrav1.AzureMarketplaceImage{Publisher: "cncf-upstream", Offer: "capi", SKU: "k8s-1dot18dot8-ubuntu-1804", Version: version},
+		}
+	}
+
+	first := newMachineScope("my-machine-1")
+	pinned, err := first.GetPinnedImage(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pinned).To(BeNil())
+	g.Expect(first.PinImage(context.TODO(), image("2020.10.14"))).To(Succeed())
+
+	// the image of the first machine is kept
+	second := newMachineScope("my-machine-2")
+	g.Expect(second.PinImage(context.TODO(), image("2020.10.15"))).To(Succeed())
+	pinned, err = second.GetPinnedImage(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pinned).To(Equal(image("2020.10.14")))
+
+	updated := &infrav1.AzureMachineTemplate{}
+	g.Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "my-template"}, updated)).To(Succeed())
+	g.Expect(updated.Annotations).To(HaveKey(infrav1.ResolvedImageAnnotation))
+
+	// machines not created from a template are not pinned
+	standalone := &MachineScope{client: c, AzureMachine: &infrav1.AzureMachine{ObjectMeta: metav1.ObjectMeta{Name: "my-machine-3", Namespace: "default"}}}
+	g.Expect(standalone.PinImage(context.TODO(), image("2020.10.15"))).To(Succeed())
+	pinned, err = standalone.GetPinnedImage(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pinned).To(BeNil())
+}
+
+func TestGetSSHPublicKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	specKey, additionalKey, secretKey := generateSSHPublicKey(), generateSSHPublicKey(), generateSSHPublicKey()
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ssh-keys", Namespace: "default"},
+		Data: map[string][]byte{
+			"authorized_keys": []byte("# operators\n" + secretKey + "\n\n" + additionalKey + "\n"),
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, secret)
+
+	keys, err := getSSHPublicKeys(context.TODO(), c, "default", base64.StdEncoding.EncodeToString([]byte(specKey+"\n")),
+		[]string{additionalKey}, &corev1.LocalObjectReference{Name: "ssh-keys"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(keys).To(Equal([]string{specKey, additionalKey, secretKey}))
+
+	_, err = getSSHPublicKeys(context.TODO(), c, "default", "", nil, &corev1.LocalObjectReference{Name: "missing"})
+	g.Expect(err).To(HaveOccurred())
+
+	secret.Data["authorized_keys"] = []byte("ssh-rsa not-a-key")
+	g.Expect(c.Update(context.TODO(), secret)).To(Succeed())
+	_, err = getSSHPublicKeys(context.TODO(), c, "default", "", nil, &corev1.LocalObjectReference{Name: "ssh-keys"})
+	g.Expect(err).To(MatchError(ContainSubstring("invalid SSH public key on line 1")))
+}
+
+func TestSetDefaultSSHPublicKey(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewFakeClientWithScheme(scheme)
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"}}
+	m := &MachineScope{
+		client:           c,
+		ClusterDescriber: &ClusterScope{Cluster: cluster},
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-machine", Namespace: "default"},
+		},
+	}
+
+	// the key pair is not generated yet
+	g.Expect(m.SetDefaultSSHPublicKey(context.TODO())).NotTo(Succeed())
+
+	owner := metav1.OwnerReference{APIVersion: infrav1.GroupVersion.String(), Kind: "AzureCluster", Name: "my-cluster"}
+	g.Expect(reconcileSSHKeySecret(context.TODO(), c, "default", "my-cluster", owner)).To(Succeed())
+	secret := &corev1.Secret{}
+	g.Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "my-cluster-ssh"}, secret)).To(Succeed())
+	g.Expect(secret.Type).To(Equal(corev1.SecretTypeSSHAuth))
+	g.Expect(secret.OwnerReferences).To(Equal([]metav1.OwnerReference{owner}))
+	g.Expect(secret.Data).To(HaveKey(corev1.SSHAuthPrivateKey))
+
+	// the key pair is kept once generated
+	g.Expect(reconcileSSHKeySecret(context.TODO(), c, "default", "my-cluster", owner)).To(Succeed())
+	kept := &corev1.Secret{}
+	g.Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "my-cluster-ssh"}, kept)).To(Succeed())
+	g.Expect(kept.Data).To(Equal(secret.Data))
+
+	g.Expect(m.SetDefaultSSHPublicKey(context.TODO())).To(Succeed())
+	g.Expect(m.AzureMachine.Spec.SSHPublicKey).To(Equal(base64.StdEncoding.EncodeToString(
+		[]byte(strings.TrimSpace(string(secret.Data[SSHPublicKeySecretKey]))))))
+
+	// a key set on the machine is kept
+	key := base64.StdEncoding.EncodeToString([]byte(generateSSHPublicKey()))
+	m.AzureMachine.Spec.SSHPublicKey = key
+	g.Expect(m.SetDefaultSSHPublicKey(context.TODO())).To(Succeed())
+	g.Expect(m.AzureMachine.Spec.SSHPublicKey).To(Equal(key))
+
+	// no key is set on a machine with additional keys or a keys Secret
+	m.AzureMachine.Spec.SSHPublicKey = ""
+	m.AzureMachine.Spec.AdditionalSSHPublicKeys = []string{generateSSHPublicKey()}
+	g.Expect(m.SetDefaultSSHPublicKey(context.TODO())).To(Succeed())
+	g.Expect(m.AzureMachine.Spec.SSHPublicKey).To(BeEmpty())
+
+	m.AzureMachine.Spec.AdditionalSSHPublicKeys = nil
+	m.AzureMachine.Spec.SSHPublicKeysSecretRef = &corev1.LocalObjectReference{Name: "ssh-keys"}
+	g.Expect(m.SetDefaultSSHPublicKey(context.TODO())).To(Succeed())
+	g.Expect(m.AzureMachine.Spec.SSHPublicKey).To(BeEmpty())
+}
+
+func TestGetWindowsAdminPassword(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewFakeClientWithScheme(scheme, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-password", Namespace: "default"},
+		Data:       map[string][]byte{AdminPasswordSecretKey: []byte("Passw0rd!")},
+	})
+	m := &MachineScope{
+		client: c,
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-machine", Namespace: "default"},
+			Spec: infrav1.AzureMachineSpec{
+				WindowsConfiguration: &infrav1.WindowsConfiguration{},
+			},
+		},
+	}
+
+	// a password is generated and stored in a Secret owned by the machine
+	password, err := m.GetWindowsAdminPassword(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(password).NotTo(BeEmpty())
+	secret := &corev1.Secret{}
+	g.Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "my-machine-admin-password"}, secret)).To(Succeed())
+	g.Expect(secret.OwnerReferences).To(HaveLen(1))
+	g.Expect(secret.OwnerReferences[0].Kind).To(Equal("AzureMachine"))
+	g.Expect(secret.OwnerReferences[0].Name).To(Equal("my-machine"))
+
+	// the generated password is kept
+	kept, err := m.GetWindowsAdminPassword(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(kept).To(Equal(password))
+
+	// a referenced Secret is used instead
+	m.AzureMachine.Spec.WindowsConfiguration.AdminPasswordSecretRef = &corev1.LocalObjectReference{Name: "my-password"}
+	password, err = m.GetWindowsAdminPassword(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(password).To(Equal("Passw0rd!"))
+}
+
+func TestSSHAccessExtension(t *testing.T) {
+	g := NewWithT(t)
+
+	key, rotatedKey := generateSSHPublicKey(), generateSSHPublicKey()
+	m := &MachineScope{
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-machine", Namespace: "default"},
+			Spec: infrav1.AzureMachineSpec{
+				OSDisk:       infrav1.OSDisk{OSType: infrav1.LinuxOSType},
+				SSHPublicKey: base64.StdEncoding.EncodeToString([]byte(key)),
+			},
+			Status: infrav1.AzureMachineStatus{
+				SSHPublicKeysHash: azure.GenerateSSHPublicKeysHash([]string{key}),
+			},
+		},
+	}
+	sshAccessSpec := func() *azure.VMExtensionSpec {
+		specs, err := m.VMExtensionSpecs(context.TODO())
+		g.Expect(err).NotTo(HaveOccurred())
+		for i := range specs {
+			if specs[i].Name == azure.SSHAccessExtensionName {
+				return &specs[i]
+			}
+		}
+		return nil
+	}
+
+	// the VM was created with the keys of the spec
+	g.Expect(sshAccessSpec()).To(BeNil())
+
+	// rotated keys are pushed, replacing the prior keys
+	m.AzureMachine.Spec.AdminUsername = "core"
+	m.AzureMachine.Spec.AdditionalSSHPublicKeys = []string{rotatedKey}
+	ext := sshAccessSpec()
+	g.Expect(ext).NotTo(BeNil())
+	g.Expect(ext.ProtectedSettings).To(Equal(map[string]interface{}{
+		"username":          "core",
+		"ssh_key":           strings.Join([]string{key, rotatedKey}, "\n"),
+		"remove_prior_keys": true,
+	}))
+
+	// the extension is kept once installed, even if the keys are reverted
+	m.AzureMachine.Status.VMExtensions = []infrav1.VMExtensionStatus{{Name: azure.SSHAccessExtensionName, ProvisioningState: "Succeeded"}}
+	m.AzureMachine.Spec.AdditionalSSHPublicKeys = nil
+	g.Expect(sshAccessSpec()).NotTo(BeNil())
+}
+
+func generateSSHPublicKey() string {
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	sshKey, _ := ssh.NewPublicKey(publicKey)
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshKey)))
+}
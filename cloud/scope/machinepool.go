@@ -22,6 +22,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/klogr"
 	"k8s.io/utils/pointer"
@@ -159,6 +160,54 @@ func getAzureMachineTemplate(ctx context.Context, c client.Client, name, namespa
 	return m, nil
 }
 
+// AdminUsername returns the name of the administrator account of the machine pool instances.
+func (m *MachinePoolScope) AdminUsername() string {
+	return adminUsername(m.AzureMachinePool.Spec.Template.AdminUsername)
+}
+
+// GetSSHPublicKeys returns the SSH public keys authorized for the administrator account of the machine pool
+// instances.
+func (m *MachinePoolScope) GetSSHPublicKeys(ctx context.Context) ([]string, error) {
+	template := m.AzureMachinePool.Spec.Template
+	return getSSHPublicKeys(ctx, m.client, m.AzureMachinePool.Namespace, template.SSHPublicKey, template.AdditionalSSHPublicKeys, template.SSHPublicKeysSecretRef)
+}
+
+// SetDefaultSSHPublicKey sets the SSH public key of the machine pool instances to the public key generated for the
+// cluster, unless the machine pool has keys already.
+func (m *MachinePoolScope) SetDefaultSSHPublicKey(ctx context.Context) error {
+	template := m.AzureMachinePool.Spec.Template
+	if hasSSHPublicKeys(template.SSHPublicKey, template.AdditionalSSHPublicKeys, template.SSHPublicKeysSecretRef) {
+		return nil
+	}
+	publicKey, err := getClusterSSHPublicKey(ctx, m.client, m.AzureMachinePool.Namespace, m.ClusterName())
+	if err != nil {
+		return err
+	}
+	m.AzureMachinePool.Spec.Template.SSHPublicKey = base64.StdEncoding.EncodeToString([]byte(publicKey))
+	return nil
+}
+
+// GetWindowsAdminPassword returns the password of the administrator account of the Windows machine pool instances.
+func (m *MachinePoolScope) GetWindowsAdminPassword(ctx context.Context) (string, error) {
+	return getWindowsAdminPassword(ctx, m.client, m.AzureMachinePool.Namespace, m.Name(),
+		*metav1.NewControllerRef(m.AzureMachinePool, infrav1exp.GroupVersion.WithKind("AzureMachinePool")), m.AzureMachinePool.Spec.Template.WindowsConfiguration)
+}
+
+// VMExtensionSpecs returns the specs of the VM extensions of the machine pool template.
+func (m *MachinePoolScope) VMExtensionSpecs(ctx context.Context) ([]azure.VMExtensionSpec, error) {
+	return getVMExtensionSpecs(ctx, m.client, m.AzureMachinePool.Namespace, m.Name(), m.AzureMachinePool.Spec.Template.VMExtensions)
+}
+
+// BootDiagnostics returns the boot diagnostics of the machine pool instances.
+func (m *MachinePoolScope) BootDiagnostics() *infrav1.BootDiagnostics {
+	return m.AzureMachinePool.Spec.Template.BootDiagnostics
+}
+
+// WindowsRemoteAccess returns how the Windows machine pool instances are reached remotely.
+func (m *MachinePoolScope) WindowsRemoteAccess() infrav1.WindowsRemoteAccess {
+	return windowsRemoteAccess(m.AzureMachinePool.Spec.Template.WindowsConfiguration)
+}
+
 // GetBootstrapData returns the bootstrap data from the secret in the Machine's bootstrap.dataSecretName.
 func (m *MachinePoolScope) GetBootstrapData(ctx context.Context) (string, error) {
 	dataSecretName := m.MachinePool.Spec.Template.Spec.Bootstrap.DataSecretName
@@ -22,6 +22,7 @@ import (
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/klogr"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
@@ -110,7 +111,30 @@ func (s *ManagedControlPlaneScope) Authorizer() autorest.Authorizer {
 	return s.AzureClients.Authorizer
 }
 
+// CloudEnvironment returns the name of the Azure cloud environment.
+func (s *ManagedControlPlaneScope) CloudEnvironment() string {
+	return s.AzureClients.EnvironmentName
+}
+
 // PatchObject persists the cluster configuration and status.
 func (s *ManagedControlPlaneScope) PatchObject(ctx context.Context) error {
 	return s.patchHelper.Patch(ctx, s.PatchTarget)
 }
+
+// SetDefaultSSHPublicKey generates the SSH key pair of the cluster, stored in a Secret owned by the control plane, and
+// sets the SSH public key of the control plane to its public key, unless the control plane has a key already.
+func (s *ManagedControlPlaneScope) SetDefaultSSHPublicKey(ctx context.Context) error {
+	if s.ControlPlane.Spec.SSHPublicKey != "" {
+		return nil
+	}
+	owner := *metav1.NewControllerRef(s.ControlPlane, infrav1exp.GroupVersion.WithKind("AzureManagedControlPlane"))
+	if err := reconcileSSHKeySecret(ctx, s.Client, s.Cluster.Namespace, s.Cluster.Name, owner); err != nil {
+		return err
+	}
+	publicKey, err := getClusterSSHPublicKey(ctx, s.Client, s.Cluster.Namespace, s.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	s.ControlPlane.Spec.SSHPublicKey = publicKey
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package availabilitysets
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+)
+
+// Reconcile creates or updates an availability set.
+func (s *Service) Reconcile(ctx context.Context) error {
+	asSpec := s.Scope.AvailabilitySetSpec()
+	if asSpec == nil {
+		return nil
+	}
+
+	s.Scope.V(2).Info("creating availability set", "availability set", asSpec.Name)
+	// Managed disks require the Aligned SKU.
+	_, err := s.Client.CreateOrUpdate(ctx, s.Scope.ResourceGroup(), asSpec.Name, compute.AvailabilitySet{
+		Location: to.StringPtr(s.Scope.Location()),
+		Sku: &compute.Sku{
+			Name: to.StringPtr("Aligned"),
+		},
+		AvailabilitySetProperties: &compute.AvailabilitySetProperties{
+			PlatformFaultDomainCount:  to.Int32Ptr(asSpec.FaultDomainCount),
+			PlatformUpdateDomainCount: to.Int32Ptr(asSpec.UpdateDomainCount),
+		},
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.Scope.ClusterName(),
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(asSpec.Name),
+			Additional:  s.Scope.AdditionalTags(),
+		})),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create availability set %s in resource group %s", asSpec.Name, s.Scope.ResourceGroup())
+	}
+
+	s.Scope.V(2).Info("successfully created availability set", "availability set", asSpec.Name)
+	return nil
+}
+
+// Delete deletes the availability set once it no longer contains any virtual machine.
+func (s *Service) Delete(ctx context.Context) error {
+	asSpec := s.Scope.AvailabilitySetSpec()
+	if asSpec == nil {
+		return nil
+	}
+
+	as, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), asSpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get availability set %s in resource group %s", asSpec.Name, s.Scope.ResourceGroup())
+	}
+
+	// only delete when the availability set does not have any VMs
+	if as.AvailabilitySetProperties != nil && as.VirtualMachines != nil && len(*as.VirtualMachines) > 0 {
+		s.Scope.V(2).Info("skipping deletion of availability set with virtual machines", "availability set", asSpec.Name)
+		return nil
+	}
+
+	s.Scope.V(2).Info("deleting availability set", "availability set", asSpec.Name)
+	err = s.Client.Delete(ctx, s.Scope.ResourceGroup(), asSpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete availability set %s in resource group %s", asSpec.Name, s.Scope.ResourceGroup())
+	}
+
+	s.Scope.V(2).Info("successfully deleted availability set", "availability set", asSpec.Name)
+	return nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package availabilitysets
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog/klogr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/availabilitysets/mock_availabilitysets"
+)
+
+func TestReconcileAvailabilitySets(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder)
+	}{
+		{
+			name:          "no availability set",
+			expectedError: "",
+			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder) {
+				s.AvailabilitySetSpec().Return(nil)
+			},
+		},
+		{
+			name:          "create availability set",
+			expectedError: "",
+			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.AvailabilitySetSpec().Return(&azure.AvailabilitySetSpec{
+					Name:              "my-cluster_control-plane-as",
+					FaultDomainCount:  3,
+					UpdateDomainCount: 5,
+				})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Location().Return("local")
+				s.ClusterName().Return("my-cluster")
+				s.AdditionalTags().Return(infrav1.Tags{})
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-cluster_control-plane-as", gomock.AssignableToTypeOf(compute.AvailabilitySet{})).
+					Do(func(_ context.Context, _, _ string, as compute.AvailabilitySet) {
+						g := NewWithT(t)
+						g.Expect(to.String(as.Sku.Name)).To(Equal("Aligned"))
+						g.Expect(to.Int32(as.PlatformFaultDomainCount)).To(Equal(int32(3)))
+						g.Expect(to.Int32(as.PlatformUpdateDomainCount)).To(Equal(int32(5)))
+					})
+			},
+		},
+		{
+			name:          "fail to create availability set",
+			expectedError: "failed to create availability set my-cluster_control-plane-as in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.AvailabilitySetSpec().Return(&azure.AvailabilitySetSpec{
+					Name:              "my-cluster_control-plane-as",
+					FaultDomainCount:  2,
+					UpdateDomainCount: 5,
+				})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Location().Return("local")
+				s.ClusterName().Return("my-cluster")
+				s.AdditionalTags().Return(infrav1.Tags{})
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-cluster_control-plane-as", gomock.AssignableToTypeOf(compute.AvailabilitySet{})).
+					Return(compute.AvailabilitySet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_availabilitysets.NewMockAvailabilitySetScope(mockCtrl)
+			clientMock := mock_availabilitysets.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				Client: clientMock,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteAvailabilitySets(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder)
+	}{
+		{
+			name:          "delete empty availability set",
+			expectedError: "",
+			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.AvailabilitySetSpec().Return(&azure.AvailabilitySetSpec{Name: "my-cluster_md-0-as"})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Get(context.TODO(), "my-rg", "my-cluster_md-0-as").Return(compute.AvailabilitySet{
+					AvailabilitySetProperties: &compute.AvailabilitySetProperties{},
+				}, nil)
+				m.Delete(context.TODO(), "my-rg", "my-cluster_md-0-as")
+			},
+		},
+		{
+			name:          "do not delete availability set with virtual machines",
+			expectedError: "",
+			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.AvailabilitySetSpec().Return(&azure.AvailabilitySetSpec{Name: "my-cluster_md-0-as"})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Get(context.TODO(), "my-rg", "my-cluster_md-0-as").Return(compute.AvailabilitySet{
+					AvailabilitySetProperties: &compute.AvailabilitySetProperties{
+						VirtualMachines: &[]compute.SubResource{{ID: to.StringPtr("vm-id")}},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:          "availability set already deleted",
+			expectedError: "",
+			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.AvailabilitySetSpec().Return(&azure.AvailabilitySetSpec{Name: "my-cluster_md-0-as"})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Get(context.TODO(), "my-rg", "my-cluster_md-0-as").Return(compute.AvailabilitySet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+		{
+			name:          "fail to delete availability set",
+			expectedError: "failed to delete availability set my-cluster_md-0-as in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, m *mock_availabilitysets.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.AvailabilitySetSpec().Return(&azure.AvailabilitySetSpec{Name: "my-cluster_md-0-as"})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Get(context.TODO(), "my-rg", "my-cluster_md-0-as").Return(compute.AvailabilitySet{}, nil)
+				m.Delete(context.TODO(), "my-rg", "my-cluster_md-0-as").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_availabilitysets.NewMockAvailabilitySetScope(mockCtrl)
+			clientMock := mock_availabilitysets.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				Client: clientMock,
+			}
+
+			err := s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
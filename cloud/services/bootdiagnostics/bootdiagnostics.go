@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootdiagnostics
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+)
+
+// maxSerialConsoleLogSize is the number of bytes kept from the end of a serial console log.
+const maxSerialConsoleLogSize = 64 * 1024
+
+// StorageURI returns the URI of the blob endpoint of the storage account storing the boot diagnostics, or an empty
+// string if boot diagnostics are disabled.
+func (s *Service) StorageURI(ctx context.Context) (string, error) {
+	account := s.storageAccount()
+	if account == nil {
+		return "", nil
+	}
+
+	s.Scope.V(2).Info("getting boot diagnostics storage account", "storage account", account.Name)
+	sa, err := s.Client.GetStorageAccount(ctx, account.ResourceGroup, account.Name)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get boot diagnostics storage account %s", account.Name)
+	}
+	if sa.AccountProperties == nil || sa.PrimaryEndpoints == nil || to.String(sa.PrimaryEndpoints.Blob) == "" {
+		return "", errors.Errorf("boot diagnostics storage account %s has no blob endpoint", account.Name)
+	}
+	return to.String(sa.PrimaryEndpoints.Blob), nil
+}
+
+// GetSerialConsoleLog returns the end of the serial console log of a virtual machine, or nil if boot diagnostics
+// are disabled.
+func (s *Service) GetSerialConsoleLog(ctx context.Context, vmName string) ([]byte, error) {
+	account := s.storageAccount()
+	if account == nil {
+		return nil, nil
+	}
+
+	view, err := s.Client.GetVMInstanceView(ctx, s.Scope.ResourceGroup(), vmName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get instance view of VM %s", vmName)
+	}
+	if view.BootDiagnostics == nil || to.String(view.BootDiagnostics.SerialConsoleLogBlobURI) == "" {
+		return nil, errors.Errorf("VM %s has no serial console log", vmName)
+	}
+
+	keys, err := s.Client.ListStorageAccountKeys(ctx, account.ResourceGroup, account.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list keys of boot diagnostics storage account %s", account.Name)
+	}
+	if keys.Keys == nil || len(*keys.Keys) == 0 {
+		return nil, errors.Errorf("boot diagnostics storage account %s has no keys", account.Name)
+	}
+
+	s.Scope.V(2).Info("getting serial console log", "vm", vmName)
+	log, err := s.Client.GetBlob(ctx, to.String(view.BootDiagnostics.SerialConsoleLogBlobURI), account.Name, to.String((*keys.Keys)[0].Value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get serial console log of VM %s", vmName)
+	}
+
+	// the serial console log is a page blob, padded with NUL bytes
+	log = bytes.TrimRight(log, "\x00")
+	if len(log) > maxSerialConsoleLogSize {
+		log = log[len(log)-maxSerialConsoleLogSize:]
+	}
+	return log, nil
+}
+
+// storageAccount returns the storage account storing the boot diagnostics, with its resource group defaulted to
+// the resource group of the cluster, or nil if boot diagnostics are disabled.
+func (s *Service) storageAccount() *infrav1.BootDiagnosticsStorageAccount {
+	bootDiagnostics := s.Scope.BootDiagnostics()
+	if bootDiagnostics == nil {
+		return nil
+	}
+	account := bootDiagnostics.StorageAccount.DeepCopy()
+	if account.ResourceGroup == "" {
+		account.ResourceGroup = s.Scope.ResourceGroup()
+	}
+	return account
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootdiagnostics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/storage/mgmt/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog/klogr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/bootdiagnostics/mock_bootdiagnostics"
+)
+
+var bootDiagnostics = &infrav1.BootDiagnostics{
+	StorageAccount: infrav1.BootDiagnosticsStorageAccount{
+		Name: "mydiagnostics",
+	},
+}
+
+func TestStorageURI(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedURI   string
+		expectedError string
+		expect        func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder)
+	}{
+		{
+			name:          "boot diagnostics disabled",
+			expectedURI:   "",
+			expectedError: "",
+			expect: func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder) {
+				s.BootDiagnostics().Return(nil)
+			},
+		},
+		{
+			name:          "storage account in the resource group of the cluster",
+			expectedURI:   "https://mydiagnostics.blob.local.azurestack.external/",
+			expectedError: "",
+			expect: func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.BootDiagnostics().Return(bootDiagnostics)
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.GetStorageAccount(context.TODO(), "my-rg", "mydiagnostics").Return(storage.Account{
+					AccountProperties: &storage.AccountProperties{
+						PrimaryEndpoints: &storage.Endpoints{
+							Blob: to.StringPtr("https://mydiagnostics.blob.local.azurestack.external/"),
+						},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:          "storage account in another resource group",
+			expectedURI:   "https://mydiagnostics.blob.local.azurestack.external/",
+			expectedError: "",
+			expect: func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.BootDiagnostics().Return(&infrav1.BootDiagnostics{
+					StorageAccount: infrav1.BootDiagnosticsStorageAccount{
+						Name:          "mydiagnostics",
+						ResourceGroup: "diagnostics-rg",
+					},
+				})
+				m.GetStorageAccount(context.TODO(), "diagnostics-rg", "mydiagnostics").Return(storage.Account{
+					AccountProperties: &storage.AccountProperties{
+						PrimaryEndpoints: &storage.Endpoints{
+							Blob: to.StringPtr("https://mydiagnostics.blob.local.azurestack.external/"),
+						},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:          "storage account not found",
+			expectedURI:   "",
+			expectedError: "failed to get boot diagnostics storage account mydiagnostics: #: Not found: StatusCode=404",
+			expect: func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.BootDiagnostics().Return(bootDiagnostics)
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.GetStorageAccount(context.TODO(), "my-rg", "mydiagnostics").
+					Return(storage.Account{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_bootdiagnostics.NewMockBootDiagnosticsScope(mockCtrl)
+			clientMock := mock_bootdiagnostics.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				Client: clientMock,
+			}
+
+			uri, err := s.StorageURI(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(uri).To(Equal(tc.expectedURI))
+		})
+	}
+}
+
+func TestGetSerialConsoleLog(t *testing.T) {
+	longLog := bytes.Repeat([]byte("a"), maxSerialConsoleLogSize)
+
+	testcases := []struct {
+		name          string
+		expectedLog   []byte
+		expectedError string
+		expect        func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder)
+	}{
+		{
+			name:          "boot diagnostics disabled",
+			expectedLog:   nil,
+			expectedError: "",
+			expect: func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder) {
+				s.BootDiagnostics().Return(nil)
+			},
+		},
+		{
+			name:          "get serial console log",
+			expectedLog:   []byte("kubeadm join failed\n"),
+			expectedError: "",
+			expect: func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.BootDiagnostics().Return(bootDiagnostics)
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.GetVMInstanceView(context.TODO(), "my-rg", "my-vm").Return(compute.VirtualMachineInstanceView{
+					BootDiagnostics: &compute.BootDiagnosticsInstanceView{
+						SerialConsoleLogBlobURI: to.StringPtr("https://mydiagnostics.blob.local.azurestack.external/bootdiagnostics-myvm/my-vm.serialconsole.log"),
+					},
+				}, nil)
+				m.ListStorageAccountKeys(context.TODO(), "my-rg", "mydiagnostics").Return(storage.AccountListKeysResult{
+					Keys: &[]storage.AccountKey{{Value: to.StringPtr("a2V5")}},
+				}, nil)
+				m.GetBlob(context.TODO(), "https://mydiagnostics.blob.local.azurestack.external/bootdiagnostics-myvm/my-vm.serialconsole.log", "mydiagnostics", "a2V5").
+					Return([]byte("kubeadm join failed\n\x00\x00\x00"), nil)
+			},
+		},
+		{
+			name:          "truncate serial console log",
+			expectedLog:   longLog,
+			expectedError: "",
+			expect: func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.BootDiagnostics().Return(bootDiagnostics)
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.GetVMInstanceView(context.TODO(), "my-rg", "my-vm").Return(compute.VirtualMachineInstanceView{
+					BootDiagnostics: &compute.BootDiagnosticsInstanceView{
+						SerialConsoleLogBlobURI: to.StringPtr("https://mydiagnostics.blob.local.azurestack.external/bootdiagnostics-myvm/my-vm.serialconsole.log"),
+					},
+				}, nil)
+				m.ListStorageAccountKeys(context.TODO(), "my-rg", "mydiagnostics").Return(storage.AccountListKeysResult{
+					Keys: &[]storage.AccountKey{{Value: to.StringPtr("a2V5")}},
+				}, nil)
+				m.GetBlob(context.TODO(), gomock.Any(), "mydiagnostics", "a2V5").Return(append([]byte("bbb"), longLog...), nil)
+			},
+		},
+		{
+			name:          "no serial console log",
+			expectedLog:   nil,
+			expectedError: "VM my-vm has no serial console log",
+			expect: func(s *mock_bootdiagnostics.MockBootDiagnosticsScopeMockRecorder, m *mock_bootdiagnostics.MockClientMockRecorder) {
+				s.BootDiagnostics().Return(bootDiagnostics)
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.GetVMInstanceView(context.TODO(), "my-rg", "my-vm").Return(compute.VirtualMachineInstanceView{}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_bootdiagnostics.NewMockBootDiagnosticsScope(mockCtrl)
+			clientMock := mock_bootdiagnostics.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				Client: clientMock,
+			}
+
+			log, err := s.GetSerialConsoleLog(context.TODO(), "my-vm")
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(log).To(Equal(tc.expectedLog))
+		})
+	}
+}
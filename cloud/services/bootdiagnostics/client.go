@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootdiagnostics
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/storage/mgmt/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+)
+
+const (
+	// blobServiceVersion is the version of the blob service API used to read boot diagnostics blobs.
+	blobServiceVersion = "2017-04-17"
+	// maxBlobSize is the maximum number of bytes read from a boot diagnostics blob.
+	maxBlobSize = 8 * 1024 * 1024
+)
+
+// Client wraps go-sdk
+type Client interface {
+	GetStorageAccount(context.Context, string, string) (storage.Account, error)
+	ListStorageAccountKeys(context.Context, string, string) (storage.AccountListKeysResult, error)
+	GetVMInstanceView(context.Context, string, string) (compute.VirtualMachineInstanceView, error)
+	GetBlob(context.Context, string, string, string) ([]byte, error)
+}
+
+// AzureClient contains the Azure go-sdk Client
+type AzureClient struct {
+	accounts        storage.AccountsClient
+	virtualmachines compute.VirtualMachinesClient
+	blobs           *http.Client
+}
+
+var _ Client = &AzureClient{}
+
+// NewClient creates a new boot diagnostics client from subscription ID.
+func NewClient(auth azure.Authorizer) *AzureClient {
+	return &AzureClient{
+		accounts:        newAccountsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		virtualmachines: newVirtualMachinesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		blobs:           &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// newAccountsClient creates a new storage accounts client from subscription ID.
+func newAccountsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) storage.AccountsClient {
+	accountsClient := storage.NewAccountsClientWithBaseURI(baseURI, subscriptionID)
+	accountsClient.Authorizer = authorizer
+	accountsClient.AddToUserAgent(azure.UserAgent())
+	return accountsClient
+}
+
+// newVirtualMachinesClient creates a new VM client from subscription ID.
+func newVirtualMachinesClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachinesClient {
+	vmClient := compute.NewVirtualMachinesClientWithBaseURI(baseURI, subscriptionID)
+	vmClient.Authorizer = authorizer
+	vmClient.AddToUserAgent(azure.UserAgent())
+	return vmClient
+}
+
+// GetStorageAccount gets the properties of the specified storage account.
+func (ac *AzureClient) GetStorageAccount(ctx context.Context, resourceGroupName, accountName string) (storage.Account, error) {
+	return ac.accounts.GetProperties(ctx, resourceGroupName, accountName)
+}
+
+// ListStorageAccountKeys lists the access keys of the specified storage account.
+func (ac *AzureClient) ListStorageAccountKeys(ctx context.Context, resourceGroupName, accountName string) (storage.AccountListKeysResult, error) {
+	return ac.accounts.ListKeys(ctx, resourceGroupName, accountName)
+}
+
+// GetVMInstanceView retrieves the run-time state of a virtual machine, including the URIs of its boot diagnostics.
+func (ac *AzureClient) GetVMInstanceView(ctx context.Context, resourceGroupName, vmName string) (compute.VirtualMachineInstanceView, error) {
+	return ac.virtualmachines.InstanceView(ctx, resourceGroupName, vmName)
+}
+
+// GetBlob reads a blob authorized with the shared key of its storage account. At most the first 8 MiB of the
+// blob are returned.
+func (ac *AzureClient) GetBlob(ctx context.Context, blobURI, accountName, accountKey string) ([]byte, error) {
+	u, err := url.Parse(blobURI)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse blob URI %s", blobURI)
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", blobServiceVersion)
+	signature, err := sharedKeySignature(req, accountName, accountKey)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", accountName, signature))
+
+	resp, err := ac.blobs.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get blob %s", blobURI)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to get blob %s: %s", blobURI, resp.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxBlobSize))
+}
+
+// sharedKeySignature signs a GET request of the blob service with the shared key of a storage account.
+// See https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key.
+func sharedKeySignature(req *http.Request, accountName, accountKey string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode storage account key")
+	}
+
+	var b bytes.Buffer
+	b.WriteString(req.Method + "\n")
+	// Content-Encoding, Content-Language, Content-Length, Content-MD5, Content-Type, Date, If-Modified-Since,
+	// If-Match, If-None-Match, If-Unmodified-Since and Range are not set.
+	b.WriteString(strings.Repeat("\n", 11))
+	fmt.Fprintf(&b, "x-ms-date:%s\nx-ms-version:%s\n", req.Header.Get("x-ms-date"), req.Header.Get("x-ms-version"))
+	fmt.Fprintf(&b, "/%s%s", accountName, req.URL.EscapedPath())
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b.Bytes())
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -26,6 +26,8 @@ import (
 
 // Client wraps go-sdk
 type Client interface {
+	Get(context.Context, string, string) (compute.Disk, error)
+	Update(context.Context, string, string, compute.DiskUpdate) error
 	Delete(context.Context, string, string) error
 }
 
@@ -50,6 +52,25 @@ func newDisksClient(subscriptionID string, baseURI string, authorizer autorest.A
 	return disksClient
 }
 
+// Get gets information about the specified disk.
+func (ac *AzureClient) Get(ctx context.Context, resourceGroupName, name string) (compute.Disk, error) {
+	return ac.disks.Get(ctx, resourceGroupName, name)
+}
+
+// Update updates the specified disk, e.g. its tags.
+func (ac *AzureClient) Update(ctx context.Context, resourceGroupName, name string, disk compute.DiskUpdate) error {
+	future, err := ac.disks.Update(ctx, resourceGroupName, name, disk)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.disks.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.disks)
+	return err
+}
+
 // Delete removes the disk client
 func (ac *AzureClient) Delete(ctx context.Context, resourceGroupName, name string) error {
 	future, err := ac.disks.Delete(ctx, resourceGroupName, name)
@@ -19,24 +19,35 @@ package disks
 import (
 	"context"
 
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
 
 	"github.com/pkg/errors"
 )
 
-// Reconcile on disk is currently no-op. OS disks should only be deleted and will create with the VM automatically.
+// Reconcile on disk is currently no-op. OS and data disks are created with the VM automatically.
 func (s *Service) Reconcile(ctx context.Context) error {
 	return nil
 }
 
-// Delete deletes the disk associated with a VM.
+// Delete deletes the disks associated with a VM, and tags the data disks retained on delete instead.
 func (s *Service) Delete(ctx context.Context) error {
 	for _, diskSpec := range s.Scope.DiskSpecs() {
+		if diskSpec.RetainOnDelete {
+			if err := s.retain(ctx, diskSpec); err != nil {
+				return err
+			}
+			continue
+		}
+
 		s.Scope.V(2).Info("deleting disk", "disk", diskSpec.Name)
 		err := s.Client.Delete(ctx, s.Scope.ResourceGroup(), diskSpec.Name)
 		if err != nil && azure.ResourceNotFound(err) {
 			// already deleted
-			return nil
+			continue
 		}
 		if err != nil {
 			return errors.Wrapf(err, "failed to delete disk %s in resource group %s", diskSpec.Name, s.Scope.ResourceGroup())
@@ -46,3 +57,30 @@ func (s *Service) Delete(ctx context.Context) error {
 	}
 	return nil
 }
+
+// retain tags a data disk with the cluster, machine and name suffix it belongs to, so that it can be
+// attached again to a machine of the same name.
+func (s *Service) retain(ctx context.Context, diskSpec azure.DiskSpec) error {
+	s.Scope.V(2).Info("retaining disk", "disk", diskSpec.Name)
+	err := s.Client.Update(ctx, s.Scope.ResourceGroup(), diskSpec.Name, compute.DiskUpdate{
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.Scope.ClusterName(),
+			Lifecycle:   infrav1.ResourceLifecycleShared,
+			Name:        to.StringPtr(diskSpec.Name),
+			Additional: infrav1.Tags{
+				infrav1.NameAzureProviderMachine:  diskSpec.MachineName,
+				infrav1.NameAzureProviderDataDisk: diskSpec.NameSuffix,
+			},
+		})),
+	})
+	if err != nil && azure.ResourceNotFound(err) {
+		// never created or already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to tag retained disk %s in resource group %s", diskSpec.Name, s.Scope.ResourceGroup())
+	}
+
+	s.Scope.V(2).Info("successfully retained disk", "disk", diskSpec.Name)
+	return nil
+}
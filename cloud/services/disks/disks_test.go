@@ -21,7 +21,9 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/disks/mock_disks"
@@ -69,6 +71,71 @@ func TestDeleteDisk(t *testing.T) {
 				m.Delete(context.TODO(), "my-rg", "my-disk-1").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found"))
 			},
 		},
+		{
+			name:          "delete remaining disks after a disk already deleted",
+			expectedError: "",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, m *mock_disks.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.DiskSpecs().Return([]azure.DiskSpec{
+					{
+						Name: "my-disk-1",
+					},
+					{
+						Name: "my-vm_data",
+					},
+				})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Delete(context.TODO(), "my-rg", "my-disk-1").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found"))
+				m.Delete(context.TODO(), "my-rg", "my-vm_data")
+			},
+		},
+		{
+			name:          "tag a data disk retained on delete",
+			expectedError: "",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, m *mock_disks.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.DiskSpecs().Return([]azure.DiskSpec{
+					{
+						Name: "my-vm_osdisk",
+					},
+					{
+						Name:           "my-vm_data",
+						MachineName:    "my-vm",
+						NameSuffix:     "data",
+						RetainOnDelete: true,
+					},
+				})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				m.Delete(context.TODO(), "my-rg", "my-vm_osdisk")
+				m.Update(context.TODO(), "my-rg", "my-vm_data", compute.DiskUpdate{
+					Tags: map[string]*string{
+						"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("shared"),
+						"sigs.k8s.io_cluster-api-provider-azure_machine":            to.StringPtr("my-vm"),
+						"sigs.k8s.io_cluster-api-provider-azure_data-disk":          to.StringPtr("data"),
+						"Name": to.StringPtr("my-vm_data"),
+					},
+				})
+			},
+		},
+		{
+			name:          "error while trying to tag a retained disk",
+			expectedError: "failed to tag retained disk my-vm_data in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, m *mock_disks.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.DiskSpecs().Return([]azure.DiskSpec{
+					{
+						Name:           "my-vm_data",
+						MachineName:    "my-vm",
+						NameSuffix:     "data",
+						RetainOnDelete: true,
+					},
+				})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				m.Update(context.TODO(), "my-rg", "my-vm_data", gomock.Any()).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
 		{
 			name:          "error while trying to delete the disk",
 			expectedError: "failed to delete disk my-disk-1 in resource group my-rg: #: Internal Server Error: StatusCode=500",
@@ -188,6 +188,20 @@ func (mr *MockDiskScopeMockRecorder) Authorizer() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorizer", reflect.TypeOf((*MockDiskScope)(nil).Authorizer))
 }
 
+// CloudEnvironment mocks base method.
+func (m *MockDiskScope) CloudEnvironment() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudEnvironment")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CloudEnvironment indicates an expected call of CloudEnvironment.
+func (mr *MockDiskScopeMockRecorder) CloudEnvironment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockDiskScope)(nil).CloudEnvironment))
+}
+
 // ResourceGroup mocks base method.
 func (m *MockDiskScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -314,6 +328,34 @@ func (mr *MockDiskScopeMockRecorder) RouteTable() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RouteTable", reflect.TypeOf((*MockDiskScope)(nil).RouteTable))
 }
 
+// PrivateDNSZone mocks base method.
+func (m *MockDiskScope) PrivateDNSZone() *v1alpha3.PrivateDNSZone {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrivateDNSZone")
+	ret0, _ := ret[0].(*v1alpha3.PrivateDNSZone)
+	return ret0
+}
+
+// PrivateDNSZone indicates an expected call of PrivateDNSZone.
+func (mr *MockDiskScopeMockRecorder) PrivateDNSZone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrivateDNSZone", reflect.TypeOf((*MockDiskScope)(nil).PrivateDNSZone))
+}
+
+// AvailabilitySets mocks base method.
+func (m *MockDiskScope) AvailabilitySets() v1alpha3.AvailabilitySetsSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailabilitySets")
+	ret0, _ := ret[0].(v1alpha3.AvailabilitySetsSpec)
+	return ret0
+}
+
+// AvailabilitySets indicates an expected call of AvailabilitySets.
+func (mr *MockDiskScopeMockRecorder) AvailabilitySets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailabilitySets", reflect.TypeOf((*MockDiskScope)(nil).AvailabilitySets))
+}
+
 // DiskSpecs mocks base method.
 func (m *MockDiskScope) DiskSpecs() []azure.DiskSpec {
 	m.ctrl.T.Helper()
@@ -188,6 +188,20 @@ func (mr *MockGroupScopeMockRecorder) Authorizer() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorizer", reflect.TypeOf((*MockGroupScope)(nil).Authorizer))
 }
 
+// CloudEnvironment mocks base method.
+func (m *MockGroupScope) CloudEnvironment() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudEnvironment")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CloudEnvironment indicates an expected call of CloudEnvironment.
+func (mr *MockGroupScopeMockRecorder) CloudEnvironment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockGroupScope)(nil).CloudEnvironment))
+}
+
 // ResourceGroup mocks base method.
 func (m *MockGroupScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -313,3 +327,31 @@ func (mr *MockGroupScopeMockRecorder) RouteTable() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RouteTable", reflect.TypeOf((*MockGroupScope)(nil).RouteTable))
 }
+
+// PrivateDNSZone mocks base method.
+func (m *MockGroupScope) PrivateDNSZone() *v1alpha3.PrivateDNSZone {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrivateDNSZone")
+	ret0, _ := ret[0].(*v1alpha3.PrivateDNSZone)
+	return ret0
+}
+
+// PrivateDNSZone indicates an expected call of PrivateDNSZone.
+func (mr *MockGroupScopeMockRecorder) PrivateDNSZone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrivateDNSZone", reflect.TypeOf((*MockGroupScope)(nil).PrivateDNSZone))
+}
+
+// AvailabilitySets mocks base method.
+func (m *MockGroupScope) AvailabilitySets() v1alpha3.AvailabilitySetsSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailabilitySets")
+	ret0, _ := ret[0].(v1alpha3.AvailabilitySetsSpec)
+	return ret0
+}
+
+// AvailabilitySets indicates an expected call of AvailabilitySets.
+func (mr *MockGroupScopeMockRecorder) AvailabilitySets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailabilitySets", reflect.TypeOf((*MockGroupScope)(nil).AvailabilitySets))
+}
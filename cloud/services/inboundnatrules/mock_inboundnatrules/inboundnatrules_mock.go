@@ -189,6 +189,20 @@ func (mr *MockInboundNatScopeMockRecorder) Authorizer() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorizer", reflect.TypeOf((*MockInboundNatScope)(nil).Authorizer))
 }
 
+// CloudEnvironment mocks base method.
+func (m *MockInboundNatScope) CloudEnvironment() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudEnvironment")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CloudEnvironment indicates an expected call of CloudEnvironment.
+func (mr *MockInboundNatScopeMockRecorder) CloudEnvironment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockInboundNatScope)(nil).CloudEnvironment))
+}
+
 // ResourceGroup mocks base method.
 func (m *MockInboundNatScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -315,6 +329,34 @@ func (mr *MockInboundNatScopeMockRecorder) RouteTable() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RouteTable", reflect.TypeOf((*MockInboundNatScope)(nil).RouteTable))
 }
 
+// PrivateDNSZone mocks base method.
+func (m *MockInboundNatScope) PrivateDNSZone() *v1alpha3.PrivateDNSZone {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrivateDNSZone")
+	ret0, _ := ret[0].(*v1alpha3.PrivateDNSZone)
+	return ret0
+}
+
+// PrivateDNSZone indicates an expected call of PrivateDNSZone.
+func (mr *MockInboundNatScopeMockRecorder) PrivateDNSZone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrivateDNSZone", reflect.TypeOf((*MockInboundNatScope)(nil).PrivateDNSZone))
+}
+
+// AvailabilitySets mocks base method.
+func (m *MockInboundNatScope) AvailabilitySets() v1alpha3.AvailabilitySetsSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailabilitySets")
+	ret0, _ := ret[0].(v1alpha3.AvailabilitySetsSpec)
+	return ret0
+}
+
+// AvailabilitySets indicates an expected call of AvailabilitySets.
+func (mr *MockInboundNatScopeMockRecorder) AvailabilitySets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailabilitySets", reflect.TypeOf((*MockInboundNatScope)(nil).AvailabilitySets))
+}
+
 // InboundNatSpecs mocks base method.
 func (m *MockInboundNatScope) InboundNatSpecs() []azure.InboundNatSpec {
 	m.ctrl.T.Helper()
@@ -94,6 +94,20 @@ func (mr *MockLBScopeMockRecorder) Authorizer() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorizer", reflect.TypeOf((*MockLBScope)(nil).Authorizer))
 }
 
+// CloudEnvironment mocks base method.
+func (m *MockLBScope) CloudEnvironment() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudEnvironment")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CloudEnvironment indicates an expected call of CloudEnvironment.
+func (mr *MockLBScopeMockRecorder) CloudEnvironment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockLBScope)(nil).CloudEnvironment))
+}
+
 // ResourceGroup mocks base method.
 func (m *MockLBScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -220,6 +234,34 @@ func (mr *MockLBScopeMockRecorder) RouteTable() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RouteTable", reflect.TypeOf((*MockLBScope)(nil).RouteTable))
 }
 
+// PrivateDNSZone mocks base method.
+func (m *MockLBScope) PrivateDNSZone() *v1alpha3.PrivateDNSZone {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrivateDNSZone")
+	ret0, _ := ret[0].(*v1alpha3.PrivateDNSZone)
+	return ret0
+}
+
+// PrivateDNSZone indicates an expected call of PrivateDNSZone.
+func (mr *MockLBScopeMockRecorder) PrivateDNSZone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrivateDNSZone", reflect.TypeOf((*MockLBScope)(nil).PrivateDNSZone))
+}
+
+// AvailabilitySets mocks base method.
+func (m *MockLBScope) AvailabilitySets() v1alpha3.AvailabilitySetsSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailabilitySets")
+	ret0, _ := ret[0].(v1alpha3.AvailabilitySetsSpec)
+	return ret0
+}
+
+// AvailabilitySets indicates an expected call of AvailabilitySets.
+func (mr *MockLBScopeMockRecorder) AvailabilitySets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailabilitySets", reflect.TypeOf((*MockLBScope)(nil).AvailabilitySets))
+}
+
 // Info mocks base method.
 func (m *MockLBScope) Info(msg string, keysAndValues ...interface{}) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package marketplaceterms
+
+import (
+	"context"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+)
+
+// Accept accepts the Marketplace terms of the plan of an image in the subscription, unless they are already accepted.
+func Accept(ctx context.Context, client Client, plan *infrav1.ImagePlan) error {
+	terms, err := client.Get(ctx, plan.Publisher, plan.Product, plan.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get Marketplace terms of plan %s/%s/%s", plan.Publisher, plan.Product, plan.Name)
+	}
+	if terms.AgreementProperties == nil {
+		return errors.Errorf("Marketplace terms of plan %s/%s/%s have no properties", plan.Publisher, plan.Product, plan.Name)
+	}
+	if to.Bool(terms.Accepted) {
+		return nil
+	}
+
+	terms.Accepted = to.BoolPtr(true)
+	if err := client.Create(ctx, plan.Publisher, plan.Product, plan.Name, terms); err != nil {
+		return errors.Wrapf(err, "failed to accept Marketplace terms of plan %s/%s/%s", plan.Publisher, plan.Product, plan.Name)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package marketplaceterms
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/marketplaceordering/mgmt/2015-06-01/marketplaceordering"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/marketplaceterms/mock_marketplaceterms"
+)
+
+func TestAccept(t *testing.T) {
+	plan := &infrav1.ImagePlan{
+		Publisher:   "my-publisher",
+		Product:     "my-offer",
+		Name:        "my-plan",
+		AcceptTerms: true,
+	}
+
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(m *mock_marketplaceterms.MockClientMockRecorder)
+	}{
+		{
+			name:          "accept terms",
+			expectedError: "",
+			expect: func(m *mock_marketplaceterms.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-publisher", "my-offer", "my-plan").Return(marketplaceordering.AgreementTerms{
+					AgreementProperties: &marketplaceordering.AgreementProperties{
+						Signature: to.StringPtr("signature"),
+						Accepted:  to.BoolPtr(false),
+					},
+				}, nil)
+				m.Create(context.TODO(), "my-publisher", "my-offer", "my-plan", marketplaceordering.AgreementTerms{
+					AgreementProperties: &marketplaceordering.AgreementProperties{
+						Signature: to.StringPtr("signature"),
+						Accepted:  to.BoolPtr(true),
+					},
+				})
+			},
+		},
+		{
+			name:          "terms already accepted",
+			expectedError: "",
+			expect: func(m *mock_marketplaceterms.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-publisher", "my-offer", "my-plan").Return(marketplaceordering.AgreementTerms{
+					AgreementProperties: &marketplaceordering.AgreementProperties{
+						Accepted: to.BoolPtr(true),
+					},
+				}, nil)
+			},
+		},
+		{
+			name:          "fail to get terms",
+			expectedError: "failed to get Marketplace terms of plan my-publisher/my-offer/my-plan: #: Not found: StatusCode=404",
+			expect: func(m *mock_marketplaceterms.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-publisher", "my-offer", "my-plan").Return(marketplaceordering.AgreementTerms{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+		{
+			name:          "fail to accept terms",
+			expectedError: "failed to accept Marketplace terms of plan my-publisher/my-offer/my-plan: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_marketplaceterms.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-publisher", "my-offer", "my-plan").Return(marketplaceordering.AgreementTerms{
+					AgreementProperties: &marketplaceordering.AgreementProperties{},
+				}, nil)
+				m.Create(context.TODO(), "my-publisher", "my-offer", "my-plan", gomock.AssignableToTypeOf(marketplaceordering.AgreementTerms{})).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			clientMock := mock_marketplaceterms.NewMockClient(mockCtrl)
+
+			tc.expect(clientMock.EXPECT())
+
+			err := Accept(context.TODO(), clientMock, plan)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -21,12 +21,15 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 )
 
 // Client wraps go-sdk
 type Client interface {
 	Get(context.Context, string, string) (network.Interface, error)
+	List(context.Context, string) ([]network.Interface, error)
+	ListScaleSetNetworkInterfaces(context.Context, string, string) ([]network.Interface, error)
 	CreateOrUpdate(context.Context, string, string, network.Interface) error
 	Delete(context.Context, string, string) error
 }
@@ -57,6 +60,40 @@ func (ac *AzureClient) Get(ctx context.Context, resourceGroupName, nicName strin
 	return ac.interfaces.Get(ctx, resourceGroupName, nicName, "")
 }
 
+// List lists the network interfaces in a resource group.
+func (ac *AzureClient) List(ctx context.Context, resourceGroupName string) ([]network.Interface, error) {
+	itr, err := ac.interfaces.ListComplete(ctx, resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	var nics []network.Interface
+	for ; itr.NotDone(); err = itr.NextWithContext(ctx) {
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to iterate network interfaces")
+		}
+		nics = append(nics, itr.Value())
+	}
+	return nics, nil
+}
+
+// ListScaleSetNetworkInterfaces lists the network interfaces of the instances of a virtual machine scale set.
+func (ac *AzureClient) ListScaleSetNetworkInterfaces(ctx context.Context, resourceGroupName, vmssName string) ([]network.Interface, error) {
+	itr, err := ac.interfaces.ListVirtualMachineScaleSetNetworkInterfacesComplete(ctx, resourceGroupName, vmssName)
+	if err != nil {
+		return nil, err
+	}
+
+	var nics []network.Interface
+	for ; itr.NotDone(); err = itr.NextWithContext(ctx) {
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to iterate network interfaces of vm scale set")
+		}
+		nics = append(nics, itr.Value())
+	}
+	return nics, nil
+}
+
 // CreateOrUpdate creates or updates a network interface.
 func (ac *AzureClient) CreateOrUpdate(ctx context.Context, resourceGroupName string, nicName string, nic network.Interface) error {
 	future, err := ac.interfaces.CreateOrUpdate(ctx, resourceGroupName, nicName, nic)
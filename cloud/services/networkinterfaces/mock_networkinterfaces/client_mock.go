@@ -65,6 +65,36 @@ func (mr *MockClientMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClient)(nil).Get), arg0, arg1, arg2)
 }
 
+// List mocks base method.
+func (m *MockClient) List(arg0 context.Context, arg1 string) ([]network.Interface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]network.Interface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockClientMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockClient)(nil).List), arg0, arg1)
+}
+
+// ListScaleSetNetworkInterfaces mocks base method.
+func (m *MockClient) ListScaleSetNetworkInterfaces(arg0 context.Context, arg1, arg2 string) ([]network.Interface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScaleSetNetworkInterfaces", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]network.Interface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScaleSetNetworkInterfaces indicates an expected call of ListScaleSetNetworkInterfaces.
+func (mr *MockClientMockRecorder) ListScaleSetNetworkInterfaces(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScaleSetNetworkInterfaces", reflect.TypeOf((*MockClient)(nil).ListScaleSetNetworkInterfaces), arg0, arg1, arg2)
+}
+
 // CreateOrUpdate mocks base method.
 func (m *MockClient) CreateOrUpdate(arg0 context.Context, arg1, arg2 string, arg3 network.Interface) error {
 	m.ctrl.T.Helper()
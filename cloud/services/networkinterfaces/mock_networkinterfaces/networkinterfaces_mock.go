@@ -95,6 +95,20 @@ func (mr *MockNICScopeMockRecorder) Authorizer() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorizer", reflect.TypeOf((*MockNICScope)(nil).Authorizer))
 }
 
+// CloudEnvironment mocks base method.
+func (m *MockNICScope) CloudEnvironment() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudEnvironment")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CloudEnvironment indicates an expected call of CloudEnvironment.
+func (mr *MockNICScopeMockRecorder) CloudEnvironment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockNICScope)(nil).CloudEnvironment))
+}
+
 // ResourceGroup mocks base method.
 func (m *MockNICScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -221,6 +235,34 @@ func (mr *MockNICScopeMockRecorder) RouteTable() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RouteTable", reflect.TypeOf((*MockNICScope)(nil).RouteTable))
 }
 
+// PrivateDNSZone mocks base method.
+func (m *MockNICScope) PrivateDNSZone() *v1alpha3.PrivateDNSZone {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrivateDNSZone")
+	ret0, _ := ret[0].(*v1alpha3.PrivateDNSZone)
+	return ret0
+}
+
+// PrivateDNSZone indicates an expected call of PrivateDNSZone.
+func (mr *MockNICScopeMockRecorder) PrivateDNSZone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrivateDNSZone", reflect.TypeOf((*MockNICScope)(nil).PrivateDNSZone))
+}
+
+// AvailabilitySets mocks base method.
+func (m *MockNICScope) AvailabilitySets() v1alpha3.AvailabilitySetsSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailabilitySets")
+	ret0, _ := ret[0].(v1alpha3.AvailabilitySetsSpec)
+	return ret0
+}
+
+// AvailabilitySets indicates an expected call of AvailabilitySets.
+func (mr *MockNICScopeMockRecorder) AvailabilitySets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailabilitySets", reflect.TypeOf((*MockNICScope)(nil).AvailabilitySets))
+}
+
 // Info mocks base method.
 func (m *MockNICScope) Info(msg string, keysAndValues ...interface{}) {
 	m.ctrl.T.Helper()
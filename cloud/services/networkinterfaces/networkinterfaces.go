@@ -98,20 +98,41 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		// 	nicSpec.AcceleratedNetworking = &accelNet
 		// }
 
+		ipConfigs := []network.InterfaceIPConfiguration{
+			{
+				Name:                                     to.StringPtr("pipConfig"),
+				InterfaceIPConfigurationPropertiesFormat: nicConfig,
+			},
+		}
+		if nicSpec.SecondaryIPCount > 0 {
+			// Azure requires the primary IP configuration to be flagged once a NIC has more than one.
+			nicConfig.Primary = to.BoolPtr(true)
+			for i := int32(1); i <= nicSpec.SecondaryIPCount; i++ {
+				ipConfigs = append(ipConfigs, network.InterfaceIPConfiguration{
+					Name: to.StringPtr(azure.GenerateSecondaryIPConfigName(i)),
+					InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+						Primary:                   to.BoolPtr(false),
+						Subnet:                    nicConfig.Subnet,
+						PrivateIPAllocationMethod: network.Dynamic,
+					},
+				})
+			}
+		}
+
+		nicProperties := &network.InterfacePropertiesFormat{
+			IPConfigurations:            &ipConfigs,
+			EnableAcceleratedNetworking: nicSpec.AcceleratedNetworking,
+		}
+		if nicSpec.EnableIPForwarding {
+			nicProperties.EnableIPForwarding = to.BoolPtr(true)
+		}
+
 		err = s.Client.CreateOrUpdate(ctx,
 			s.Scope.ResourceGroup(),
 			nicSpec.Name,
 			network.Interface{
-				Location: to.StringPtr(s.Scope.Location()),
-				InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
-					IPConfigurations: &[]network.InterfaceIPConfiguration{
-						{
-							Name:                                     to.StringPtr("pipConfig"),
-							InterfaceIPConfigurationPropertiesFormat: nicConfig,
-						},
-					},
-					EnableAcceleratedNetworking: nicSpec.AcceleratedNetworking,
-				},
+				Location:                  to.StringPtr(s.Scope.Location()),
+				InterfacePropertiesFormat: nicProperties,
 			})
 
 		if err != nil {
@@ -520,6 +520,72 @@ func TestReconcileNetworkInterface(t *testing.T) {
 				)
 			},
 		},
+		{
+			name:          "network interface with IP forwarding and secondary IPs successfully created",
+			expectedError: "",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder,
+				m *mock_networkinterfaces.MockClientMockRecorder,
+				mSubnet *mock_subnets.MockClientMockRecorder,
+				mLoadBalancer *mock_loadbalancers.MockClientMockRecorder,
+				mPublicIP *mock_publicips.MockClientMockRecorder,
+			) {
+				s.NICSpecs().Return([]azure.NICSpec{
+					{
+						Name:                  "my-net-interface",
+						MachineName:           "azure-test1",
+						MachineRole:           infrav1.Node,
+						SubnetName:            "my-subnet",
+						VNetName:              "my-vnet",
+						VNetResourceGroup:     "my-rg",
+						VMSize:                "Standard_D2v2",
+						AcceleratedNetworking: to.BoolPtr(false),
+						EnableIPForwarding:    true,
+						SecondaryIPCount:      2,
+					},
+				})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Location().AnyTimes().Return("fake-location")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+
+				gomock.InOrder(
+					mSubnet.Get(context.TODO(), "my-rg", "my-vnet", "my-subnet").Return(network.Subnet{ID: to.StringPtr("my-subnet-id")}, nil),
+					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", matchers.DiffEq(network.Interface{
+						Location: to.StringPtr("fake-location"),
+						InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+							EnableAcceleratedNetworking: to.BoolPtr(false),
+							EnableIPForwarding:          to.BoolPtr(true),
+							IPConfigurations: &[]network.InterfaceIPConfiguration{
+								{
+									Name: to.StringPtr("pipConfig"),
+									InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+										Primary:                         to.BoolPtr(true),
+										Subnet:                          &network.Subnet{ID: to.StringPtr("my-subnet-id")},
+										PrivateIPAllocationMethod:       network.Dynamic,
+										LoadBalancerBackendAddressPools: &[]network.BackendAddressPool{},
+									},
+								},
+								{
+									Name: to.StringPtr("ipConfig-secondary-1"),
+									InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+										Primary:                   to.BoolPtr(false),
+										Subnet:                    &network.Subnet{ID: to.StringPtr("my-subnet-id")},
+										PrivateIPAllocationMethod: network.Dynamic,
+									},
+								},
+								{
+									Name: to.StringPtr("ipConfig-secondary-2"),
+									InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+										Primary:                   to.BoolPtr(false),
+										Subnet:                    &network.Subnet{ID: to.StringPtr("my-subnet-id")},
+										PrivateIPAllocationMethod: network.Dynamic,
+									},
+								},
+							},
+						},
+					})),
+				)
+			},
+		},
 	}
 
 	for _, tc := range testcases {
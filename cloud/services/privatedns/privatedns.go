@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package privatedns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+)
+
+const (
+	// zoneLocation is the location of private DNS zones, which are global resources.
+	zoneLocation = "global"
+	// recordTTL is the time to live, in seconds, of the A records managed in the zone.
+	recordTTL = 300
+)
+
+// Reconcile gets/creates/updates a private DNS zone, its virtual network link and its records.
+func (s *Service) Reconcile(ctx context.Context) error {
+	dnsSpec := s.Scope.PrivateDNSSpec()
+	if dnsSpec == nil {
+		return nil
+	}
+
+	// The private DNS API is outside of the Azure Stack Hub API profile, so fail instead of calling an API that
+	// Azure Stack Hub does not serve.
+	if err := azure.ValidateProfileFeatures("private DNS zone", dnsSpec.ZoneName, s.Scope.CloudEnvironment(), azure.ProfileFeatures{PrivateDNSZone: true}); err != nil {
+		return err
+	}
+
+	if dnsSpec.ManageZone {
+		if err := s.reconcileZone(ctx, dnsSpec); err != nil {
+			return err
+		}
+	}
+
+	if dnsSpec.LinkName != "" {
+		if err := s.reconcileLink(ctx, dnsSpec); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range dnsSpec.Records {
+		ip := record.IP
+		if record.InternalLoadBalancerName != "" {
+			var err error
+			ip, err = s.getInternalLBIP(ctx, record.InternalLoadBalancerName)
+			if err != nil {
+				return err
+			}
+		}
+		if ip == "" {
+			s.Scope.V(4).Info("skipping private DNS record without IP address", "record", record.Hostname)
+			continue
+		}
+
+		s.Scope.V(2).Info("creating private DNS record", "record", record.Hostname, "zone", dnsSpec.ZoneName, "ip", ip)
+		recordSet := privatedns.RecordSet{
+			RecordSetProperties: &privatedns.RecordSetProperties{
+				TTL: to.Int64Ptr(recordTTL),
+				ARecords: &[]privatedns.ARecord{
+					{
+						Ipv4Address: to.StringPtr(ip),
+					},
+				},
+			},
+		}
+		if err := s.Client.CreateOrUpdateRecordSet(ctx, dnsSpec.ZoneResourceGroup, dnsSpec.ZoneName, privatedns.A, record.Hostname, recordSet); err != nil {
+			return errors.Wrapf(err, "failed to create record %s in private DNS zone %s", record.Hostname, dnsSpec.ZoneName)
+		}
+		s.Scope.V(2).Info("successfully created private DNS record", "record", record.Hostname, "zone", dnsSpec.ZoneName)
+		if record.APIServer {
+			s.Scope.SetAPIServerPrivateDNSName(fmt.Sprintf("%s.%s", record.Hostname, dnsSpec.ZoneName))
+		}
+	}
+	return nil
+}
+
+// Delete deletes the records, the virtual network link and, if managed, the private DNS zone.
+func (s *Service) Delete(ctx context.Context) error {
+	dnsSpec := s.Scope.PrivateDNSSpec()
+	if dnsSpec == nil || !azure.IsAzureCloud(s.Scope.CloudEnvironment()) {
+		// Nothing can have been created without the private DNS API.
+		return nil
+	}
+
+	for _, record := range dnsSpec.Records {
+		s.Scope.V(2).Info("deleting private DNS record", "record", record.Hostname, "zone", dnsSpec.ZoneName)
+		err := s.Client.DeleteRecordSet(ctx, dnsSpec.ZoneResourceGroup, dnsSpec.ZoneName, privatedns.A, record.Hostname)
+		if err != nil && !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to delete record %s in private DNS zone %s", record.Hostname, dnsSpec.ZoneName)
+		}
+	}
+
+	if dnsSpec.LinkName != "" {
+		s.Scope.V(2).Info("deleting private DNS zone virtual network link", "link", dnsSpec.LinkName, "zone", dnsSpec.ZoneName)
+		err := s.Client.DeleteLink(ctx, dnsSpec.ZoneResourceGroup, dnsSpec.ZoneName, dnsSpec.LinkName)
+		if err != nil && !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to delete virtual network link %s of private DNS zone %s", dnsSpec.LinkName, dnsSpec.ZoneName)
+		}
+	}
+
+	if dnsSpec.ManageZone {
+		s.Scope.V(2).Info("deleting private DNS zone", "zone", dnsSpec.ZoneName)
+		err := s.Client.DeleteZone(ctx, dnsSpec.ZoneResourceGroup, dnsSpec.ZoneName)
+		if err != nil && !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to delete private DNS zone %s in resource group %s", dnsSpec.ZoneName, dnsSpec.ZoneResourceGroup)
+		}
+		s.Scope.V(2).Info("successfully deleted private DNS zone", "zone", dnsSpec.ZoneName)
+	}
+	return nil
+}
+
+func (s *Service) reconcileZone(ctx context.Context, dnsSpec *azure.PrivateDNSSpec) error {
+	_, err := s.Client.GetZone(ctx, dnsSpec.ZoneResourceGroup, dnsSpec.ZoneName)
+	if err == nil {
+		return nil
+	}
+	if !azure.ResourceNotFound(err) {
+		return errors.Wrapf(err, "failed to get private DNS zone %s in %s", dnsSpec.ZoneName, dnsSpec.ZoneResourceGroup)
+	}
+
+	s.Scope.V(2).Info("creating private DNS zone", "zone", dnsSpec.ZoneName)
+	err = s.Client.CreateOrUpdateZone(ctx, dnsSpec.ZoneResourceGroup, dnsSpec.ZoneName, privatedns.PrivateZone{
+		Location: to.StringPtr(zoneLocation),
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.Scope.ClusterName(),
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(dnsSpec.ZoneName),
+			Additional:  s.Scope.AdditionalTags(),
+		})),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create private DNS zone %s in resource group %s", dnsSpec.ZoneName, dnsSpec.ZoneResourceGroup)
+	}
+	s.Scope.V(2).Info("successfully created private DNS zone", "zone", dnsSpec.ZoneName)
+	return nil
+}
+
+func (s *Service) reconcileLink(ctx context.Context, dnsSpec *azure.PrivateDNSSpec) error {
+	_, err := s.Client.GetLink(ctx, dnsSpec.ZoneResourceGroup, dnsSpec.ZoneName, dnsSpec.LinkName)
+	if err == nil {
+		return nil
+	}
+	if !azure.ResourceNotFound(err) {
+		return errors.Wrapf(err, "failed to get virtual network link %s of private DNS zone %s", dnsSpec.LinkName, dnsSpec.ZoneName)
+	}
+
+	vnetID := s.Scope.Vnet().ID
+	if vnetID == "" {
+		vnetID = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/virtualNetworks/%s", s.Scope.SubscriptionID(), s.Scope.Vnet().ResourceGroup, s.Scope.Vnet().Name)
+	}
+
+	s.Scope.V(2).Info("creating private DNS zone virtual network link", "link", dnsSpec.LinkName, "zone", dnsSpec.ZoneName)
+	err = s.Client.CreateOrUpdateLink(ctx, dnsSpec.ZoneResourceGroup, dnsSpec.ZoneName, dnsSpec.LinkName, privatedns.VirtualNetworkLink{
+		Location: to.StringPtr(zoneLocation),
+		VirtualNetworkLinkProperties: &privatedns.VirtualNetworkLinkProperties{
+			VirtualNetwork: &privatedns.SubResource{
+				ID: to.StringPtr(vnetID),
+			},
+			RegistrationEnabled: to.BoolPtr(false),
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create virtual network link %s of private DNS zone %s", dnsSpec.LinkName, dnsSpec.ZoneName)
+	}
+	s.Scope.V(2).Info("successfully created private DNS zone virtual network link", "link", dnsSpec.LinkName, "zone", dnsSpec.ZoneName)
+	return nil
+}
+
+// getInternalLBIP returns the private frontend IP address of the internal load balancer.
+func (s *Service) getInternalLBIP(ctx context.Context, lbName string) (string, error) {
+	lb, err := s.LoadBalancersClient.Get(ctx, s.Scope.ResourceGroup(), lbName)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get internal load balancer %s in %s", lbName, s.Scope.ResourceGroup())
+	}
+	if lb.LoadBalancerPropertiesFormat == nil || lb.FrontendIPConfigurations == nil || len(*lb.FrontendIPConfigurations) == 0 {
+		return "", errors.Errorf("internal load balancer %s has no frontend IP configuration", lbName)
+	}
+	frontend := (*lb.FrontendIPConfigurations)[0]
+	if frontend.FrontendIPConfigurationPropertiesFormat == nil {
+		return "", errors.Errorf("internal load balancer %s has no frontend IP configuration", lbName)
+	}
+	return to.String(frontend.PrivateIPAddress), nil
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package privatedns
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	network "github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
+	"github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog/klogr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/loadbalancers/mock_loadbalancers"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/privatedns/mock_privatedns"
+)
+
+func TestReconcilePrivateDNS(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder, mLB *mock_loadbalancers.MockClientMockRecorder)
+	}{
+		{
+			name:          "no private DNS zone",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder, mLB *mock_loadbalancers.MockClientMockRecorder) {
+				s.PrivateDNSSpec().Return(nil)
+			},
+		},
+		{
+			name:          "private DNS zones are not supported on Azure Stack Hub",
+			expectedError: "cannot create private DNS zone cluster.internal: the AzureStackCloud environment does not support private DNS zones",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder, mLB *mock_loadbalancers.MockClientMockRecorder) {
+				s.CloudEnvironment().Return("AzureStackCloud")
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "my-rg",
+					ManageZone:        true,
+				})
+			},
+		},
+		{
+			name:          "create managed zone, link and API server record",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder, mLB *mock_loadbalancers.MockClientMockRecorder) {
+				s.CloudEnvironment().AnyTimes().Return("AzurePublicCloud")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "my-rg",
+					ManageZone:        true,
+					LinkName:          "my-cluster-vnet-link",
+					Records: []azure.PrivateDNSRecordSpec{
+						{
+							Hostname:                 "my-cluster-apiserver",
+							InternalLoadBalancerName: "my-cluster-internal-lb",
+							APIServer:                true,
+						},
+					},
+				})
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				s.AdditionalTags().Return(infrav1.Tags{})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Vnet().AnyTimes().Return(&infrav1.VnetSpec{ID: "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/my-vnet", Name: "my-vnet"})
+				m.GetZone(context.TODO(), "my-rg", "cluster.internal").Return(privatedns.PrivateZone{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdateZone(context.TODO(), "my-rg", "cluster.internal", gomock.AssignableToTypeOf(privatedns.PrivateZone{}))
+				m.GetLink(context.TODO(), "my-rg", "cluster.internal", "my-cluster-vnet-link").Return(privatedns.VirtualNetworkLink{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdateLink(context.TODO(), "my-rg", "cluster.internal", "my-cluster-vnet-link", privatedns.VirtualNetworkLink{
+					Location: to.StringPtr("global"),
+					VirtualNetworkLinkProperties: &privatedns.VirtualNetworkLinkProperties{
+						VirtualNetwork: &privatedns.SubResource{
+							ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/my-vnet"),
+						},
+						RegistrationEnabled: to.BoolPtr(false),
+					},
+				})
+				mLB.Get(context.TODO(), "my-rg", "my-cluster-internal-lb").Return(network.LoadBalancer{
+					LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+						FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
+							{
+								FrontendIPConfigurationPropertiesFormat: &network.FrontendIPConfigurationPropertiesFormat{
+									PrivateIPAddress: to.StringPtr("10.0.0.100"),
+								},
+							},
+						},
+					},
+				}, nil)
+				m.CreateOrUpdateRecordSet(context.TODO(), "my-rg", "cluster.internal", privatedns.A, "my-cluster-apiserver", privatedns.RecordSet{
+					RecordSetProperties: &privatedns.RecordSetProperties{
+						TTL: to.Int64Ptr(300),
+						ARecords: &[]privatedns.ARecord{
+							{
+								Ipv4Address: to.StringPtr("10.0.0.100"),
+							},
+						},
+					},
+				})
+				s.SetAPIServerPrivateDNSName("my-cluster-apiserver.cluster.internal")
+			},
+		},
+		{
+			name:          "existing zone and link are not recreated",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder, mLB *mock_loadbalancers.MockClientMockRecorder) {
+				s.CloudEnvironment().AnyTimes().Return("AzurePublicCloud")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "my-rg",
+					ManageZone:        true,
+					LinkName:          "my-cluster-vnet-link",
+				})
+				m.GetZone(context.TODO(), "my-rg", "cluster.internal").Return(privatedns.PrivateZone{}, nil)
+				m.GetLink(context.TODO(), "my-rg", "cluster.internal", "my-cluster-vnet-link").Return(privatedns.VirtualNetworkLink{}, nil)
+			},
+		},
+		{
+			name:          "node record in an existing zone",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder, mLB *mock_loadbalancers.MockClientMockRecorder) {
+				s.CloudEnvironment().AnyTimes().Return("AzurePublicCloud")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "dns-rg",
+					Records: []azure.PrivateDNSRecordSpec{
+						{
+							Hostname: "my-machine",
+							IP:       "10.1.0.4",
+						},
+					},
+				})
+				m.CreateOrUpdateRecordSet(context.TODO(), "dns-rg", "cluster.internal", privatedns.A, "my-machine", gomock.AssignableToTypeOf(privatedns.RecordSet{}))
+			},
+		},
+		{
+			name:          "node record without IP address is skipped",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder, mLB *mock_loadbalancers.MockClientMockRecorder) {
+				s.CloudEnvironment().AnyTimes().Return("AzurePublicCloud")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "dns-rg",
+					Records: []azure.PrivateDNSRecordSpec{
+						{
+							Hostname: "my-machine",
+						},
+					},
+				})
+			},
+		},
+		{
+			name:          "fail to create zone",
+			expectedError: "failed to create private DNS zone cluster.internal in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder, mLB *mock_loadbalancers.MockClientMockRecorder) {
+				s.CloudEnvironment().AnyTimes().Return("AzurePublicCloud")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "my-rg",
+					ManageZone:        true,
+				})
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				s.AdditionalTags().Return(infrav1.Tags{})
+				m.GetZone(context.TODO(), "my-rg", "cluster.internal").Return(privatedns.PrivateZone{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdateZone(context.TODO(), "my-rg", "cluster.internal", gomock.AssignableToTypeOf(privatedns.PrivateZone{})).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_privatedns.NewMockPrivateDNSScope(mockCtrl)
+			clientMock := mock_privatedns.NewMockClient(mockCtrl)
+			lbMock := mock_loadbalancers.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT(), lbMock.EXPECT())
+
+			s := &Service{
+				Scope:               scopeMock,
+				Client:              clientMock,
+				LoadBalancersClient: lbMock,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeletePrivateDNS(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder)
+	}{
+		{
+			name:          "nothing to delete on Azure Stack Hub",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder) {
+				s.CloudEnvironment().Return("AzureStackCloud")
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "my-rg",
+					ManageZone:        true,
+				})
+			},
+		},
+		{
+			name:          "delete records, link and managed zone",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder) {
+				s.CloudEnvironment().AnyTimes().Return("AzurePublicCloud")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "my-rg",
+					ManageZone:        true,
+					LinkName:          "my-cluster-vnet-link",
+					Records: []azure.PrivateDNSRecordSpec{
+						{
+							Hostname: "my-cluster-apiserver",
+						},
+					},
+				})
+				m.DeleteRecordSet(context.TODO(), "my-rg", "cluster.internal", privatedns.A, "my-cluster-apiserver")
+				m.DeleteLink(context.TODO(), "my-rg", "cluster.internal", "my-cluster-vnet-link")
+				m.DeleteZone(context.TODO(), "my-rg", "cluster.internal")
+			},
+		},
+		{
+			name:          "existing zone is not deleted",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder) {
+				s.CloudEnvironment().AnyTimes().Return("AzurePublicCloud")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "dns-rg",
+					LinkName:          "my-cluster-vnet-link",
+				})
+				m.DeleteLink(context.TODO(), "dns-rg", "cluster.internal", "my-cluster-vnet-link").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+		{
+			name:          "fail to delete record",
+			expectedError: "failed to delete record my-machine in private DNS zone cluster.internal: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_privatedns.MockPrivateDNSScopeMockRecorder, m *mock_privatedns.MockClientMockRecorder) {
+				s.CloudEnvironment().AnyTimes().Return("AzurePublicCloud")
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:          "cluster.internal",
+					ZoneResourceGroup: "dns-rg",
+					Records: []azure.PrivateDNSRecordSpec{
+						{
+							Hostname: "my-machine",
+						},
+					},
+				})
+				m.DeleteRecordSet(context.TODO(), "dns-rg", "cluster.internal", privatedns.A, "my-machine").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_privatedns.NewMockPrivateDNSScope(mockCtrl)
+			clientMock := mock_privatedns.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				Client: clientMock,
+			}
+
+			err := s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
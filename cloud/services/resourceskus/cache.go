@@ -138,40 +138,8 @@ func (c *Cache) GetZones(ctx context.Context, location string) ([]string, error)
 	mapFn := func(sku SKU) {
 		// Look for VMs only
 		if sku.Kind != nil && strings.EqualFold(*sku.Kind, string(VirtualMachines)) {
-			// find matching location
-			for _, locationInfo := range *sku.LocationInfo {
-				if strings.EqualFold(*locationInfo.Location, location) {
-					// Use map for easy deletion and iteration
-					availableZones := make(map[string]bool)
-
-					// add all zones
-					for _, zone := range *locationInfo.Zones {
-						availableZones[zone] = true
-					}
-
-					if sku.Restrictions != nil {
-						for _, restriction := range *sku.Restrictions {
-							// Can't deploy anything in this subscription in this location. Bail out.
-							if restriction.Type == compute.Location {
-								availableZones = nil
-								break
-							}
-
-							// remove restricted zones
-							for _, restrictedZone := range *restriction.RestrictionInfo.Zones {
-								delete(availableZones, restrictedZone)
-							}
-						}
-					}
-
-					// add to global list, if any exist. it's okay for the final list to be empty.
-					// that means the region may not support AZ yet.
-					for zone := range availableZones {
-						allZones[zone] = true
-					}
-
-					break
-				}
+			for zone := range availableZones(sku, location) {
+				allZones[zone] = true
 			}
 		}
 	}
@@ -180,15 +148,7 @@ func (c *Cache) GetZones(ctx context.Context, location string) ([]string, error)
 		return nil, err
 	}
 
-	var zones = make([]string, 0, len(allZones))
-	for zone := range allZones {
-		zones = append(zones, zone)
-	}
-
-	// lexical sort for testing
-	sort.Strings(zones)
-
-	return zones, nil
+	return sortedZones(allZones), nil
 }
 
 // GetZonesWithVMSize returns available zones for a virtual machine size in the given location.
@@ -196,55 +156,74 @@ func (c *Cache) GetZonesWithVMSize(ctx context.Context, size, location string) (
 	var allZones = make(map[string]bool)
 	mapFn := func(sku SKU) {
 		if sku.Name != nil && strings.EqualFold(*sku.Name, size) && sku.Kind != nil && strings.EqualFold(*sku.Kind, string(VirtualMachines)) {
-			// find matching location
-			for _, locationInfo := range *sku.LocationInfo {
-				if strings.EqualFold(*locationInfo.Location, location) {
-					// Use map for easy deletion and iteration
-					availableZones := make(map[string]bool)
-
-					// add all zones
-					for _, zone := range *locationInfo.Zones {
-						availableZones[zone] = true
-					}
+			for zone := range availableZones(sku, location) {
+				allZones[zone] = true
+			}
+		}
+	}
 
-					if sku.Restrictions != nil {
-						for _, restriction := range *sku.Restrictions {
-							// Can't deploy anything in this subscription in this location. Bail out.
-							if restriction.Type == compute.Location {
-								availableZones = nil
-								break
-							}
-
-							// remove restricted zones
-							for _, restrictedZone := range *restriction.RestrictionInfo.Zones {
-								delete(availableZones, restrictedZone)
-							}
-						}
-					}
+	if err := c.Map(ctx, mapFn); err != nil {
+		return nil, err
+	}
 
-					// add to global list, if any exist. it's okay for the final list to be empty.
-					// that means the region may not support AZ yet.
-					for zone := range availableZones {
-						allZones[zone] = true
-					}
+	return sortedZones(allZones), nil
+}
+
+// availableZones returns the zones of a SKU in the given location, minus any restricted zones.
+// It is empty when the location does not offer availability zones, e.g. on Azure Stack Hub,
+// whose SKU catalog omits zone information entirely.
+func availableZones(sku SKU, location string) map[string]bool {
+	// Use map for easy deletion and iteration
+	zones := make(map[string]bool)
+	if sku.LocationInfo == nil {
+		return zones
+	}
+
+	// find matching location
+	for _, locationInfo := range *sku.LocationInfo {
+		if locationInfo.Location == nil || !strings.EqualFold(*locationInfo.Location, location) {
+			continue
+		}
 
-					break
+		// add all zones
+		if locationInfo.Zones != nil {
+			for _, zone := range *locationInfo.Zones {
+				zones[zone] = true
+			}
+		}
+
+		if sku.Restrictions != nil {
+			for _, restriction := range *sku.Restrictions {
+				// Can't deploy anything in this subscription in this location. Bail out.
+				if restriction.Type == compute.Location {
+					return map[string]bool{}
+				}
+
+				// remove restricted zones
+				if restriction.RestrictionInfo != nil && restriction.RestrictionInfo.Zones != nil {
+					for _, restrictedZone := range *restriction.RestrictionInfo.Zones {
+						delete(zones, restrictedZone)
+					}
 				}
 			}
 		}
-	}
 
-	if err := c.Map(ctx, mapFn); err != nil {
-		return nil, err
+		// it's okay for the final list to be empty. that means the region may not support AZ yet.
+		break
 	}
 
-	var zones = make([]string, 0, len(allZones))
-	for zone := range allZones {
+	return zones
+}
+
+// sortedZones returns the zones of a set in lexical order.
+func sortedZones(set map[string]bool) []string {
+	var zones = make([]string, 0, len(set))
+	for zone := range set {
 		zones = append(zones, zone)
 	}
 
 	// lexical sort for testing
 	sort.Strings(zones)
 
-	return zones, nil
+	return zones
 }
@@ -248,6 +248,30 @@ func TestCacheGetZonesWithVMSize(t *testing.T) {
 		have []compute.ResourceSku
 		want []string
 	}{
+		"should find no zones without zone information": {
+			have: []compute.ResourceSku{
+				{
+					Name: to.StringPtr("foo"),
+					Kind: to.StringPtr(string(VirtualMachines)),
+					Locations: &[]string{
+						"baz",
+					},
+					LocationInfo: &[]compute.ResourceSkuLocationInfo{
+						{
+							Location: to.StringPtr("baz"),
+						},
+					},
+				},
+				{
+					Name: to.StringPtr("foo"),
+					Kind: to.StringPtr(string(VirtualMachines)),
+					Locations: &[]string{
+						"baz",
+					},
+				},
+			},
+			want: nil,
+		},
 		"should find 1 result": {
 			have: []compute.ResourceSku{
 				{
@@ -52,6 +52,8 @@ const (
 	EphemeralOSDisk = "EphemeralOSDiskSupported"
 	// AcceleratedNetworking identifies the capability for accelerated networking support.
 	AcceleratedNetworking = "AcceleratedNetworkingEnabled"
+	// MaxWriteAcceleratorDisksAllowed identifies the capability for the number of write accelerated data disks of a VM size.
+	MaxWriteAcceleratorDisksAllowed = "MaxWriteAcceleratorDisksAllowed"
 )
 
 // HasCapability return true for a capability which can be either
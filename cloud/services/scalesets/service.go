@@ -18,21 +18,29 @@ package scalesets
 import (
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/loadbalancers"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/marketplaceterms"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 )
 
 // Service provides operations on azure resources
 type Service struct {
 	Client
-	ResourceSKUCache    *resourceskus.Cache
-	LoadBalancersClient loadbalancers.Client
+	ResourceSKUCache       *resourceskus.Cache
+	LoadBalancersClient    loadbalancers.Client
+	InterfacesClient       networkinterfaces.Client
+	MarketplaceTermsClient marketplaceterms.Client
+	Environment            string
 }
 
 // NewService creates a new service.
 func NewService(auth azure.Authorizer, skuCache *resourceskus.Cache) *Service {
 	return &Service{
-		Client:              NewClient(auth),
-		ResourceSKUCache:    skuCache,
-		LoadBalancersClient: loadbalancers.NewClient(auth),
+		Client:                 NewClient(auth),
+		ResourceSKUCache:       skuCache,
+		LoadBalancersClient:    loadbalancers.NewClient(auth),
+		InterfacesClient:       networkinterfaces.NewClient(auth),
+		MarketplaceTermsClient: marketplaceterms.NewClient(auth),
+		Environment:            auth.CloudEnvironment(),
 	}
 }
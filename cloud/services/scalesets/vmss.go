@@ -19,8 +19,10 @@ package scalesets
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	"k8s.io/klog"
@@ -29,6 +31,8 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/marketplaceterms"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 )
 
 // Spec contains properties to create a managed cluster.
@@ -42,7 +46,8 @@ type (
 		MachinePoolName        string
 		Sku                    string
 		Capacity               int64
-		SSHKeyData             string
+		AdminUsername          string
+		SSHKeys                []string
 		Image                  *infrav1.Image
 		OSDisk                 infrav1.OSDisk
 		DataDisks              []infrav1.DataDisk
@@ -51,6 +56,13 @@ type (
 		PublicLoadBalancerName string
 		AdditionalTags         infrav1.Tags
 		AcceleratedNetworking  *bool
+		EnableIPForwarding     bool
+		SecondaryIPCount       int32
+		AdminPassword          string
+		WindowsRemoteAccess    infrav1.WindowsRemoteAccess
+		Extensions             []azure.VMExtensionSpec
+		BootDiagnostics        *infrav1.BootDiagnostics
+		DiagnosticsStorageURI  string
 	}
 )
 
@@ -65,7 +77,38 @@ func (s *Service) Get(ctx context.Context, vmssSpec *Spec) (*infrav1exp.VMSS, er
 		return nil, err
 	}
 
-	return converters.SDKToVMSS(vmss, vmssInstances), nil
+	converted := converters.SDKToVMSS(vmss, vmssInstances)
+	if vmssSpec.SecondaryIPCount > 0 && len(converted.Instances) > 0 {
+		nics, err := s.InterfacesClient.ListScaleSetNetworkInterfaces(ctx, vmssSpec.ResourceGroup, vmssSpec.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list network interfaces of VMSS %s", vmssSpec.Name)
+		}
+		secondaryIPs := secondaryIPAddressesByInstance(nics)
+		for i, instance := range converted.Instances {
+			converted.Instances[i].SecondaryIPAddresses = secondaryIPs[strings.ToLower(instance.ID)]
+		}
+	}
+	return converted, nil
+}
+
+// secondaryIPAddressesByInstance returns the private IP addresses of the secondary IP configurations of scale set
+// network interfaces, keyed by the lowercased ID of the instance they are attached to.
+func secondaryIPAddressesByInstance(nics []network.Interface) map[string][]string {
+	secondaryIPs := make(map[string][]string)
+	for _, nic := range nics {
+		if nic.InterfacePropertiesFormat == nil || nic.VirtualMachine == nil || nic.VirtualMachine.ID == nil || nic.IPConfigurations == nil {
+			continue
+		}
+		instanceID := strings.ToLower(*nic.VirtualMachine.ID)
+		for _, ipConfig := range *nic.IPConfigurations {
+			// Secondary IP configurations are explicitly marked as non-primary.
+			if ipConfig.InterfaceIPConfigurationPropertiesFormat == nil || ipConfig.Primary == nil || *ipConfig.Primary || ipConfig.PrivateIPAddress == nil {
+				continue
+			}
+			secondaryIPs[instanceID] = append(secondaryIPs[instanceID], *ipConfig.PrivateIPAddress)
+		}
+	}
+	return secondaryIPs
 }
 
 func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
@@ -92,6 +135,13 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	// 	vmssSpec.AcceleratedNetworking = &accelNet
 	// }
 
+	if err := azure.ValidateProfileFeatures("VMSS", vmssSpec.Name, s.Environment, azure.ProfileFeatures{
+		EphemeralOSDisk:  vmssSpec.OSDisk.DiffDiskSettings != nil,
+		MarketplaceTerms: azure.AcceptsMarketplaceTerms(vmssSpec.Image),
+	}); err != nil {
+		return err
+	}
+
 	storageProfile, err := s.generateStorageProfile(ctx, *vmssSpec)
 	if err != nil {
 		return err
@@ -110,6 +160,7 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	}
 
 	vmss := compute.VirtualMachineScaleSet{
+		Plan:     converters.ImageToPlan(vmssSpec.Image),
 		Location: to.StringPtr(vmssSpec.Location),
 		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
 			ClusterName: vmssSpec.ClusterName,
@@ -128,43 +179,18 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 				Mode: "Manual",
 			},
 			VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
-				OsProfile: &compute.VirtualMachineScaleSetOSProfile{
-					ComputerNamePrefix: to.StringPtr(vmssSpec.Name),
-					AdminUsername:      to.StringPtr(azure.DefaultUserName),
-					CustomData:         to.StringPtr(vmssSpec.CustomData),
-					LinuxConfiguration: &compute.LinuxConfiguration{
-						SSH: &compute.SSHConfiguration{
-							PublicKeys: &[]compute.SSHPublicKey{
-								{
-									Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", azure.DefaultUserName)),
-									KeyData: to.StringPtr(vmssSpec.SSHKeyData),
-								},
-							},
-						},
-						DisablePasswordAuthentication: to.BoolPtr(true),
-					},
-				},
-				StorageProfile: storageProfile,
+				OsProfile:          generateOSProfile(*vmssSpec),
+				StorageProfile:     storageProfile,
+				ExtensionProfile:   generateExtensionProfile(*vmssSpec),
+				DiagnosticsProfile: generateDiagnosticsProfile(*vmssSpec),
 				NetworkProfile: &compute.VirtualMachineScaleSetNetworkProfile{
 					NetworkInterfaceConfigurations: &[]compute.VirtualMachineScaleSetNetworkConfiguration{
 						{
 							Name: to.StringPtr(vmssSpec.Name + "-netconfig"),
 							VirtualMachineScaleSetNetworkConfigurationProperties: &compute.VirtualMachineScaleSetNetworkConfigurationProperties{
-								Primary: to.BoolPtr(true),
-								// EnableIPForwarding: to.BoolPtr(true),
-								IPConfigurations: &[]compute.VirtualMachineScaleSetIPConfiguration{
-									{
-										Name: to.StringPtr(vmssSpec.Name + "-ipconfig"),
-										VirtualMachineScaleSetIPConfigurationProperties: &compute.VirtualMachineScaleSetIPConfigurationProperties{
-											Subnet: &compute.APIEntityReference{
-												ID: to.StringPtr(vmssSpec.SubnetID),
-											},
-											Primary:                         to.BoolPtr(true),
-											PrivateIPAddressVersion:         compute.IPv4,
-											LoadBalancerBackendAddressPools: &backendAddressPools,
-										},
-									},
-								},
+								Primary:                     to.BoolPtr(true),
+								EnableIPForwarding:          to.BoolPtr(vmssSpec.EnableIPForwarding),
+								IPConfigurations:            getVMSSIPConfigurations(*vmssSpec, backendAddressPools),
 								EnableAcceleratedNetworking: vmssSpec.AcceleratedNetworking,
 							},
 						},
@@ -174,13 +200,22 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		},
 	}
 
+	// the terms of the plan of a new image must be accepted before instances are created from it
+	if azure.AcceptsMarketplaceTerms(vmssSpec.Image) {
+		klog.V(2).Infof("accepting Marketplace terms of plan %s for VMSS %s", vmssSpec.Image.Marketplace.Plan.Name, vmssSpec.Name)
+		if err := marketplaceterms.Accept(ctx, s.MarketplaceTermsClient, vmssSpec.Image.Marketplace.Plan); err != nil {
+			return err
+		}
+	}
+
 	_, err = s.Client.Get(ctx, vmssSpec.ResourceGroup, vmssSpec.Name)
 	if !azure.ResourceNotFound(err) {
 		if err != nil {
 			return errors.Wrapf(err, "failed to get scale set %s in %s", vmssSpec.Name, vmssSpec.ResourceGroup)
 		}
 		// scale set already exists, update it
-		// we do this to avoid overwriting fields in networkProfile modified by cloud-provider
+		// we do this to avoid overwriting fields in networkProfile modified by cloud-provider, which is why the
+		// AzureMachinePool webhook rejects changes to IP forwarding and secondary IPs
 		update, err := getVMSSUpdateFromVMSS(vmss)
 		if err != nil {
 			return errors.Wrapf(err, "failed to generate scale set update parameters for %s", vmssSpec.Name)
@@ -221,6 +256,97 @@ func (s *Service) Delete(ctx context.Context, spec interface{}) error {
 	return nil
 }
 
+// generateOSProfile generates a pointer to a compute.VirtualMachineScaleSetOSProfile for the OS type of the scale set.
+func generateOSProfile(vmssSpec Spec) *compute.VirtualMachineScaleSetOSProfile {
+	if vmssSpec.OSDisk.OSType == infrav1.WindowsOSType {
+		windowsConfiguration := &compute.WindowsConfiguration{
+			// updates are rolled out by replacing instances
+			EnableAutomaticUpdates: to.BoolPtr(false),
+		}
+		if vmssSpec.WindowsRemoteAccess == infrav1.WindowsRemoteAccessWinRM {
+			windowsConfiguration.WinRM = &compute.WinRMConfiguration{
+				Listeners: &[]compute.WinRMListener{
+					{
+						Protocol: compute.HTTP,
+					},
+				},
+			}
+		}
+		// Windows computer name prefixes are limited to 9 characters
+		return &compute.VirtualMachineScaleSetOSProfile{
+			ComputerNamePrefix:   to.StringPtr(azure.GenerateWindowsComputerNamePrefix(vmssSpec.Name)),
+			AdminUsername:        to.StringPtr(vmssSpec.AdminUsername),
+			AdminPassword:        to.StringPtr(vmssSpec.AdminPassword),
+			CustomData:           to.StringPtr(vmssSpec.CustomData),
+			WindowsConfiguration: windowsConfiguration,
+		}
+	}
+
+	return &compute.VirtualMachineScaleSetOSProfile{
+		ComputerNamePrefix: to.StringPtr(vmssSpec.Name),
+		AdminUsername:      to.StringPtr(vmssSpec.AdminUsername),
+		CustomData:         to.StringPtr(vmssSpec.CustomData),
+		LinuxConfiguration: &compute.LinuxConfiguration{
+			SSH: &compute.SSHConfiguration{
+				PublicKeys: converters.SSHPublicKeysToSDK(vmssSpec.AdminUsername, vmssSpec.SSHKeys),
+			},
+			DisablePasswordAuthentication: to.BoolPtr(true),
+		},
+	}
+}
+
+// generateExtensionProfile generates a pointer to a compute.VirtualMachineScaleSetExtensionProfile with the
+// extension configuring OpenSSH on Windows instances, if any, followed by the VM extensions of the spec.
+func generateExtensionProfile(vmssSpec Spec) *compute.VirtualMachineScaleSetExtensionProfile {
+	extensions := []compute.VirtualMachineScaleSetExtension{}
+	if vmssSpec.OSDisk.OSType == infrav1.WindowsOSType && vmssSpec.WindowsRemoteAccess == infrav1.WindowsRemoteAccessOpenSSH {
+		extensions = append(extensions, compute.VirtualMachineScaleSetExtension{
+			Name: to.StringPtr(azure.WindowsOpenSSHExtensionName),
+			VirtualMachineScaleSetExtensionProperties: &compute.VirtualMachineScaleSetExtensionProperties{
+				Publisher:               to.StringPtr(azure.CustomScriptExtensionPublisher),
+				Type:                    to.StringPtr(azure.CustomScriptExtensionType),
+				TypeHandlerVersion:      to.StringPtr(azure.CustomScriptExtensionVersion),
+				AutoUpgradeMinorVersion: to.BoolPtr(true),
+				ProtectedSettings: map[string]interface{}{
+					"commandToExecute": azure.GenerateWindowsOpenSSHCommand(vmssSpec.SSHKeys),
+				},
+			},
+		})
+	}
+	for _, extSpec := range vmssSpec.Extensions {
+		extensions = append(extensions, compute.VirtualMachineScaleSetExtension{
+			Name: to.StringPtr(extSpec.Name),
+			VirtualMachineScaleSetExtensionProperties: &compute.VirtualMachineScaleSetExtensionProperties{
+				Publisher:               to.StringPtr(extSpec.Publisher),
+				Type:                    to.StringPtr(extSpec.Type),
+				TypeHandlerVersion:      to.StringPtr(extSpec.Version),
+				AutoUpgradeMinorVersion: to.BoolPtr(true),
+				Settings:                extSpec.Settings,
+				ProtectedSettings:       extSpec.ProtectedSettings,
+			},
+		})
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+	return &compute.VirtualMachineScaleSetExtensionProfile{
+		Extensions: &extensions,
+	}
+}
+
+// generateDiagnosticsProfile generates a pointer to a compute.DiagnosticsProfile enabling boot diagnostics, if any.
+func generateDiagnosticsProfile(vmssSpec Spec) *compute.DiagnosticsProfile {
+	if vmssSpec.BootDiagnostics == nil {
+		return nil
+	}
+	return &compute.DiagnosticsProfile{
+		BootDiagnostics: &compute.BootDiagnostics{
+			Enabled:    to.BoolPtr(true),
+			StorageURI: to.StringPtr(vmssSpec.DiagnosticsStorageURI),
+		},
+	}
+}
+
 // generateStorageProfile generates a pointer to a compute.VirtualMachineScaleSetStorageProfile which can utilized for VM creation.
 func (s *Service) generateStorageProfile(ctx context.Context, vmssSpec Spec) (*compute.VirtualMachineScaleSetStorageProfile, error) {
 	storageProfile := &compute.VirtualMachineScaleSetStorageProfile{
@@ -234,28 +360,43 @@ func (s *Service) generateStorageProfile(ctx context.Context, vmssSpec Spec) (*c
 		},
 	}
 
-	// // enable ephemeral OS
-	// if vmssSpec.OSDisk.DiffDiskSettings != nil {
-	// 	if !sku.HasCapability(resourceskus.EphemeralOSDisk) {
-	// 		return nil, fmt.Errorf("vm size %s does not support ephemeral os. select a different vm size or disable ephemeral os", vmssSpec.Sku)
-	// 	}
-
-	// 	// storageProfile.OsDisk.DiffDiskSettings = &compute.DiffDiskSettings{
-	// 	// 	Option: compute.DiffDiskOptions(vmssSpec.OSDisk.DiffDiskSettings.Option),
-	// 	// }
-	// }
-
 	dataDisks := []compute.VirtualMachineScaleSetDataDisk{}
+	writeAcceleratedDisks := 0
 	for _, disk := range vmssSpec.DataDisks {
-		dataDisks = append(dataDisks, compute.VirtualMachineScaleSetDataDisk{
+		dataDisk := compute.VirtualMachineScaleSetDataDisk{
 			CreateOption: compute.DiskCreateOptionTypesEmpty,
 			DiskSizeGB:   to.Int32Ptr(disk.DiskSizeGB),
 			Lun:          disk.Lun,
 			Name:         to.StringPtr(azure.GenerateDataDiskName(vmssSpec.Name, disk.NameSuffix)),
-		})
+			Caching:      compute.CachingTypes(azure.GetDataDiskCachingType(disk)),
+		}
+		if disk.ManagedDisk != nil {
+			dataDisk.ManagedDisk = &compute.VirtualMachineScaleSetManagedDiskParameters{
+				StorageAccountType: compute.StorageAccountTypes(disk.ManagedDisk.StorageAccountType),
+			}
+		}
+		if disk.WriteAcceleratorEnabled {
+			dataDisk.WriteAcceleratorEnabled = to.BoolPtr(true)
+			writeAcceleratedDisks++
+		}
+		dataDisks = append(dataDisks, dataDisk)
 	}
 	storageProfile.DataDisks = &dataDisks
 
+	if writeAcceleratedDisks > 0 {
+		sku, err := s.ResourceSKUCache.Get(ctx, vmssSpec.Sku, resourceskus.VirtualMachines)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get find vm sku %s in compute api", vmssSpec.Sku)
+		}
+		supported, err := sku.HasCapabilityWithCapacity(resourceskus.MaxWriteAcceleratorDisksAllowed, int64(writeAcceleratedDisks))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get write accelerator capability of vm sku %s", vmssSpec.Sku)
+		}
+		if !supported {
+			return nil, fmt.Errorf("vm size %s does not support %d write accelerated data disks. select a different vm size or disable write accelerator", vmssSpec.Sku, writeAcceleratedDisks)
+		}
+	}
+
 	imageRef, err := converters.ImageToSDK(vmssSpec.Image)
 	if err != nil {
 		return nil, err
@@ -266,6 +407,37 @@ func (s *Service) generateStorageProfile(ctx context.Context, vmssSpec Spec) (*c
 	return storageProfile, nil
 }
 
+// getVMSSIPConfigurations returns the primary IP configuration of the scale set network interface, followed by
+// the requested number of secondary IP configurations in the same subnet.
+func getVMSSIPConfigurations(vmssSpec Spec, backendAddressPools []compute.SubResource) *[]compute.VirtualMachineScaleSetIPConfiguration {
+	ipConfigs := []compute.VirtualMachineScaleSetIPConfiguration{
+		{
+			Name: to.StringPtr(vmssSpec.Name + "-ipconfig"),
+			VirtualMachineScaleSetIPConfigurationProperties: &compute.VirtualMachineScaleSetIPConfigurationProperties{
+				Subnet: &compute.APIEntityReference{
+					ID: to.StringPtr(vmssSpec.SubnetID),
+				},
+				Primary:                         to.BoolPtr(true),
+				PrivateIPAddressVersion:         compute.IPv4,
+				LoadBalancerBackendAddressPools: &backendAddressPools,
+			},
+		},
+	}
+	for i := int32(1); i <= vmssSpec.SecondaryIPCount; i++ {
+		ipConfigs = append(ipConfigs, compute.VirtualMachineScaleSetIPConfiguration{
+			Name: to.StringPtr(fmt.Sprintf("%s-%s", vmssSpec.Name, azure.GenerateSecondaryIPConfigName(i))),
+			VirtualMachineScaleSetIPConfigurationProperties: &compute.VirtualMachineScaleSetIPConfigurationProperties{
+				Subnet: &compute.APIEntityReference{
+					ID: to.StringPtr(vmssSpec.SubnetID),
+				},
+				Primary:                 to.BoolPtr(false),
+				PrivateIPAddressVersion: compute.IPv4,
+			},
+		})
+	}
+	return &ipConfigs
+}
+
 func getVMSSUpdateFromVMSS(vmss compute.VirtualMachineScaleSet) (compute.VirtualMachineScaleSetUpdate, error) {
 	json, err := vmss.MarshalJSON()
 	if err != nil {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
@@ -62,7 +63,7 @@ func TestNewService(t *testing.T) {
 		Cluster: cluster,
 		AzureCluster: &infrav1.AzureCluster{
 			Spec: infrav1.AzureClusterSpec{
-				Location: "test-location",
+				Location:       "test-location",
 				ResourceGroup:  "my-rg",
 				SubscriptionID: "123",
 				NetworkSpec: infrav1.NetworkSpec{
@@ -250,6 +251,40 @@ func TestService_Get(t *testing.T) {
 	}
 }
 
+func TestSecondaryIPAddressesByInstance(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instanceID := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachineScaleSets/capz-mp-0/virtualMachines/0"
+	nics := []network.Interface{
+		{
+			InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+				VirtualMachine: &network.SubResource{ID: to.StringPtr(instanceID)},
+				IPConfigurations: &[]network.InterfaceIPConfiguration{
+					{
+						InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+							Primary:          to.BoolPtr(true),
+							PrivateIPAddress: to.StringPtr("10.1.0.4"),
+						},
+					},
+					{
+						InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+							Primary:          to.BoolPtr(false),
+							PrivateIPAddress: to.StringPtr("10.1.0.5"),
+						},
+					},
+				},
+			},
+		},
+		{
+			InterfacePropertiesFormat: &network.InterfacePropertiesFormat{},
+		},
+	}
+
+	g.Expect(secondaryIPAddressesByInstance(nics)).To(gomega.Equal(map[string][]string{
+		strings.ToLower(instanceID): {"10.1.0.5"},
+	}))
+}
+
 func TestService_Reconcile(t *testing.T) {
 	cases := []struct {
 		Name        string
@@ -279,7 +314,8 @@ func TestService_Reconcile(t *testing.T) {
 					MachinePoolName:        mpScope.Name(),
 					Sku:                    "skuName",
 					Capacity:               2,
-					SSHKeyData:             "sshKeyData",
+					AdminUsername:          azure.DefaultUserName,
+					SSHKeys:                []string{"sshKeyData"},
 					OSDisk: infrav1.OSDisk{
 						OSType:     "Linux",
 						DiskSizeGB: 120,
@@ -297,7 +333,8 @@ func TestService_Reconcile(t *testing.T) {
 					Image: &infrav1.Image{
 						ID: to.StringPtr("image"),
 					},
-					CustomData: "customData",
+					CustomData:         "customData",
+					EnableIPForwarding: true,
 				}
 			},
 			Setup: func(ctx context.Context, g *gomega.GomegaWithT, svc *Service, scope *scope.ClusterScope, mpScope *scope.MachinePoolScope, spec *Spec) *gomock.Controller {
@@ -355,7 +392,7 @@ func TestService_Reconcile(t *testing.T) {
 										PublicKeys: &[]compute.SSHPublicKey{
 											{
 												Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", azure.DefaultUserName)),
-												KeyData: to.StringPtr(spec.SSHKeyData),
+												KeyData: to.StringPtr(spec.SSHKeys[0]),
 											},
 										},
 									},
@@ -415,7 +452,8 @@ func TestService_Reconcile(t *testing.T) {
 					MachinePoolName:        mpScope.Name(),
 					Sku:                    "skuName",
 					Capacity:               2,
-					SSHKeyData:             "sshKeyData",
+					AdminUsername:          azure.DefaultUserName,
+					SSHKeys:                []string{"sshKeyData"},
 					OSDisk: infrav1.OSDisk{
 						OSType:     "Linux",
 						DiskSizeGB: 120,
@@ -426,7 +464,8 @@ func TestService_Reconcile(t *testing.T) {
 					Image: &infrav1.Image{
 						ID: to.StringPtr("image"),
 					},
-					CustomData: "customData",
+					CustomData:         "customData",
+					EnableIPForwarding: true,
 				}
 			},
 			Setup: func(ctx context.Context, g *gomega.GomegaWithT, svc *Service, scope *scope.ClusterScope, mpScope *scope.MachinePoolScope, spec *Spec) *gomock.Controller {
@@ -490,7 +529,7 @@ func TestService_Reconcile(t *testing.T) {
 										PublicKeys: &[]compute.SSHPublicKey{
 											{
 												Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", azure.DefaultUserName)),
-												KeyData: to.StringPtr(spec.SSHKeyData),
+												KeyData: to.StringPtr(spec.SSHKeys[0]),
 											},
 										},
 									},
@@ -550,7 +589,8 @@ func TestService_Reconcile(t *testing.T) {
 					MachinePoolName:        mpScope.Name(),
 					Sku:                    "skuName",
 					Capacity:               2,
-					SSHKeyData:             "sshKeyData",
+					AdminUsername:          azure.DefaultUserName,
+					SSHKeys:                []string{"sshKeyData"},
 					OSDisk: infrav1.OSDisk{
 						OSType:     "Linux",
 						DiskSizeGB: 120,
@@ -568,7 +608,8 @@ func TestService_Reconcile(t *testing.T) {
 					Image: &infrav1.Image{
 						ID: to.StringPtr("image"),
 					},
-					CustomData: "customData",
+					CustomData:         "customData",
+					EnableIPForwarding: true,
 				}
 			},
 			Setup: func(ctx context.Context, g *gomega.GomegaWithT, svc *Service, scope *scope.ClusterScope, mpScope *scope.MachinePoolScope, spec *Spec) *gomock.Controller {
@@ -626,7 +667,7 @@ func TestService_Reconcile(t *testing.T) {
 										PublicKeys: &[]compute.SSHPublicKey{
 											{
 												Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", azure.DefaultUserName)),
-												KeyData: to.StringPtr(spec.SSHKeyData),
+												KeyData: to.StringPtr(spec.SSHKeys[0]),
 											},
 										},
 									},
@@ -687,7 +728,7 @@ func TestService_Reconcile(t *testing.T) {
 										PublicKeys: &[]compute.SSHPublicKey{
 											{
 												Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", azure.DefaultUserName)),
-												KeyData: to.StringPtr(spec.SSHKeyData),
+												KeyData: to.StringPtr(spec.SSHKeys[0]),
 											},
 										},
 									},
@@ -706,6 +747,7 @@ func TestService_Reconcile(t *testing.T) {
 										Lun:          to.Int32Ptr(0),
 										CreateOption: "Empty",
 										DiskSizeGB:   to.Int32Ptr(128),
+										Caching:      compute.CachingTypesNone,
 									},
 								},
 							},
@@ -920,7 +962,7 @@ func getScopes(g *gomega.GomegaWithT) (*scope.ClusterScope, *scope.MachinePoolSc
 		Cluster: cluster,
 		AzureCluster: &infrav1.AzureCluster{
 			Spec: infrav1.AzureClusterSpec{
-				Location: "test-location",
+				Location:       "test-location",
 				ResourceGroup:  "my-rg",
 				SubscriptionID: "123",
 				NetworkSpec: infrav1.NetworkSpec{
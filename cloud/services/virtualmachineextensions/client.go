@@ -52,9 +52,9 @@ func newVirtualMachineExtensionsClient(subscriptionID string, baseURI string, au
 	return vmExtClient
 }
 
-// Get the operation to get the extension.
+// Get the operation to get the extension, including its instance view.
 func (ac *AzureClient) Get(ctx context.Context, resourceGroupName, vmName, extName string) (compute.VirtualMachineExtension, error) {
-	return ac.vmextensions.Get(ctx, resourceGroupName, vmName, extName, "")
+	return ac.vmextensions.Get(ctx, resourceGroupName, vmName, extName, "instanceView")
 }
 
 // CreateOrUpdate the operation to create or update the extension.
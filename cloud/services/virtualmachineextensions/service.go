@@ -17,17 +17,31 @@ limitations under the License.
 package virtualmachineextensions
 
 import (
-	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"context"
+
+	"github.com/go-logr/logr"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 )
 
+// VMExtensionScope defines the scope interface for a virtual machine extensions service.
+type VMExtensionScope interface {
+	logr.Logger
+	azure.ClusterDescriber
+	Name() string
+	VMExtensionSpecs(context.Context) ([]azure.VMExtensionSpec, error)
+	VMExtensionStatuses() []infrav1.VMExtensionStatus
+	SetVMExtensionStatuses([]infrav1.VMExtensionStatus)
+}
+
 // Service provides operations on azure resources
 type Service struct {
-	Scope *scope.ClusterScope
+	Scope VMExtensionScope
 	Client
 }
 
 // NewService creates a new service.
-func NewService(scope *scope.ClusterScope) *Service {
+func NewService(scope VMExtensionScope) *Service {
 	return &Service{
 		Scope:  scope,
 		Client: NewClient(scope),
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachineextensions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+)
+
+const (
+	// specHashTagKey is the tag recording the hash of the spec an extension was last installed or updated with.
+	// Protected settings are not returned by Azure, so changes are detected by comparing hashes.
+	specHashTagKey = infrav1.NameAzureProviderPrefix + "vm-extension-hash"
+
+	provisioningStateSucceeded = "Succeeded"
+	provisioningStateFailed    = "Failed"
+	provisioningStateDeleting  = "Deleting"
+)
+
+// Reconcile installs the VM extensions of the machine that do not exist yet, updates the extensions whose spec
+// changed, removes the extensions which are no longer part of the machine and reports their provisioning state.
+func (s *Service) Reconcile(ctx context.Context) error {
+	extSpecs, err := s.Scope.VMExtensionSpecs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	statuses := make([]infrav1.VMExtensionStatus, 0, len(extSpecs))
+	desired := make(map[string]struct{}, len(extSpecs))
+	for _, extSpec := range extSpecs {
+		desired[extSpec.Name] = struct{}{}
+		status, err := s.reconcileExtension(ctx, extSpec)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, status := range s.Scope.VMExtensionStatuses() {
+		if _, ok := desired[status.Name]; ok {
+			continue
+		}
+		if err := s.deleteExtension(ctx, status.Name); err != nil {
+			errs = append(errs, err)
+			// keep reporting the extension so that its removal is retried
+			statuses = append(statuses, infrav1.VMExtensionStatus{
+				Name:              status.Name,
+				ProvisioningState: provisioningStateDeleting,
+				Message:           err.Error(),
+			})
+		}
+	}
+
+	s.Scope.SetVMExtensionStatuses(statuses)
+	return kerrors.NewAggregate(errs)
+}
+
+// reconcileExtension creates or updates a VM extension if it does not exist or its spec changed.
+func (s *Service) reconcileExtension(ctx context.Context, extSpec azure.VMExtensionSpec) (infrav1.VMExtensionStatus, error) {
+	status := infrav1.VMExtensionStatus{Name: extSpec.Name}
+
+	hash, err := specHash(extSpec)
+	if err != nil {
+		return status, errors.Wrapf(err, "failed to hash VM extension %s", extSpec.Name)
+	}
+
+	existing, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), extSpec.VMName, extSpec.Name)
+	switch {
+	case err == nil && to.String(existing.Tags[specHashTagKey]) == hash:
+		// the extension is up to date; failed extensions are not retried until their spec changes
+		if existing.VirtualMachineExtensionProperties != nil {
+			status.ProvisioningState = to.String(existing.ProvisioningState)
+			if status.ProvisioningState == provisioningStateFailed {
+				status.Message = instanceViewMessage(existing.InstanceView)
+			}
+		}
+		return status, nil
+	case err != nil && !azure.ResourceNotFound(err):
+		status.Message = err.Error()
+		return status, errors.Wrapf(err, "failed to get VM extension %s on VM %s", extSpec.Name, extSpec.VMName)
+	}
+
+	s.Scope.V(2).Info("creating or updating VM extension", "vm extension", extSpec.Name, "vm", extSpec.VMName)
+	err = s.Client.CreateOrUpdate(
+		ctx,
+		s.Scope.ResourceGroup(),
+		extSpec.VMName,
+		extSpec.Name,
+		compute.VirtualMachineExtension{
+			Location: to.StringPtr(s.Scope.Location()),
+			Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+				ClusterName: s.Scope.ClusterName(),
+				Lifecycle:   infrav1.ResourceLifecycleOwned,
+				Additional: infrav1.Tags{
+					specHashTagKey: hash,
+				},
+			})),
+			VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
+				Publisher:               to.StringPtr(extSpec.Publisher),
+				Type:                    to.StringPtr(extSpec.Type),
+				TypeHandlerVersion:      to.StringPtr(extSpec.Version),
+				AutoUpgradeMinorVersion: to.BoolPtr(true),
+				Settings:                extSpec.Settings,
+				ProtectedSettings:       extSpec.ProtectedSettings,
+			},
+		})
+	if err != nil {
+		status.ProvisioningState = provisioningStateFailed
+		status.Message = err.Error()
+		return status, errors.Wrapf(err, "failed to create or update VM extension %s on VM %s", extSpec.Name, extSpec.VMName)
+	}
+
+	s.Scope.V(2).Info("successfully created or updated VM extension", "vm extension", extSpec.Name, "vm", extSpec.VMName)
+	status.ProvisioningState = provisioningStateSucceeded
+	return status, nil
+}
+
+// Delete deletes the VM extensions reported in the status of the machine.
+func (s *Service) Delete(ctx context.Context) error {
+	for _, status := range s.Scope.VMExtensionStatuses() {
+		if err := s.deleteExtension(ctx, status.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteExtension deletes a VM extension of the machine.
+func (s *Service) deleteExtension(ctx context.Context, name string) error {
+	s.Scope.V(2).Info("deleting VM extension", "vm extension", name, "vm", s.Scope.Name())
+	err := s.Client.Delete(ctx, s.Scope.ResourceGroup(), s.Scope.Name(), name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete VM extension %s on VM %s", name, s.Scope.Name())
+	}
+	s.Scope.V(2).Info("successfully deleted VM extension", "vm extension", name, "vm", s.Scope.Name())
+	return nil
+}
+
+// instanceViewMessage returns the messages reported by the handler of a VM extension, such as the output of a script.
+func instanceViewMessage(view *compute.VirtualMachineExtensionInstanceView) string {
+	if view == nil {
+		return ""
+	}
+	var messages []string
+	for _, statuses := range []*[]compute.InstanceViewStatus{view.Statuses, view.Substatuses} {
+		if statuses == nil {
+			continue
+		}
+		for _, status := range *statuses {
+			if message := strings.TrimSpace(to.String(status.Message)); message != "" {
+				messages = append(messages, message)
+			}
+		}
+	}
+	return strings.Join(messages, "\n")
+}
+
+// specHash returns a hash of the publisher, type, version and settings of a VM extension.
+func specHash(extSpec azure.VMExtensionSpec) (string, error) {
+	data, err := json.Marshal(struct {
+		Publisher         string
+		Type              string
+		Version           string
+		Settings          map[string]interface{}
+		ProtectedSettings map[string]interface{}
+	}{
+		Publisher:         extSpec.Publisher,
+		Type:              extSpec.Type,
+		Version:           extSpec.Version,
+		Settings:          extSpec.Settings,
+		ProtectedSettings: extSpec.ProtectedSettings,
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
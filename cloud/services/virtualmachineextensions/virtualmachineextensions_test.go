@@ -0,0 +1,234 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachineextensions
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog/klogr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachineextensions/mock_virtualmachineextensions"
+)
+
+var openSSHSpec = azure.VMExtensionSpec{
+	Name:      "capz-windows-openssh",
+	VMName:    "my-vm",
+	Publisher: "Microsoft.Compute",
+	Type:      "CustomScriptExtension",
+	Version:   "1.9",
+	ProtectedSettings: map[string]interface{}{
+		"commandToExecute": "powershell.exe",
+	},
+}
+
+func existingExtension(t *testing.T, spec azure.VMExtensionSpec, provisioningState string) compute.VirtualMachineExtension {
+	hash, err := specHash(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return compute.VirtualMachineExtension{
+		Tags: map[string]*string{specHashTagKey: to.StringPtr(hash)},
+		VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
+			ProvisioningState: to.StringPtr(provisioningState),
+		},
+	}
+}
+
+func TestReconcileVMExtensions(t *testing.T) {
+	testcases := []struct {
+		name             string
+		expectedError    string
+		expectedStatuses []infrav1.VMExtensionStatus
+		expect           func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder)
+	}{
+		{
+			name:          "no extensions",
+			expectedError: "",
+			expect: func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder) {
+				s.VMExtensionSpecs(gomock.Any()).Return(nil, nil)
+				s.VMExtensionStatuses().Return(nil)
+				s.SetVMExtensionStatuses([]infrav1.VMExtensionStatus{})
+			},
+		},
+		{
+			name:             "create extension",
+			expectedError:    "",
+			expectedStatuses: []infrav1.VMExtensionStatus{{Name: "capz-windows-openssh", ProvisioningState: "Succeeded"}},
+			expect: func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.VMExtensionSpecs(gomock.Any()).Return([]azure.VMExtensionSpec{openSSHSpec}, nil)
+				s.VMExtensionStatuses().Return(nil)
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Location().Return("local")
+				m.Get(context.TODO(), "my-rg", "my-vm", "capz-windows-openssh").
+					Return(compute.VirtualMachineExtension{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-vm", "capz-windows-openssh", gomock.AssignableToTypeOf(compute.VirtualMachineExtension{})).
+					Do(func(_ context.Context, _, _, _ string, ext compute.VirtualMachineExtension) {
+						g := NewWithT(t)
+						g.Expect(to.String(ext.Publisher)).To(Equal("Microsoft.Compute"))
+						g.Expect(to.String(ext.VirtualMachineExtensionProperties.Type)).To(Equal("CustomScriptExtension"))
+						g.Expect(to.String(ext.TypeHandlerVersion)).To(Equal("1.9"))
+						g.Expect(ext.ProtectedSettings).To(Equal(openSSHSpec.ProtectedSettings))
+						g.Expect(ext.Tags).To(HaveKey(specHashTagKey))
+					})
+			},
+		},
+		{
+			name:             "extension already exists",
+			expectedError:    "",
+			expectedStatuses: []infrav1.VMExtensionStatus{{Name: "capz-windows-openssh", ProvisioningState: "Succeeded"}},
+			expect: func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.VMExtensionSpecs(gomock.Any()).Return([]azure.VMExtensionSpec{openSSHSpec}, nil)
+				s.VMExtensionStatuses().Return(nil)
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Get(context.TODO(), "my-rg", "my-vm", "capz-windows-openssh").Return(existingExtension(t, openSSHSpec, "Succeeded"), nil)
+			},
+		},
+		{
+			name:             "failed extension is not retried until its spec changes",
+			expectedError:    "",
+			expectedStatuses: []infrav1.VMExtensionStatus{{Name: "capz-windows-openssh", ProvisioningState: "Failed", Message: "Enable failed\nexit code 1"}},
+			expect: func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.VMExtensionSpecs(gomock.Any()).Return([]azure.VMExtensionSpec{openSSHSpec}, nil)
+				s.VMExtensionStatuses().Return(nil)
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				ext := existingExtension(t, openSSHSpec, "Failed")
+				ext.InstanceView = &compute.VirtualMachineExtensionInstanceView{
+					Statuses:    &[]compute.InstanceViewStatus{{Message: to.StringPtr("Enable failed")}},
+					Substatuses: &[]compute.InstanceViewStatus{{Message: to.StringPtr("exit code 1\n")}, {Message: to.StringPtr("")}},
+				}
+				m.Get(context.TODO(), "my-rg", "my-vm", "capz-windows-openssh").Return(ext, nil)
+			},
+		},
+		{
+			name:             "update extension with changed spec",
+			expectedError:    "",
+			expectedStatuses: []infrav1.VMExtensionStatus{{Name: "capz-windows-openssh", ProvisioningState: "Succeeded"}},
+			expect: func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder) {
+				oldSpec := openSSHSpec
+				oldSpec.Version = "1.8"
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.VMExtensionSpecs(gomock.Any()).Return([]azure.VMExtensionSpec{openSSHSpec}, nil)
+				s.VMExtensionStatuses().Return(nil)
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Location().Return("local")
+				m.Get(context.TODO(), "my-rg", "my-vm", "capz-windows-openssh").Return(existingExtension(t, oldSpec, "Succeeded"), nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-vm", "capz-windows-openssh", gomock.AssignableToTypeOf(compute.VirtualMachineExtension{})).
+					Do(func(_ context.Context, _, _, _ string, ext compute.VirtualMachineExtension) {
+						g := NewWithT(t)
+						g.Expect(to.String(ext.TypeHandlerVersion)).To(Equal("1.9"))
+					})
+			},
+		},
+		{
+			name:             "remove extension no longer in spec",
+			expectedError:    "",
+			expectedStatuses: []infrav1.VMExtensionStatus{},
+			expect: func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.VMExtensionSpecs(gomock.Any()).Return(nil, nil)
+				s.VMExtensionStatuses().Return([]infrav1.VMExtensionStatus{{Name: "my-extension", ProvisioningState: "Succeeded"}})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Name().AnyTimes().Return("my-vm")
+				m.Delete(context.TODO(), "my-rg", "my-vm", "my-extension")
+			},
+		},
+		{
+			name:          "fail to remove extension",
+			expectedError: "failed to delete VM extension my-extension on VM my-vm: #: Internal Server Error: StatusCode=500",
+			expectedStatuses: []infrav1.VMExtensionStatus{{
+				Name:              "my-extension",
+				ProvisioningState: "Deleting",
+				Message:           "failed to delete VM extension my-extension on VM my-vm: #: Internal Server Error: StatusCode=500",
+			}},
+			expect: func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.VMExtensionSpecs(gomock.Any()).Return(nil, nil)
+				s.VMExtensionStatuses().Return([]infrav1.VMExtensionStatus{{Name: "my-extension", ProvisioningState: "Succeeded"}})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Name().AnyTimes().Return("my-vm")
+				m.Delete(context.TODO(), "my-rg", "my-vm", "my-extension").
+					Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+		{
+			name:          "fail to create extension",
+			expectedError: "failed to create or update VM extension capz-windows-openssh on VM my-vm: #: Internal Server Error: StatusCode=500",
+			expectedStatuses: []infrav1.VMExtensionStatus{{
+				Name:              "capz-windows-openssh",
+				ProvisioningState: "Failed",
+				Message:           "#: Internal Server Error: StatusCode=500",
+			}},
+			expect: func(s *mock_virtualmachineextensions.MockVMExtensionScopeMockRecorder, m *mock_virtualmachineextensions.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.VMExtensionSpecs(gomock.Any()).Return([]azure.VMExtensionSpec{openSSHSpec}, nil)
+				s.VMExtensionStatuses().Return(nil)
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Location().Return("local")
+				m.Get(context.TODO(), "my-rg", "my-vm", "capz-windows-openssh").
+					Return(compute.VirtualMachineExtension{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-vm", "capz-windows-openssh", gomock.AssignableToTypeOf(compute.VirtualMachineExtension{})).
+					Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_virtualmachineextensions.NewMockVMExtensionScope(mockCtrl)
+			clientMock := mock_virtualmachineextensions.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+			if tc.expectedStatuses != nil {
+				scopeMock.EXPECT().SetVMExtensionStatuses(tc.expectedStatuses)
+			}
+
+			s := &Service{
+				Scope:  scopeMock,
+				Client: clientMock,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachineimages
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+)
+
+// Client wraps go-sdk
+type Client interface {
+	ListVersions(context.Context, string, string, string, string) ([]string, error)
+}
+
+// AzureClient contains the Azure go-sdk Client
+type AzureClient struct {
+	images compute.VirtualMachineImagesClient
+}
+
+var _ Client = &AzureClient{}
+
+// NewClient creates a new VM images client from subscription ID.
+func NewClient(auth azure.Authorizer) *AzureClient {
+	c := newVirtualMachineImagesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	return &AzureClient{c}
+}
+
+// newVirtualMachineImagesClient creates a new VM images client from subscription ID.
+func newVirtualMachineImagesClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachineImagesClient {
+	imagesClient := compute.NewVirtualMachineImagesClientWithBaseURI(baseURI, subscriptionID)
+	imagesClient.Authorizer = authorizer
+	imagesClient.AddToUserAgent(azure.UserAgent())
+	return imagesClient
+}
+
+// ListVersions lists the versions of a Marketplace image available in a location.
+func (ac *AzureClient) ListVersions(ctx context.Context, location, publisher, offer, sku string) ([]string, error) {
+	result, err := ac.images.List(ctx, location, publisher, offer, sku, "", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	if result.Value != nil {
+		for _, image := range *result.Value {
+			versions = append(versions, to.String(image.Name))
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachineimages
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+)
+
+// ResolveImage returns a copy of an image whose Marketplace version is resolved to the latest version available in
+// the location of the cluster when it is "latest". Other images are returned unchanged.
+func (s *Service) ResolveImage(ctx context.Context, image *infrav1.Image) (*infrav1.Image, error) {
+	resolved := image.DeepCopy()
+	mp := resolved.Marketplace
+	if mp == nil || !strings.EqualFold(mp.Version, azure.LatestVersion) {
+		return resolved, nil
+	}
+
+	s.Scope.V(2).Info("resolving latest version of image", "publisher", mp.Publisher, "offer", mp.Offer, "sku", mp.SKU)
+	versions, err := s.Client.ListVersions(ctx, s.Scope.Location(), mp.Publisher, mp.Offer, mp.SKU)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list versions of image %s:%s:%s", mp.Publisher, mp.Offer, mp.SKU)
+	}
+	if len(versions) == 0 {
+		return nil, errors.Errorf("image %s:%s:%s has no version in location %s", mp.Publisher, mp.Offer, mp.SKU, s.Scope.Location())
+	}
+
+	latest := versions[0]
+	for _, version := range versions[1:] {
+		if compareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	mp.Version = latest
+	return resolved, nil
+}
+
+// IsResolvedFrom reports whether an image is the result of resolving another image: both reference the same image and
+// plan, with the same version unless the version of the other image is "latest".
+func IsResolvedFrom(resolved, image *infrav1.Image) bool {
+	if resolved == nil || image == nil {
+		return resolved == image
+	}
+	if resolved.Marketplace == nil || image.Marketplace == nil {
+		return reflect.DeepEqual(resolved, image)
+	}
+	return strings.EqualFold(resolved.Marketplace.Publisher, image.Marketplace.Publisher) &&
+		strings.EqualFold(resolved.Marketplace.Offer, image.Marketplace.Offer) &&
+		strings.EqualFold(resolved.Marketplace.SKU, image.Marketplace.SKU) &&
+		(strings.EqualFold(image.Marketplace.Version, azure.LatestVersion) || resolved.Marketplace.Version == image.Marketplace.Version) &&
+		reflect.DeepEqual(resolved.Marketplace.Plan, image.Marketplace.Plan)
+}
+
+// compareVersions compares two image versions made of dot-separated numbers, such as 18.04.202010140, returning a
+// negative number, zero or a positive number when a is lower than, equal to or greater than b. Parts that are not
+// numbers are compared as strings.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.ParseUint(aParts[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(bParts[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil && aNum < bNum:
+			return -1
+		case aErr == nil && bErr == nil && aNum > bNum:
+			return 1
+		case aErr != nil || bErr != nil:
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(aParts) - len(bParts)
+}
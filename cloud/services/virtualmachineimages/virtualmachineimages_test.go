@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachineimages
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog/klogr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachineimages/mock_virtualmachineimages"
+)
+
+func marketplaceImage(version string) *infrav1.Image {
+	return &infrav1.Image{
+		Marketplace: &infrav1.AzureMarketplaceImage{
+			Publisher: "cncf-upstream",
+			Offer:     "capi",
+			SKU:       "k8s-1dot18dot8-ubuntu-1804",
+			Version:   version,
+		},
+	}
+}
+
+func TestResolveImage(t *testing.T) {
+	testcases := []struct {
+		name          string
+		image         *infrav1.Image
+		expectedImage *infrav1.Image
+		expectedError string
+		expect        func(s *mock_virtualmachineimages.MockVirtualMachineImagesScopeMockRecorder, m *mock_virtualmachineimages.MockClientMockRecorder)
+	}{
+		{
+			name:          "latest version is resolved",
+			image:         marketplaceImage("latest"),
+			expectedImage: marketplaceImage("2020.10.14"),
+			expect: func(s *mock_virtualmachineimages.MockVirtualMachineImagesScopeMockRecorder, m *mock_virtualmachineimages.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.Location().AnyTimes().Return("local")
+				m.ListVersions(context.TODO(), "local", "cncf-upstream", "capi", "k8s-1dot18dot8-ubuntu-1804").
+					Return([]string{"2020.9.30", "2020.10.14", "2020.10.2"}, nil)
+			},
+		},
+		{
+			name:          "concrete version is kept",
+			image:         marketplaceImage("2020.9.30"),
+			expectedImage: marketplaceImage("2020.9.30"),
+			expect: func(s *mock_virtualmachineimages.MockVirtualMachineImagesScopeMockRecorder, m *mock_virtualmachineimages.MockClientMockRecorder) {
+			},
+		},
+		{
+			name:          "image ID is kept",
+			image:         &infrav1.Image{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/images/my-image")},
+			expectedImage: &infrav1.Image{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/images/my-image")},
+			expect: func(s *mock_virtualmachineimages.MockVirtualMachineImagesScopeMockRecorder, m *mock_virtualmachineimages.MockClientMockRecorder) {
+			},
+		},
+		{
+			name:          "no version available",
+			image:         marketplaceImage("latest"),
+			expectedError: "image cncf-upstream:capi:k8s-1dot18dot8-ubuntu-1804 has no version in location local",
+			expect: func(s *mock_virtualmachineimages.MockVirtualMachineImagesScopeMockRecorder, m *mock_virtualmachineimages.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.Location().AnyTimes().Return("local")
+				m.ListVersions(context.TODO(), "local", "cncf-upstream", "capi", "k8s-1dot18dot8-ubuntu-1804").Return(nil, nil)
+			},
+		},
+		{
+			name:          "listing versions fails",
+			image:         marketplaceImage("latest"),
+			expectedError: "failed to list versions of image cncf-upstream:capi:k8s-1dot18dot8-ubuntu-1804: #: Not found: StatusCode=404",
+			expect: func(s *mock_virtualmachineimages.MockVirtualMachineImagesScopeMockRecorder, m *mock_virtualmachineimages.MockClientMockRecorder) {
+				s.V(gomock.AssignableToTypeOf(2)).AnyTimes().Return(klogr.New())
+				s.Location().AnyTimes().Return("local")
+				m.ListVersions(context.TODO(), "local", "cncf-upstream", "capi", "k8s-1dot18dot8-ubuntu-1804").
+					Return(nil, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_virtualmachineimages.NewMockVirtualMachineImagesScope(mockCtrl)
+			clientMock := mock_virtualmachineimages.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				Client: clientMock,
+			}
+
+			image, err := s.ResolveImage(context.TODO(), tc.image)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(image).To(Equal(tc.expectedImage))
+			}
+		})
+	}
+}
+
+func TestIsResolvedFrom(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsResolvedFrom(marketplaceImage("2020.10.14"), marketplaceImage("latest"))).To(BeTrue())
+	g.Expect(IsResolvedFrom(marketplaceImage("2020.10.14"), marketplaceImage("2020.10.14"))).To(BeTrue())
+	g.Expect(IsResolvedFrom(marketplaceImage("2020.10.14"), marketplaceImage("2020.10.15"))).To(BeFalse())
+	g.Expect(IsResolvedFrom(marketplaceImage("2020.10.14"), &infrav1.Image{
+		Marketplace: &infrav1.AzureMarketplaceImage{Publisher: "cncf-upstream", Offer: "capi", SKU: "k8s-1dot19dot1-ubuntu-1804", Version: "latest"},
+	})).To(BeFalse())
+	withPlan := marketplaceImage("latest")
+	withPlan.Marketplace.Plan = &infrav1.ImagePlan{Publisher: "cncf-upstream", Product: "capi", Name: "k8s-1dot18dot8-ubuntu-1804"}
+	g.Expect(IsResolvedFrom(marketplaceImage("2020.10.14"), withPlan)).To(BeFalse())
+	g.Expect(IsResolvedFrom(marketplaceImage("2020.10.14"), &infrav1.Image{ID: to.StringPtr("my-image")})).To(BeFalse())
+	g.Expect(IsResolvedFrom(&infrav1.Image{ID: to.StringPtr("my-image")}, &infrav1.Image{ID: to.StringPtr("my-image")})).To(BeTrue())
+}
+
+func TestCompareVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(compareVersions("18.04.202010140", "18.04.202009220")).To(BeNumerically(">", 0))
+	g.Expect(compareVersions("2020.9.30", "2020.10.2")).To(BeNumerically("<", 0))
+	g.Expect(compareVersions("1.0.0", "1.0.0")).To(Equal(0))
+	g.Expect(compareVersions("1.0", "1.0.1")).To(BeNumerically("<", 0))
+}
@@ -21,14 +21,20 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 )
 
 // Client wraps go-sdk
 type Client interface {
 	Get(context.Context, string, string) (compute.VirtualMachine, error)
+	InstanceView(context.Context, string, string) (compute.VirtualMachineInstanceView, error)
 	CreateOrUpdate(context.Context, string, string, compute.VirtualMachine) error
+	Update(context.Context, string, string, compute.VirtualMachineUpdate) error
 	Delete(context.Context, string, string) error
+	Deallocate(context.Context, string, string) error
+	Start(context.Context, string, string) error
+	ListAvailableSizes(context.Context, string, string) ([]string, error)
 }
 
 // AzureClient contains the Azure go-sdk Client
@@ -52,9 +58,14 @@ func newVirtualMachinesClient(subscriptionID string, baseURI string, authorizer
 	return vmClient
 }
 
-// Get retrieves information about the model view or the instance view of a virtual machine.
+// Get retrieves information about the model view and the instance view of a virtual machine.
 func (ac *AzureClient) Get(ctx context.Context, resourceGroupName, vmName string) (compute.VirtualMachine, error) {
-	return ac.virtualmachines.Get(ctx, resourceGroupName, vmName, "")
+	return ac.virtualmachines.Get(ctx, resourceGroupName, vmName, compute.InstanceView)
+}
+
+// InstanceView retrieves the run-time state of a virtual machine, including the details of a failed provisioning.
+func (ac *AzureClient) InstanceView(ctx context.Context, resourceGroupName, vmName string) (compute.VirtualMachineInstanceView, error) {
+	return ac.virtualmachines.InstanceView(ctx, resourceGroupName, vmName)
 }
 
 // CreateOrUpdate the operation to create or update a virtual machine.
@@ -84,3 +95,60 @@ func (ac *AzureClient) Delete(ctx context.Context, resourceGroupName, vmName str
 	_, err = future.Result(ac.virtualmachines)
 	return err
 }
+
+// Update the operation to update a virtual machine, e.g. its size.
+func (ac *AzureClient) Update(ctx context.Context, resourceGroupName, vmName string, vm compute.VirtualMachineUpdate) error {
+	future, err := ac.virtualmachines.Update(ctx, resourceGroupName, vmName, vm)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.virtualmachines.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.virtualmachines)
+	return err
+}
+
+// Deallocate the operation to stop a virtual machine and release its compute resources.
+func (ac *AzureClient) Deallocate(ctx context.Context, resourceGroupName, vmName string) error {
+	future, err := ac.virtualmachines.Deallocate(ctx, resourceGroupName, vmName)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.virtualmachines.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.virtualmachines)
+	return err
+}
+
+// Start the operation to start a virtual machine.
+func (ac *AzureClient) Start(ctx context.Context, resourceGroupName, vmName string) error {
+	future, err := ac.virtualmachines.Start(ctx, resourceGroupName, vmName)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.virtualmachines.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.virtualmachines)
+	return err
+}
+
+// ListAvailableSizes lists the VM sizes a virtual machine can be resized to without being deallocated.
+func (ac *AzureClient) ListAvailableSizes(ctx context.Context, resourceGroupName, vmName string) ([]string, error) {
+	result, err := ac.virtualmachines.ListAvailableSizes(ctx, resourceGroupName, vmName)
+	if err != nil {
+		return nil, err
+	}
+	var sizes []string
+	if result.Value != nil {
+		for _, size := range *result.Value {
+			sizes = append(sizes, to.String(size.Name))
+		}
+	}
+	return sizes, nil
+}
@@ -66,6 +66,21 @@ func (mr *MockClientMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClient)(nil).Get), arg0, arg1, arg2)
 }
 
+// InstanceView mocks base method.
+func (m *MockClient) InstanceView(arg0 context.Context, arg1, arg2 string) (compute.VirtualMachineInstanceView, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstanceView", arg0, arg1, arg2)
+	ret0, _ := ret[0].(compute.VirtualMachineInstanceView)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InstanceView indicates an expected call of InstanceView.
+func (mr *MockClientMockRecorder) InstanceView(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceView", reflect.TypeOf((*MockClient)(nil).InstanceView), arg0, arg1, arg2)
+}
+
 // CreateOrUpdate mocks base method.
 func (m *MockClient) CreateOrUpdate(arg0 context.Context, arg1, arg2 string, arg3 compute.VirtualMachine) error {
 	m.ctrl.T.Helper()
@@ -80,6 +95,20 @@ func (mr *MockClientMockRecorder) CreateOrUpdate(arg0, arg1, arg2, arg3 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdate", reflect.TypeOf((*MockClient)(nil).CreateOrUpdate), arg0, arg1, arg2, arg3)
 }
 
+// Update mocks base method.
+func (m *MockClient) Update(arg0 context.Context, arg1, arg2 string, arg3 compute.VirtualMachineUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockClientMockRecorder) Update(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockClient)(nil).Update), arg0, arg1, arg2, arg3)
+}
+
 // Delete mocks base method.
 func (m *MockClient) Delete(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -93,3 +122,46 @@ func (mr *MockClientMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClient)(nil).Delete), arg0, arg1, arg2)
 }
+
+// Deallocate mocks base method.
+func (m *MockClient) Deallocate(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deallocate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deallocate indicates an expected call of Deallocate.
+func (mr *MockClientMockRecorder) Deallocate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deallocate", reflect.TypeOf((*MockClient)(nil).Deallocate), arg0, arg1, arg2)
+}
+
+// Start mocks base method.
+func (m *MockClient) Start(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockClientMockRecorder) Start(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockClient)(nil).Start), arg0, arg1, arg2)
+}
+
+// ListAvailableSizes mocks base method.
+func (m *MockClient) ListAvailableSizes(arg0 context.Context, arg1, arg2 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAvailableSizes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAvailableSizes indicates an expected call of ListAvailableSizes.
+func (mr *MockClientMockRecorder) ListAvailableSizes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAvailableSizes", reflect.TypeOf((*MockClient)(nil).ListAvailableSizes), arg0, arg1, arg2)
+}
@@ -18,6 +18,8 @@ package virtualmachines
 
 import (
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/disks"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/marketplaceterms"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicips"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
@@ -28,9 +30,11 @@ type Service struct {
 	Scope        *scope.ClusterScope
 	MachineScope *scope.MachineScope
 	Client
-	InterfacesClient networkinterfaces.Client
-	PublicIPsClient  publicips.Client
-	ResourceSKUCache *resourceskus.Cache
+	DisksClient            disks.Client
+	InterfacesClient       networkinterfaces.Client
+	PublicIPsClient        publicips.Client
+	MarketplaceTermsClient marketplaceterms.Client
+	ResourceSKUCache       *resourceskus.Cache
 }
 
 // NewService creates a new service.
@@ -39,8 +43,10 @@ func NewService(scope *scope.ClusterScope, machineScope *scope.MachineScope, sku
 		Scope:            scope,
 		MachineScope:     machineScope,
 		Client:           NewClient(scope),
-		InterfacesClient: networkinterfaces.NewClient(scope),
-		PublicIPsClient:  publicips.NewClient(scope),
-		ResourceSKUCache: skuCache,
+		DisksClient:            disks.NewClient(scope),
+		InterfacesClient:       networkinterfaces.NewClient(scope),
+		PublicIPsClient:        publicips.NewClient(scope),
+		MarketplaceTermsClient: marketplaceterms.NewClient(scope),
+		ResourceSKUCache:       skuCache,
 	}
 }
@@ -28,6 +28,7 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/marketplaceterms"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 )
 
@@ -35,7 +36,8 @@ import (
 type Spec struct {
 	Name                   string
 	NICNames               []string
-	SSHKeyData             string
+	AdminUsername          string
+	SSHKeys                []string
 	Size                   string
 	Zone                   string
 	Image                  *infrav1.Image
@@ -45,6 +47,11 @@ type Spec struct {
 	CustomData             string
 	UserAssignedIdentities []infrav1.UserAssignedIdentity
 	SpotVMOptions          *infrav1.SpotVMOptions
+	AvailabilitySetID      string
+	AdminPassword          string
+	WindowsRemoteAccess    infrav1.WindowsRemoteAccess
+	BootDiagnostics        *infrav1.BootDiagnostics
+	DiagnosticsStorageURI  string
 }
 
 // Get provides information about a virtual machine.
@@ -63,14 +70,128 @@ func (s *Service) Get(ctx context.Context, vmSpec *Spec) (*infrav1.VM, error) {
 
 	// Discover addresses for NICs associated with the VM
 	// and add them to our converted vm struct
-	addresses, err := s.getAddresses(ctx, vm)
+	addresses, secondaryIPs, err := s.getAddresses(ctx, vm)
 	if err != nil {
 		return convertedVM, err
 	}
 	convertedVM.Addresses = addresses
+	convertedVM.SecondaryIPAddresses = secondaryIPs
 	return convertedVM, nil
 }
 
+// GetProvisioningError returns the errors reported by Azure for the failed provisioning of a virtual machine, from
+// the statuses of its instance view.
+func (s *Service) GetProvisioningError(ctx context.Context, vmSpec *Spec) (string, error) {
+	view, err := s.Client.InstanceView(ctx, s.Scope.ResourceGroup(), vmSpec.Name)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get instance view of VM %s", vmSpec.Name)
+	}
+
+	var messages []string
+	if view.Statuses != nil {
+		for _, status := range *view.Statuses {
+			if status.Level == compute.Error {
+				messages = append(messages, fmt.Sprintf("%s: %s", to.String(status.Code), to.String(status.Message)))
+			}
+		}
+	}
+	return strings.Join(messages, "; "), nil
+}
+
+// GetOSDiskSizeGB returns the size of the OS disk of a virtual machine.
+func (s *Service) GetOSDiskSizeGB(ctx context.Context, vmSpec *Spec) (int32, error) {
+	disk, err := s.DisksClient.Get(ctx, s.Scope.ResourceGroup(), azure.GenerateOSDiskName(vmSpec.Name))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get OS disk of VM %s", vmSpec.Name)
+	}
+	if disk.DiskProperties == nil {
+		return 0, nil
+	}
+	return to.Int32(disk.DiskProperties.DiskSizeGB), nil
+}
+
+// IsSizeAvailable reports whether a virtual machine can be resized to the given VM size without being deallocated.
+func (s *Service) IsSizeAvailable(ctx context.Context, vmSpec *Spec) (bool, error) {
+	sizes, err := s.Client.ListAvailableSizes(ctx, s.Scope.ResourceGroup(), vmSpec.Name)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list available sizes of VM %s", vmSpec.Name)
+	}
+	for _, size := range sizes {
+		if strings.EqualFold(size, vmSpec.Size) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InAvailabilitySet reports whether a virtual machine is placed in an availability set.
+func (s *Service) InAvailabilitySet(ctx context.Context, vmSpec *Spec) (bool, error) {
+	vm, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), vmSpec.Name)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get VM %s", vmSpec.Name)
+	}
+	return vm.VirtualMachineProperties != nil && vm.AvailabilitySet != nil && to.String(vm.AvailabilitySet.ID) != "", nil
+}
+
+// Deallocate stops a virtual machine and releases its compute resources.
+func (s *Service) Deallocate(ctx context.Context, vmSpec *Spec) error {
+	s.Scope.V(2).Info("deallocating VM", "vm", vmSpec.Name)
+	if err := s.Client.Deallocate(ctx, s.Scope.ResourceGroup(), vmSpec.Name); err != nil {
+		return errors.Wrapf(err, "failed to deallocate VM %s", vmSpec.Name)
+	}
+	return nil
+}
+
+// Start starts a virtual machine.
+func (s *Service) Start(ctx context.Context, vmSpec *Spec) error {
+	s.Scope.V(2).Info("starting VM", "vm", vmSpec.Name)
+	if err := s.Client.Start(ctx, s.Scope.ResourceGroup(), vmSpec.Name); err != nil {
+		return errors.Wrapf(err, "failed to start VM %s", vmSpec.Name)
+	}
+	return nil
+}
+
+// Resize changes the VM size of a virtual machine to the size of the spec.
+func (s *Service) Resize(ctx context.Context, vmSpec *Spec) error {
+	s.Scope.V(2).Info("resizing VM", "vm", vmSpec.Name, "size", vmSpec.Size)
+	update := compute.VirtualMachineUpdate{
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			HardwareProfile: &compute.HardwareProfile{
+				VMSize: compute.VirtualMachineSizeTypes(vmSpec.Size),
+			},
+		},
+	}
+	if err := s.Client.Update(ctx, s.Scope.ResourceGroup(), vmSpec.Name, update); err != nil {
+		return errors.Wrapf(err, "failed to resize VM %s to %s", vmSpec.Name, vmSpec.Size)
+	}
+	return nil
+}
+
+// GrowOSDisk grows the OS disk of a deallocated virtual machine to the size of the spec. OS disks are never shrunk.
+func (s *Service) GrowOSDisk(ctx context.Context, vmSpec *Spec) error {
+	current, err := s.GetOSDiskSizeGB(ctx, vmSpec)
+	if err != nil {
+		return err
+	}
+	if vmSpec.OSDisk.DiskSizeGB < current {
+		return errors.Errorf("cannot shrink OS disk of VM %s from %dGB to %dGB", vmSpec.Name, current, vmSpec.OSDisk.DiskSizeGB)
+	}
+	if vmSpec.OSDisk.DiskSizeGB == current {
+		return nil
+	}
+
+	s.Scope.V(2).Info("growing OS disk", "vm", vmSpec.Name, "diskSizeGB", vmSpec.OSDisk.DiskSizeGB)
+	update := compute.DiskUpdate{
+		DiskUpdateProperties: &compute.DiskUpdateProperties{
+			DiskSizeGB: to.Int32Ptr(vmSpec.OSDisk.DiskSizeGB),
+		},
+	}
+	if err := s.DisksClient.Update(ctx, s.Scope.ResourceGroup(), azure.GenerateOSDiskName(vmSpec.Name), update); err != nil {
+		return errors.Wrapf(err, "failed to grow OS disk of VM %s", vmSpec.Name)
+	}
+	return nil
+}
+
 // Reconcile gets/creates/updates a virtual machine.
 func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	vmSpec, ok := spec.(*Spec)
@@ -78,6 +199,14 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		return errors.New("invalid VM specification")
 	}
 
+	if err := azure.ValidateProfileFeatures("VM", vmSpec.Name, s.Scope.CloudEnvironment(), azure.ProfileFeatures{
+		SpotVM:           vmSpec.SpotVMOptions != nil,
+		EphemeralOSDisk:  vmSpec.OSDisk.DiffDiskSettings != nil,
+		MarketplaceTerms: azure.AcceptsMarketplaceTerms(vmSpec.Image),
+	}); err != nil {
+		return err
+	}
+
 	storageProfile, err := s.generateStorageProfile(ctx, *vmSpec)
 	if err != nil {
 		return err
@@ -100,6 +229,13 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		}
 	}
 
+	if azure.AcceptsMarketplaceTerms(vmSpec.Image) {
+		s.Scope.V(2).Info("accepting Marketplace terms", "plan", vmSpec.Image.Marketplace.Plan.Name)
+		if err := marketplaceterms.Accept(ctx, s.MarketplaceTermsClient, vmSpec.Image.Marketplace.Plan); err != nil {
+			return err
+		}
+	}
+
 	s.Scope.V(2).Info("creating VM", "vm", vmSpec.Name)
 
 	// Make sure to use the MachineScope here to get the merger of AzureCluster and AzureMachine tags
@@ -107,14 +243,8 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	// Set the cloud provider tag
 	additionalTags[infrav1.ClusterAzureCloudProviderTagKey(s.MachineScope.Name())] = string(infrav1.ResourceLifecycleOwned)
 
-	/*
-		priority, evictionPolicy, billingProfile, err := getSpotVMOptions(vmSpec.SpotVMOptions)
-		if err != nil {
-			return errors.Wrapf(err, "failed to get Spot VM options")
-		}
-	*/
-
 	virtualMachine := compute.VirtualMachine{
+		Plan:     converters.ImageToPlan(vmSpec.Image),
 		Location: to.StringPtr(s.Scope.Location()),
 		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
 			ClusterName: s.Scope.ClusterName(),
@@ -128,33 +258,29 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 				VMSize: compute.VirtualMachineSizeTypes(vmSpec.Size),
 			},
 			StorageProfile: storageProfile,
-			OsProfile: &compute.OSProfile{
-				ComputerName:  to.StringPtr(vmSpec.Name),
-				AdminUsername: to.StringPtr(azure.DefaultUserName),
-				CustomData:    to.StringPtr(vmSpec.CustomData),
-				LinuxConfiguration: &compute.LinuxConfiguration{
-					DisablePasswordAuthentication: to.BoolPtr(true),
-					SSH: &compute.SSHConfiguration{
-						PublicKeys: &[]compute.SSHPublicKey{
-							{
-								Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", azure.DefaultUserName)),
-								KeyData: to.StringPtr(vmSpec.SSHKeyData),
-							},
-						},
-					},
-				},
-			},
+			OsProfile:      generateOSProfile(*vmSpec),
 			NetworkProfile: &compute.NetworkProfile{
 				NetworkInterfaces: &nicRefs,
 			},
 		},
 	}
 
+	if vmSpec.BootDiagnostics != nil {
+		virtualMachine.DiagnosticsProfile = &compute.DiagnosticsProfile{
+			BootDiagnostics: &compute.BootDiagnostics{
+				Enabled:    to.BoolPtr(true),
+				StorageURI: to.StringPtr(vmSpec.DiagnosticsStorageURI),
+			},
+		}
+	}
+
 	s.Scope.V(2).Info("Setting zone", "zone", vmSpec.Zone)
 
 	if vmSpec.Zone != "" {
 		zones := []string{vmSpec.Zone}
 		virtualMachine.Zones = &zones
+	} else if vmSpec.AvailabilitySetID != "" {
+		virtualMachine.AvailabilitySet = &compute.SubResource{ID: to.StringPtr(vmSpec.AvailabilitySetID)}
 	}
 
 	if vmSpec.Identity == infrav1.VMIdentitySystemAssigned {
@@ -229,12 +355,13 @@ func (s *Service) Delete(ctx context.Context, spec interface{}) error {
 	return nil
 }
 
-func (s *Service) getAddresses(ctx context.Context, vm compute.VirtualMachine) ([]corev1.NodeAddress, error) {
+func (s *Service) getAddresses(ctx context.Context, vm compute.VirtualMachine) ([]corev1.NodeAddress, []string, error) {
 
 	addresses := []corev1.NodeAddress{}
+	secondaryIPs := []string{}
 
 	if vm.NetworkProfile.NetworkInterfaces == nil {
-		return addresses, nil
+		return addresses, secondaryIPs, nil
 	}
 	for _, nicRef := range *vm.NetworkProfile.NetworkInterfaces {
 
@@ -249,13 +376,21 @@ func (s *Service) getAddresses(ctx context.Context, vm compute.VirtualMachine) (
 		// Fetch nic and append its addresses
 		nic, err := s.InterfacesClient.Get(ctx, s.Scope.ResourceGroup(), nicName)
 		if err != nil {
-			return addresses, err
+			return addresses, secondaryIPs, err
 		}
 
 		if nic.IPConfigurations == nil {
 			continue
 		}
 		for _, ipConfig := range *nic.IPConfigurations {
+			// Secondary IP configurations are explicitly marked as non-primary and are not node addresses.
+			if ipConfig.Primary != nil && !*ipConfig.Primary {
+				if ipConfig.PrivateIPAddress != nil {
+					secondaryIPs = append(secondaryIPs, to.String(ipConfig.PrivateIPAddress))
+				}
+				continue
+			}
+
 			if ipConfig.PrivateIPAddress != nil {
 				addresses = append(addresses,
 					corev1.NodeAddress{
@@ -273,13 +408,13 @@ func (s *Service) getAddresses(ctx context.Context, vm compute.VirtualMachine) (
 			publicIPName := getResourceNameByID(to.String(ipConfig.PublicIPAddress.ID))
 			publicNodeAddress, err := s.getPublicIPAddress(ctx, publicIPName)
 			if err != nil {
-				return addresses, err
+				return addresses, secondaryIPs, err
 			}
 			addresses = append(addresses, publicNodeAddress)
 		}
 	}
 
-	return addresses, nil
+	return addresses, secondaryIPs, nil
 }
 
 // getPublicIPAddress will fetch a public ip address resource by name and return a nodeaddresss representation
@@ -304,6 +439,45 @@ func getResourceNameByID(resourceID string) string {
 	return resourceName
 }
 
+// generateOSProfile generates a pointer to a compute.OSProfile for the OS type of the VM.
+func generateOSProfile(vmSpec Spec) *compute.OSProfile {
+	if vmSpec.OSDisk.OSType == infrav1.WindowsOSType {
+		windowsConfiguration := &compute.WindowsConfiguration{
+			// updates are rolled out by replacing machines
+			EnableAutomaticUpdates: to.BoolPtr(false),
+		}
+		if vmSpec.WindowsRemoteAccess == infrav1.WindowsRemoteAccessWinRM {
+			windowsConfiguration.WinRM = &compute.WinRMConfiguration{
+				Listeners: &[]compute.WinRMListener{
+					{
+						Protocol: compute.HTTP,
+					},
+				},
+			}
+		}
+		// Windows computer names are limited to 15 characters
+		return &compute.OSProfile{
+			ComputerName:         to.StringPtr(azure.GenerateWindowsComputerName(vmSpec.Name)),
+			AdminUsername:        to.StringPtr(vmSpec.AdminUsername),
+			AdminPassword:        to.StringPtr(vmSpec.AdminPassword),
+			CustomData:           to.StringPtr(vmSpec.CustomData),
+			WindowsConfiguration: windowsConfiguration,
+		}
+	}
+
+	return &compute.OSProfile{
+		ComputerName:  to.StringPtr(vmSpec.Name),
+		AdminUsername: to.StringPtr(vmSpec.AdminUsername),
+		CustomData:    to.StringPtr(vmSpec.CustomData),
+		LinuxConfiguration: &compute.LinuxConfiguration{
+			DisablePasswordAuthentication: to.BoolPtr(true),
+			SSH: &compute.SSHConfiguration{
+				PublicKeys: converters.SSHPublicKeysToSDK(vmSpec.AdminUsername, vmSpec.SSHKeys),
+			},
+		},
+	}
+}
+
 // generateStorageProfile generates a pointer to a compute.StorageProfile which can utilized for VM creation.
 func (s *Service) generateStorageProfile(ctx context.Context, vmSpec Spec) (*compute.StorageProfile, error) {
 	storageProfile := &compute.StorageProfile{
@@ -318,33 +492,60 @@ func (s *Service) generateStorageProfile(ctx context.Context, vmSpec Spec) (*com
 		},
 	}
 
-	// enable ephemeral OS
-	if vmSpec.OSDisk.DiffDiskSettings != nil {
-		sku, err := s.ResourceSKUCache.Get(ctx, vmSpec.Size, resourceskus.VirtualMachines)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get find vm sku %s in compute api", vmSpec.Size)
-		}
-
-		if !sku.HasCapability(resourceskus.EphemeralOSDisk) {
-			return nil, fmt.Errorf("vm size %s does not support ephemeral os. select a different vm size or disable ephemeral os", vmSpec.Size)
-		}
-
-		// storageProfile.OsDisk.DiffDiskSettings = &compute.DiffDiskSettings{
-		// 	Option: compute.DiffDiskOptions(vmSpec.OSDisk.DiffDiskSettings.Option),
-		// }
-	}
-
 	dataDisks := []compute.DataDisk{}
+	writeAcceleratedDisks := 0
 	for _, disk := range vmSpec.DataDisks {
-		dataDisks = append(dataDisks, compute.DataDisk{
+		dataDisk := compute.DataDisk{
 			CreateOption: compute.DiskCreateOptionTypesEmpty,
 			DiskSizeGB:   to.Int32Ptr(disk.DiskSizeGB),
 			Lun:          disk.Lun,
 			Name:         to.StringPtr(azure.GenerateDataDiskName(vmSpec.Name, disk.NameSuffix)),
-		})
+			Caching:      compute.CachingTypes(azure.GetDataDiskCachingType(disk)),
+		}
+		if disk.ManagedDisk != nil {
+			dataDisk.ManagedDisk = &compute.ManagedDiskParameters{
+				StorageAccountType: compute.StorageAccountTypes(disk.ManagedDisk.StorageAccountType),
+			}
+		}
+		if disk.WriteAcceleratorEnabled {
+			dataDisk.WriteAcceleratorEnabled = to.BoolPtr(true)
+			writeAcceleratedDisks++
+		}
+
+		// attach a data disk retained from a previous machine of the same name
+		if disk.RetainOnDelete {
+			existing, err := s.DisksClient.Get(ctx, s.Scope.ResourceGroup(), *dataDisk.Name)
+			switch {
+			case err == nil:
+				s.Scope.V(2).Info("attaching retained data disk", "disk", *dataDisk.Name)
+				dataDisk.CreateOption = compute.DiskCreateOptionTypesAttach
+				dataDisk.DiskSizeGB = nil
+				dataDisk.ManagedDisk = &compute.ManagedDiskParameters{
+					ID: existing.ID,
+				}
+			case !azure.ResourceNotFound(err):
+				return nil, errors.Wrapf(err, "failed to get retained data disk %s", *dataDisk.Name)
+			}
+		}
+
+		dataDisks = append(dataDisks, dataDisk)
 	}
 	storageProfile.DataDisks = &dataDisks
 
+	if writeAcceleratedDisks > 0 {
+		sku, err := s.ResourceSKUCache.Get(ctx, vmSpec.Size, resourceskus.VirtualMachines)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get find vm sku %s in compute api", vmSpec.Size)
+		}
+		supported, err := sku.HasCapabilityWithCapacity(resourceskus.MaxWriteAcceleratorDisksAllowed, int64(writeAcceleratedDisks))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get write accelerator capability of vm sku %s", vmSpec.Size)
+		}
+		if !supported {
+			return nil, fmt.Errorf("vm size %s does not support %d write accelerated data disks. select a different vm size or disable write accelerator", vmSpec.Size, writeAcceleratedDisks)
+		}
+	}
+
 	imageRef, err := converters.ImageToSDK(vmSpec.Image)
 	if err != nil {
 		return nil, err
@@ -354,23 +555,3 @@ func (s *Service) generateStorageProfile(ctx context.Context, vmSpec Spec) (*com
 
 	return storageProfile, nil
 }
-
-/*
-func getSpotVMOptions(spotVMOptions *infrav1.SpotVMOptions) (compute.VirtualMachinePriorityTypes, compute.VirtualMachineEvictionPolicyTypes, *compute.BillingProfile, error) {
-	// Spot VM not requested, return zero values to apply defaults
-	if spotVMOptions == nil {
-		return "", "", nil, nil
-	}
-	var billingProfile *compute.BillingProfile
-	if spotVMOptions.MaxPrice != nil {
-		maxPrice, err := strconv.ParseFloat(*spotVMOptions.MaxPrice, 64)
-		if err != nil {
-			return compute.VirtualMachinePriorityTypes(""), compute.VirtualMachineEvictionPolicyTypes(""), nil, err
-		}
-		billingProfile = &compute.BillingProfile{
-			MaxPrice: &maxPrice,
-		}
-	}
-	return compute.Spot, compute.Deallocate, billingProfile, nil
-}
-*/
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/disks/mock_disks"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces/mock_networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicips/mock_publicips"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachines/mock_virtualmachines"
@@ -35,7 +36,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/klogr"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -72,7 +75,7 @@ func TestInvalidVM(t *testing.T) {
 		Cluster: cluster,
 		AzureCluster: &infrav1.AzureCluster{
 			Spec: infrav1.AzureClusterSpec{
-				Location: "test-location",
+				Location:       "test-location",
 				ResourceGroup:  "my-rg",
 				SubscriptionID: subscriptionID,
 				NetworkSpec: infrav1.NetworkSpec{
@@ -307,7 +310,7 @@ func TestGetVM(t *testing.T) {
 				Cluster: cluster,
 				AzureCluster: &infrav1.AzureCluster{
 					Spec: infrav1.AzureClusterSpec{
-						Location: "test-location",
+						Location:       "test-location",
 						ResourceGroup:  "my-rg",
 						SubscriptionID: subscriptionID,
 						NetworkSpec: infrav1.NetworkSpec{
@@ -505,7 +508,7 @@ func TestReconcileVM(t *testing.T) {
 			expectedError: "",
 		},
 		{
-			name: "can create a vm on spot",
+			name: "vm creation on spot fails on the Azure Stack Hub profile",
 			machine: clusterv1.Machine{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{"set": "node"},
@@ -544,11 +547,8 @@ func TestReconcileVM(t *testing.T) {
 				},
 			},
 			expect: func(g *WithT, m *mock_virtualmachines.MockClientMockRecorder, mnic *mock_networkinterfaces.MockClientMockRecorder, mpip *mock_publicips.MockClientMockRecorder) {
-				mnic.Get(gomock.Any(), gomock.Any(), gomock.Any())
-				m.CreateOrUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_, _, _ interface{}, vm compute.VirtualMachine) {
-				})
 			},
-			expectedError: "",
+			expectedError: "cannot create VM azure-test1: the Azure Stack Hub API profile does not support Spot VMs",
 		},
 		{
 			name: "vm creation fails",
@@ -672,7 +672,8 @@ func TestReconcileVM(t *testing.T) {
 			vmSpec := &Spec{
 				Name:          machineScope.Name(),
 				NICNames:      []string{"test-nic"},
-				SSHKeyData:    "fake-key",
+				AdminUsername: azure.DefaultUserName,
+				SSHKeys:       []string{"fake-key"},
 				Size:          machineScope.AzureMachine.Spec.VMSize,
 				OSDisk:        machineScope.AzureMachine.Spec.OSDisk,
 				Image:         machineScope.AzureMachine.Spec.Image,
@@ -691,6 +692,45 @@ func TestReconcileVM(t *testing.T) {
 	}
 }
 
+func TestGenerateOSProfile(t *testing.T) {
+	g := NewWithT(t)
+
+	linux := generateOSProfile(Spec{
+		Name:          "my-cluster-md-0-abcde",
+		AdminUsername: "core",
+		SSHKeys:       []string{"ssh-rsa AAAA", "ssh-ed25519 BBBB"},
+		OSDisk:        infrav1.OSDisk{OSType: infrav1.LinuxOSType},
+	})
+	g.Expect(to.String(linux.ComputerName)).To(Equal("my-cluster-md-0-abcde"))
+	g.Expect(linux.LinuxConfiguration).NotTo(BeNil())
+	g.Expect(linux.WindowsConfiguration).To(BeNil())
+	g.Expect(linux.AdminPassword).To(BeNil())
+	g.Expect(to.String(linux.AdminUsername)).To(Equal("core"))
+	g.Expect(*linux.LinuxConfiguration.SSH.PublicKeys).To(Equal([]compute.SSHPublicKey{
+		{Path: to.StringPtr("/home/core/.ssh/authorized_keys"), KeyData: to.StringPtr("ssh-rsa AAAA")},
+		{Path: to.StringPtr("/home/core/.ssh/authorized_keys"), KeyData: to.StringPtr("ssh-ed25519 BBBB")},
+	}))
+
+	windows := generateOSProfile(Spec{
+		Name:          "my-cluster-md-win-abcde",
+		AdminPassword: "P@ssw0rd",
+		OSDisk:        infrav1.OSDisk{OSType: infrav1.WindowsOSType},
+	})
+	g.Expect(to.String(windows.ComputerName)).To(HaveLen(15))
+	g.Expect(to.String(windows.AdminPassword)).To(Equal("P@ssw0rd"))
+	g.Expect(windows.LinuxConfiguration).To(BeNil())
+	g.Expect(windows.WindowsConfiguration).NotTo(BeNil())
+	g.Expect(windows.WindowsConfiguration.WinRM).To(BeNil())
+
+	winRM := generateOSProfile(Spec{
+		Name:                "win-abcde",
+		OSDisk:              infrav1.OSDisk{OSType: infrav1.WindowsOSType},
+		WindowsRemoteAccess: infrav1.WindowsRemoteAccessWinRM,
+	})
+	g.Expect(to.String(winRM.ComputerName)).To(Equal("win-abcde"))
+	g.Expect(*winRM.WindowsConfiguration.WinRM.Listeners).To(ConsistOf(compute.WinRMListener{Protocol: compute.HTTP}))
+}
+
 func TestDeleteVM(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -757,7 +797,7 @@ func TestDeleteVM(t *testing.T) {
 				Cluster: cluster,
 				AzureCluster: &infrav1.AzureCluster{
 					Spec: infrav1.AzureClusterSpec{
-						Location: "test-location",
+						Location:       "test-location",
 						ResourceGroup:  "my-rg",
 						SubscriptionID: subscriptionID,
 						NetworkSpec: infrav1.NetworkSpec{
@@ -783,3 +823,219 @@ func TestDeleteVM(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProvisioningError(t *testing.T) {
+	testcases := []struct {
+		name            string
+		expectedMessage string
+		expectedError   string
+		expect          func(m *mock_virtualmachines.MockClientMockRecorder)
+	}{
+		{
+			name:            "returns the error statuses of the instance view",
+			expectedMessage: "ProvisioningState/failed/OperationNotAllowed: Operation results in exceeding quota limits of Core.",
+			expect: func(m *mock_virtualmachines.MockClientMockRecorder) {
+				m.InstanceView(context.TODO(), "my-rg", "my-vm").Return(compute.VirtualMachineInstanceView{
+					Statuses: &[]compute.InstanceViewStatus{
+						{
+							Code:    to.StringPtr("ProvisioningState/failed/OperationNotAllowed"),
+							Level:   compute.Error,
+							Message: to.StringPtr("Operation results in exceeding quota limits of Core."),
+						},
+						{
+							Code:  to.StringPtr("PowerState/stopped"),
+							Level: compute.Info,
+						},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:            "no error status",
+			expectedMessage: "",
+			expect: func(m *mock_virtualmachines.MockClientMockRecorder) {
+				m.InstanceView(context.TODO(), "my-rg", "my-vm").Return(compute.VirtualMachineInstanceView{}, nil)
+			},
+		},
+		{
+			name:          "instance view fails",
+			expectedError: "failed to get instance view of VM my-vm: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_virtualmachines.MockClientMockRecorder) {
+				m.InstanceView(context.TODO(), "my-rg", "my-vm").
+					Return(compute.VirtualMachineInstanceView{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			vmMock := mock_virtualmachines.NewMockClient(mockCtrl)
+
+			tc.expect(vmMock.EXPECT())
+
+			s := &Service{
+				Scope: &scope.ClusterScope{
+					AzureCluster: &infrav1.AzureCluster{
+						Spec: infrav1.AzureClusterSpec{ResourceGroup: "my-rg"},
+					},
+				},
+				Client: vmMock,
+			}
+
+			message, err := s.GetProvisioningError(context.TODO(), &Spec{Name: "my-vm"})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(message).To(Equal(tc.expectedMessage))
+			}
+		})
+	}
+}
+
+func TestGrowOSDisk(t *testing.T) {
+	testcases := []struct {
+		name          string
+		diskSizeGB    int32
+		expectedError string
+		expect        func(m *mock_disks.MockClientMockRecorder)
+	}{
+		{
+			name:       "grows the OS disk",
+			diskSizeGB: 128,
+			expect: func(m *mock_disks.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vm_OSDisk").Return(compute.Disk{
+					DiskProperties: &compute.DiskProperties{DiskSizeGB: to.Int32Ptr(30)},
+				}, nil)
+				m.Update(context.TODO(), "my-rg", "my-vm_OSDisk", compute.DiskUpdate{
+					DiskUpdateProperties: &compute.DiskUpdateProperties{DiskSizeGB: to.Int32Ptr(128)},
+				}).Return(nil)
+			},
+		},
+		{
+			name:       "OS disk already has the size",
+			diskSizeGB: 30,
+			expect: func(m *mock_disks.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vm_OSDisk").Return(compute.Disk{
+					DiskProperties: &compute.DiskProperties{DiskSizeGB: to.Int32Ptr(30)},
+				}, nil)
+			},
+		},
+		{
+			name:          "never shrinks the OS disk",
+			diskSizeGB:    30,
+			expectedError: "cannot shrink OS disk of VM my-vm from 128GB to 30GB",
+			expect: func(m *mock_disks.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vm_OSDisk").Return(compute.Disk{
+					DiskProperties: &compute.DiskProperties{DiskSizeGB: to.Int32Ptr(128)},
+				}, nil)
+			},
+		},
+		{
+			name:          "update fails",
+			diskSizeGB:    128,
+			expectedError: "failed to grow OS disk of VM my-vm: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_disks.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vm_OSDisk").Return(compute.Disk{
+					DiskProperties: &compute.DiskProperties{DiskSizeGB: to.Int32Ptr(30)},
+				}, nil)
+				m.Update(context.TODO(), "my-rg", "my-vm_OSDisk", gomock.AssignableToTypeOf(compute.DiskUpdate{})).
+					Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			disksMock := mock_disks.NewMockClient(mockCtrl)
+
+			tc.expect(disksMock.EXPECT())
+
+			s := &Service{
+				Scope: &scope.ClusterScope{
+					Logger: klogr.New(),
+					AzureCluster: &infrav1.AzureCluster{
+						Spec: infrav1.AzureClusterSpec{ResourceGroup: "my-rg"},
+					},
+				},
+				DisksClient: disksMock,
+			}
+
+			err := s.GrowOSDisk(context.TODO(), &Spec{Name: "my-vm", OSDisk: infrav1.OSDisk{DiskSizeGB: tc.diskSizeGB}})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestIsSizeAvailable(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	vmMock := mock_virtualmachines.NewMockClient(mockCtrl)
+	vmMock.EXPECT().ListAvailableSizes(context.TODO(), "my-rg", "my-vm").Return([]string{"Standard_D2s_v3", "Standard_D4s_v3"}, nil).Times(2)
+
+	s := &Service{
+		Scope: &scope.ClusterScope{
+			AzureCluster: &infrav1.AzureCluster{
+				Spec: infrav1.AzureClusterSpec{ResourceGroup: "my-rg"},
+			},
+		},
+		Client: vmMock,
+	}
+
+	available, err := s.IsSizeAvailable(context.TODO(), &Spec{Name: "my-vm", Size: "standard_d4s_v3"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(available).To(BeTrue())
+
+	available, err = s.IsSizeAvailable(context.TODO(), &Spec{Name: "my-vm", Size: "Standard_F16s_v2"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(available).To(BeFalse())
+}
+
+func TestInAvailabilitySet(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	vmMock := mock_virtualmachines.NewMockClient(mockCtrl)
+	gomock.InOrder(
+		vmMock.EXPECT().Get(context.TODO(), "my-rg", "my-vm").Return(compute.VirtualMachine{
+			VirtualMachineProperties: &compute.VirtualMachineProperties{
+				AvailabilitySet: &compute.SubResource{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/availabilitySets/my-as")},
+			},
+		}, nil),
+		vmMock.EXPECT().Get(context.TODO(), "my-rg", "my-vm").Return(compute.VirtualMachine{
+			VirtualMachineProperties: &compute.VirtualMachineProperties{},
+		}, nil),
+	)
+
+	s := &Service{
+		Scope: &scope.ClusterScope{
+			AzureCluster: &infrav1.AzureCluster{
+				Spec: infrav1.AzureClusterSpec{ResourceGroup: "my-rg"},
+			},
+		},
+		Client: vmMock,
+	}
+
+	inSet, err := s.InAvailabilitySet(context.TODO(), &Spec{Name: "my-vm"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(inSet).To(BeTrue())
+
+	inSet, err = s.InAvailabilitySet(context.TODO(), &Spec{Name: "my-vm"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(inSet).To(BeFalse())
+}
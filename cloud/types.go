@@ -40,11 +40,44 @@ type NICSpec struct {
 	PublicIPName             string
 	VMSize                   string
 	AcceleratedNetworking    *bool
+	EnableIPForwarding       bool
+	SecondaryIPCount         int32
+}
+
+// AvailabilitySetSpec defines the specification for an availability set.
+type AvailabilitySetSpec struct {
+	Name              string
+	FaultDomainCount  int32
+	UpdateDomainCount int32
+}
+
+// PrivateDNSSpec defines the specification for a private DNS zone and its records.
+type PrivateDNSSpec struct {
+	ZoneName          string
+	ZoneResourceGroup string
+	ManageZone        bool
+	LinkName          string
+	Records           []PrivateDNSRecordSpec
+}
+
+// PrivateDNSRecordSpec defines the specification for an A record of a private DNS zone.
+// When IP is empty, the frontend IP of the internal load balancer named InternalLoadBalancerName is used.
+// The fully qualified name of the record marked APIServer is reported as the private DNS name of the API server.
+type PrivateDNSRecordSpec struct {
+	Hostname                 string
+	IP                       string
+	InternalLoadBalancerName string
+	APIServer                bool
 }
 
 // DiskSpec defines the specification for a Disk.
 type DiskSpec struct {
 	Name string
+	// MachineName and NameSuffix identify the machine and data disk a retained disk belongs to.
+	MachineName string
+	NameSuffix  string
+	// RetainOnDelete keeps the disk when the machine is deleted.
+	RetainOnDelete bool
 }
 
 // LBSpec defines the specification for a Load Balancer.
@@ -92,3 +125,14 @@ type RoleAssignmentSpec struct {
 	MachineName string
 	UUID        string
 }
+
+// VMExtensionSpec defines the specification for a VM extension.
+type VMExtensionSpec struct {
+	Name              string
+	VMName            string
+	Publisher         string
+	Type              string
+	Version           string
+	Settings          map[string]interface{}
+	ProtectedSettings map[string]interface{}
+}
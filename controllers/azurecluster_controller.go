@@ -79,6 +79,7 @@ func (r *AzureClusterReconciler) SetupWithManager(mgr ctrl.Manager, options cont
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azureclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachinetemplates;azuremachinetemplates/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create
 
 func (r *AzureClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
 	ctx, cancel := context.WithTimeout(context.Background(), reconciler.DefaultedLoopTimeout(r.ReconcileTimeout))
@@ -161,6 +162,10 @@ func (r *AzureClusterReconciler) reconcileNormal(ctx context.Context, clusterSco
 		return reconcile.Result{}, err
 	}
 
+	if err := clusterScope.ReconcileSSHKeySecret(ctx); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to reconcile SSH key secret")
+	}
+
 	err := newAzureClusterReconciler(clusterScope).Reconcile(ctx)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to reconcile cluster services")
@@ -172,9 +177,20 @@ func (r *AzureClusterReconciler) reconcileNormal(ctx context.Context, clusterSco
 		return reconcile.Result{RequeueAfter: 15 * time.Second}, nil
 	}
 
-	// Set APIEndpoints so the Cluster API Cluster Controller can pull them
+	// The private DNS name is set once the API server record exists, so that the endpoint does not change later.
+	if azureCluster.Spec.NetworkSpec.PrivateDNSZone != nil && azureCluster.Status.Network.APIServerPrivateDNSName == "" {
+		clusterScope.Info("Waiting for the API server private DNS record to exist")
+		return reconcile.Result{RequeueAfter: 15 * time.Second}, nil
+	}
+
+	// Set APIEndpoints so the Cluster API Cluster Controller can pull them.
+	// The private DNS name, when configured, takes precedence over the public DNS label.
+	host := azureCluster.Status.Network.APIServerIP.DNSName
+	if azureCluster.Status.Network.APIServerPrivateDNSName != "" {
+		host = azureCluster.Status.Network.APIServerPrivateDNSName
+	}
 	azureCluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{
-		Host: azureCluster.Status.Network.APIServerIP.DNSName,
+		Host: host,
 		Port: clusterScope.APIServerPort(),
 	}
 
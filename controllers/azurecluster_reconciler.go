@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/groups"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/loadbalancers"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/privatedns"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicips"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/routetables"
@@ -51,6 +52,7 @@ type azureClusterReconciler struct {
 	subnetsSvc       azure.Service
 	publicIPSvc      azure.Service
 	loadBalancerSvc  azure.Service
+	privateDNSSvc    azure.Service
 	skuCache         *resourceskus.Cache
 }
 
@@ -65,6 +67,7 @@ func newAzureClusterReconciler(scope *scope.ClusterScope) *azureClusterReconcile
 		subnetsSvc:       subnets.NewService(scope),
 		publicIPSvc:      publicips.NewService(scope),
 		loadBalancerSvc:  loadbalancers.NewService(scope),
+		privateDNSSvc:    privatedns.NewService(scope),
 		skuCache:         resourceskus.NewCache(scope, scope.Location()),
 	}
 }
@@ -125,11 +128,19 @@ func (r *azureClusterReconciler) Reconcile(ctx context.Context) error {
 		return errors.Wrapf(err, "failed to reconcile load balancers for cluster %s", r.scope.ClusterName())
 	}
 
+	if err := r.privateDNSSvc.Reconcile(ctx); err != nil {
+		return errors.Wrapf(err, "failed to reconcile private DNS zone for cluster %s", r.scope.ClusterName())
+	}
+
 	return nil
 }
 
 // Delete reconciles all the services in pre determined order
 func (r *azureClusterReconciler) Delete(ctx context.Context) error {
+	if err := r.privateDNSSvc.Delete(ctx); err != nil {
+		return errors.Wrapf(err, "failed to delete private DNS zone for cluster %s", r.scope.ClusterName())
+	}
+
 	if err := r.loadBalancerSvc.Delete(ctx); err != nil {
 		if !azure.ResourceNotFound(err) {
 			return errors.Wrapf(err, "failed to delete load balancers for cluster %s", r.scope.ClusterName())
@@ -211,18 +222,43 @@ func (r *azureClusterReconciler) createOrUpdateNetworkAPIServerIP() error {
 	return nil
 }
 
+// setFailureDomainsForLocation reports the allowed availability zones of the location, or the allowed fault domains of
+// the availability sets in locations without availability zones, as the failure domains of the cluster. The failure
+// domains are rebuilt on each reconciliation, so that domains which are no longer allowed are not reported anymore.
 func (r *azureClusterReconciler) setFailureDomainsForLocation(ctx context.Context) error {
 	zones, err := r.skuCache.GetZones(ctx, r.scope.Location())
 	if err != nil {
 		return errors.Wrapf(err, "failed to get zones for location %s", r.scope.Location())
 	}
 
+	r.scope.ClearFailureDomains()
+
 	for _, zone := range zones {
+		if !r.scope.IsFailureDomainAllowed(zone) {
+			continue
+		}
 		r.scope.SetFailureDomain(zone, clusterv1.FailureDomainSpec{
 			ControlPlane: true,
 		})
 	}
 
+	// Without availability zones, machines are spread across the fault domains of their availability set.
+	if len(zones) == 0 {
+		faultDomainCount := int32(infrav1.DefaultFaultDomainCount)
+		if count := r.scope.AvailabilitySets().FaultDomainCount; count != nil {
+			faultDomainCount = *count
+		}
+		for i := int32(0); i < faultDomainCount; i++ {
+			name := azure.GenerateFaultDomainName(i)
+			if !r.scope.IsFailureDomainAllowed(name) {
+				continue
+			}
+			r.scope.SetFailureDomain(name, clusterv1.FailureDomainSpec{
+				ControlPlane: true,
+			})
+		}
+	}
+
 	return nil
 }
 
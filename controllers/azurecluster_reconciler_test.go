@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestSetFailureDomainsForLocation(t *testing.T) {
+	g := NewWithT(t)
+
+	clusterScope := &scope.ClusterScope{
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				Location: "local",
+			},
+		},
+	}
+	r := &azureClusterReconciler{
+		scope:    clusterScope,
+		skuCache: resourceskus.NewStaticCache([]compute.ResourceSku{}),
+	}
+
+	g.Expect(r.setFailureDomainsForLocation(context.Background())).To(Succeed())
+	g.Expect(clusterScope.FailureDomains()).To(Equal(clusterv1.FailureDomains{
+		"fd-0": clusterv1.FailureDomainSpec{ControlPlane: true},
+		"fd-1": clusterv1.FailureDomainSpec{ControlPlane: true},
+	}))
+
+	// failure domains that are no longer allowed are removed
+	clusterScope.AzureCluster.Spec.AllowedFailureDomains = []string{"fd-1"}
+	g.Expect(r.setFailureDomainsForLocation(context.Background())).To(Succeed())
+	g.Expect(clusterScope.FailureDomains()).To(Equal(clusterv1.FailureDomains{
+		"fd-1": clusterv1.FailureDomainSpec{ControlPlane: true},
+	}))
+}
@@ -19,6 +19,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -41,7 +43,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
 )
 
@@ -51,6 +55,11 @@ type AzureMachineReconciler struct {
 	Log              logr.Logger
 	Recorder         record.EventRecorder
 	ReconcileTimeout time.Duration
+	// MaxVMProvisioningAttempts is the number of times the VM of a machine is created before the machine is marked
+	// as failed.
+	MaxVMProvisioningAttempts int
+	// ImageCatalog holds the default images of the machines that don't specify one.
+	ImageCatalog *azure.ImageCatalog
 }
 
 func (r *AzureMachineReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -104,9 +113,10 @@ func (r *AzureMachineReconciler) SetupWithManager(mgr ctrl.Manager, options cont
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachinetemplates,verbs=get;update
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
-// +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch;create;update;patch
 
 func (r *AzureMachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
 	ctx, cancel := context.WithTimeout(context.Background(), reconciler.DefaultedLoopTimeout(r.ReconcileTimeout))
@@ -195,6 +205,8 @@ func (r *AzureMachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, ret
 		conditions.SetSummary(machineScope.AzureMachine,
 			conditions.WithConditions(
 				infrav1.VMRunningCondition,
+				infrav1.BootstrapSucceededCondition,
+				infrav1.InPlaceUpdatedCondition,
 			),
 		)
 
@@ -241,6 +253,11 @@ func (r *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineSco
 		return reconcile.Result{}, err
 	}
 
+	if _, ok := machineScope.AzureMachine.Annotations[infrav1.ResetVMProvisioningAnnotation]; ok {
+		machineScope.Info("Resetting failed VM provisioning attempts", "attempts", machineScope.VMProvisioningFailedAttempts())
+		machineScope.ResetVMProvisioning()
+	}
+
 	if !clusterScope.Cluster.Status.InfrastructureReady {
 		machineScope.Info("Cluster infrastructure is not ready yet")
 		conditions.MarkFalse(machineScope.AzureMachine, infrav1.VMRunningCondition, infrav1.WaitingForClusterInfrastructureReason, clusterv1.ConditionSeverityInfo, "")
@@ -266,7 +283,27 @@ func (r *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineSco
 		}
 	}
 
-	ams := newAzureMachineService(machineScope, clusterScope)
+	// Default the SSH public key to the key generated for the cluster.
+	if err := machineScope.SetDefaultSSHPublicKey(ctx); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to set default SSH public key")
+	}
+
+	if err := r.claimStaticIPAddress(ctx, machineScope, networkinterfaces.NewClient(clusterScope)); err != nil {
+		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, infrav1.StaticIPAddressUnavailableReason, err.Error())
+		conditions.MarkFalse(machineScope.AzureMachine, infrav1.VMRunningCondition, infrav1.StaticIPAddressUnavailableReason, clusterv1.ConditionSeverityError, err.Error())
+		return reconcile.Result{}, err
+	}
+
+	// Wait for the backoff following a failed provisioning before creating the virtual machine again.
+	if backoff := machineScope.VMProvisioningBackoff(); backoff > 0 {
+		machineScope.Info("Waiting before creating the VM again after a failed provisioning", "backoff", backoff)
+		conditions.MarkFalse(machineScope.AzureMachine, infrav1.VMRunningCondition, infrav1.VMProvisionBackoffReason, clusterv1.ConditionSeverityWarning,
+			"VM failed to provision %d times, last error: %s", machineScope.VMProvisioningFailedAttempts(), machineScope.VMProvisioningLastError())
+		machineScope.SetNotReady()
+		return reconcile.Result{RequeueAfter: backoff}, nil
+	}
+
+	ams := newAzureMachineService(machineScope, clusterScope, r.ImageCatalog)
 
 	// Get or create the virtual machine.
 	vm, err := r.getOrCreate(ctx, machineScope, ams)
@@ -274,21 +311,67 @@ func (r *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineSco
 		return reconcile.Result{}, err
 	}
 
+	// VMs that existed before the SSH public keys hash was recorded are assumed to have the keys of the spec, so that
+	// the VM access extension does not replace their authorized keys.
+	if machineScope.SSHPublicKeysHash() == "" {
+		sshKeys, err := machineScope.GetSSHPublicKeys(ctx)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to get SSH public keys")
+		}
+		machineScope.SetSSHPublicKeysHash(azure.GenerateSSHPublicKeysHash(sshKeys))
+	}
+
 	// Make sure Spec.ProviderID is always set.
 	machineScope.SetProviderID(fmt.Sprintf("azure:///%s", vm.ID))
 
 	machineScope.SetAnnotation("cluster-api-provider-azure", "true")
 
 	machineScope.SetAddresses(vm.Addresses)
+	machineScope.SetSecondaryIPAddresses(vm.SecondaryIPAddresses)
+
+	if err := ams.ReconcilePrivateDNS(ctx); err != nil {
+		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "Error creating private DNS record", err.Error())
+		return reconcile.Result{}, err
+	}
 
 	// Proceed to reconcile the AzureMachine state.
 	machineScope.SetVMState(vm.State)
+	machineScope.SetVMPowerState(vm.PowerState, vm.VMAgentStatus)
 
 	switch vm.State {
 	case infrav1.VMStateSucceeded:
+		running, err := r.reconcilePowerState(ctx, machineScope, ams, vm)
+		if err != nil {
+			machineScope.SetNotReady()
+			return reconcile.Result{}, err
+		}
+		if !running {
+			machineScope.SetNotReady()
+			break
+		}
+		if err := r.reconcileInPlaceUpdate(ctx, machineScope, ams, vm); err != nil {
+			machineScope.SetNotReady()
+			return reconcile.Result{}, err
+		}
+		extErr := ams.ReconcileVMExtensions(ctx)
+		bootstrapped := true
+		if machineScope.VerifyBootstrap() {
+			bootstrapped = r.reconcileBootstrapCheck(machineScope)
+			if machineScope.AzureMachine.Status.FailureReason != nil {
+				r.recordSerialConsoleLog(ctx, machineScope, ams)
+			}
+		}
+		if extErr != nil {
+			r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "Error reconciling VM extensions", extErr.Error())
+			return reconcile.Result{}, extErr
+		}
 		machineScope.V(2).Info("VM is running", "id", *machineScope.GetVMID())
 		conditions.MarkTrue(machineScope.AzureMachine, infrav1.VMRunningCondition)
-		machineScope.SetReady()
+		if bootstrapped {
+			machineScope.SetReady()
+		} else {
+			machineScope.SetNotReady()
+		}
 	case infrav1.VMStateCreating:
 		machineScope.V(2).Info("VM is creating", "id", *machineScope.GetVMID())
 		conditions.MarkFalse(machineScope.AzureMachine, infrav1.VMRunningCondition, infrav1.VMNCreatingReason, clusterv1.ConditionSeverityInfo, "")
@@ -306,6 +389,7 @@ func (r *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineSco
 		machineScope.SetNotReady()
 		machineScope.Error(errors.New("Failed to create or update VM"), "VM is in failed state", "id", *machineScope.GetVMID())
 		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "FailedVMState", "Azure VM is in failed state")
+		r.recordSerialConsoleLog(ctx, machineScope, ams)
 		machineScope.SetFailureReason(capierrors.UpdateMachineError)
 		machineScope.SetFailureMessage(errors.Errorf("Azure VM state is %s", vm.State))
 		conditions.MarkFalse(machineScope.AzureMachine, infrav1.VMRunningCondition, infrav1.VMProvisionFailedReason, clusterv1.ConditionSeverityWarning, "")
@@ -328,6 +412,96 @@ func (r *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineSco
 	return reconcile.Result{}, nil
 }
 
+// reconcilePowerState reports whether the VM of a machine is running. A VM found stopped or deallocated, e.g. from
+// the portal, is started again with the Start power state policy, and otherwise left as it is. A VM deallocated by an
+// unfinished in-place update is considered running, as the update starts it again.
+func (r *AzureMachineReconciler) reconcilePowerState(ctx context.Context, machineScope *scope.MachineScope, ams *azureMachineService, vm *infrav1.VM) (bool, error) {
+	switch vm.PowerState {
+	case "", infrav1.VMPowerStateRunning:
+		return true, nil
+	}
+	if conditions.IsFalse(machineScope.AzureMachine, infrav1.InPlaceUpdatedCondition) {
+		return true, nil
+	}
+
+	stopped := vm.PowerState == infrav1.VMPowerStateStopped || vm.PowerState == infrav1.VMPowerStateDeallocated
+	if stopped && machineScope.AzureMachine.Spec.PowerStatePolicy == infrav1.PowerStatePolicyStart {
+		machineScope.Info("Starting VM", "powerState", vm.PowerState, "id", *machineScope.GetVMID())
+		if err := ams.StartVM(ctx); err != nil {
+			r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "VMStartFailed", "Failed to start Azure VM: %s", err.Error())
+			conditions.MarkFalse(machineScope.AzureMachine, infrav1.VMRunningCondition, infrav1.VMStoppedReason, clusterv1.ConditionSeverityError, "failed to start VM: %s", err.Error())
+			return false, errors.Wrap(err, "failed to start VM")
+		}
+		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeNormal, "VMStarted", "Azure VM was %s and has been started", strings.ToLower(string(vm.PowerState)))
+		machineScope.SetVMPowerState(infrav1.VMPowerStateRunning, vm.VMAgentStatus)
+		return true, nil
+	}
+
+	machineScope.Info("VM is not running", "powerState", vm.PowerState, "id", *machineScope.GetVMID())
+	conditions.MarkFalse(machineScope.AzureMachine, infrav1.VMRunningCondition, infrav1.VMStoppedReason, clusterv1.ConditionSeverityWarning, "VM power state is %s", vm.PowerState)
+	return false, nil
+}
+
+// reconcileInPlaceUpdate applies the changes to the VM size and the OS disk size of a machine with the InPlace update
+// policy to its existing VM. The update takes several minutes, so each step is reported in the InPlaceUpdated
+// condition and persisted before it starts; an update left unfinished, e.g. by a restart of the controller, is resumed
+// and its VM started again.
+func (r *AzureMachineReconciler) reconcileInPlaceUpdate(ctx context.Context, machineScope *scope.MachineScope, ams *azureMachineService, vm *infrav1.VM) error {
+	resume := conditions.IsFalse(machineScope.AzureMachine, infrav1.InPlaceUpdatedCondition)
+	if machineScope.AzureMachine.Spec.UpdatePolicy != infrav1.UpdatePolicyInPlace && !resume {
+		return nil
+	}
+
+	updated, err := ams.UpdateInPlace(ctx, vm, resume, func(reason string) error {
+		machineScope.Info("Updating VM in place", "step", reason)
+		conditions.MarkFalse(machineScope.AzureMachine, infrav1.InPlaceUpdatedCondition, reason, clusterv1.ConditionSeverityInfo, "")
+		return machineScope.PatchObject(ctx)
+	})
+	if err != nil {
+		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "InPlaceUpdateFailed", "In-place update of the Azure VM failed: %s", err.Error())
+		conditions.MarkFalse(machineScope.AzureMachine, infrav1.InPlaceUpdatedCondition, infrav1.InPlaceUpdateFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return errors.Wrap(err, "failed to update VM in place")
+	}
+	if updated {
+		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeNormal, "VMUpdated", "Azure VM updated in place to size %s", machineScope.AzureMachine.Spec.VMSize)
+		conditions.MarkTrue(machineScope.AzureMachine, infrav1.InPlaceUpdatedCondition)
+	}
+	return nil
+}
+
+// reconcileBootstrapCheck reports the result of the VM extension verifying the bootstrap of the machine, and returns
+// true if the bootstrap succeeded. A failed bootstrap is terminal so that the machine can be remediated.
+func (r *AzureMachineReconciler) reconcileBootstrapCheck(machineScope *scope.MachineScope) bool {
+	status := machineScope.BootstrapCheckStatus()
+	switch {
+	case status != nil && status.ProvisioningState == string(infrav1.VMStateSucceeded):
+		conditions.MarkTrue(machineScope.AzureMachine, infrav1.BootstrapSucceededCondition)
+		return true
+	case status != nil && status.ProvisioningState == string(infrav1.VMStateFailed):
+		machineScope.Info("Bootstrap of the machine failed", "output", status.Message)
+		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "BootstrapFailed", "Bootstrap of the machine failed: %s", status.Message)
+		conditions.MarkFalse(machineScope.AzureMachine, infrav1.BootstrapSucceededCondition, infrav1.BootstrapFailedReason, clusterv1.ConditionSeverityError, status.Message)
+		machineScope.SetFailureReason(capierrors.CreateMachineError)
+		machineScope.SetFailureMessage(errors.New("bootstrap of the machine failed, see the BootstrapFailed event for its output"))
+	default:
+		conditions.MarkFalse(machineScope.AzureMachine, infrav1.BootstrapSucceededCondition, infrav1.BootstrapInProgressReason, clusterv1.ConditionSeverityInfo, "")
+	}
+	return false
+}
+
+// recordSerialConsoleLog stores the serial console log of a failed machine in a Secret, if its boot diagnostics are
+// enabled, and records an event referencing the Secret.
+func (r *AzureMachineReconciler) recordSerialConsoleLog(ctx context.Context, machineScope *scope.MachineScope, ams *azureMachineService) {
+	secretName, err := ams.CaptureSerialConsoleLog(ctx)
+	if err != nil {
+		machineScope.Error(err, "failed to capture serial console log")
+		return
+	}
+	if secretName != "" {
+		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "SerialConsoleLogCaptured", "Serial console log of the Azure VM stored in secret %s", secretName)
+	}
+}
+
 func (r *AzureMachineReconciler) getOrCreate(ctx context.Context, scope *scope.MachineScope, ams *azureMachineService) (*infrav1.VM, error) {
 	vm, err := r.findVM(ctx, scope, ams)
 	if err != nil {
@@ -340,8 +514,23 @@ func (r *AzureMachineReconciler) getOrCreate(ctx context.Context, scope *scope.M
 		// Create a new VM if we couldn't find a running VM.
 		vm, err = ams.Reconcile(ctx)
 		if err != nil {
+			if attempts := scope.VMProvisioningFailedAttempts(); int(attempts) >= reconciler.DefaultedMaxVMProvisioningAttempts(r.MaxVMProvisioningAttempts) {
+				// The VM keeps failing to provision, e.g. for lack of quota or capacity. Stop creating it so that the
+				// machine can be remediated.
+				message := fmt.Sprintf("Azure VM failed to provision %d times, last error: %s", attempts, scope.VMProvisioningLastError())
+				r.Recorder.Eventf(scope.AzureMachine, corev1.EventTypeWarning, "VMProvisioningAttemptsExceeded", message)
+				conditions.MarkFalse(scope.AzureMachine, infrav1.VMRunningCondition, infrav1.VMProvisionFailedReason, clusterv1.ConditionSeverityError, message)
+				scope.SetNotReady()
+				scope.SetFailureReason(capierrors.CreateMachineError)
+				scope.SetFailureMessage(errors.New(message))
+				return nil, errors.Wrapf(err, "failed to reconcile AzureMachine")
+			}
+			reason := infrav1.VMProvisionFailedReason
+			if errors.As(err, &zonePlacementError{}) {
+				reason = infrav1.ZoneUnavailableReason
+			}
 			r.Recorder.Eventf(scope.AzureMachine, corev1.EventTypeWarning, "Error creating new AzureMachine", errors.Wrapf(err, "failed to reconcile AzureMachine").Error())
-			conditions.MarkFalse(scope.AzureMachine, infrav1.VMRunningCondition, infrav1.VMProvisionFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			conditions.MarkFalse(scope.AzureMachine, infrav1.VMRunningCondition, reason, clusterv1.ConditionSeverityError, err.Error())
 			return nil, errors.Wrapf(err, "failed to reconcile AzureMachine")
 		}
 	}
@@ -352,7 +541,7 @@ func (r *AzureMachineReconciler) getOrCreate(ctx context.Context, scope *scope.M
 func (r *AzureMachineReconciler) reconcileDelete(ctx context.Context, machineScope *scope.MachineScope, clusterScope *scope.ClusterScope) (_ reconcile.Result, reterr error) {
 	machineScope.Info("Handling deleted AzureMachine")
 
-	if err := newAzureMachineService(machineScope, clusterScope).Delete(ctx); err != nil {
+	if err := newAzureMachineService(machineScope, clusterScope, r.ImageCatalog).Delete(ctx); err != nil {
 		r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "Error deleting AzureCluster", errors.Wrapf(err, "error deleting AzureCluster %s/%s", clusterScope.Namespace(), clusterScope.ClusterName()).Error())
 		return reconcile.Result{}, errors.Wrapf(err, "error deleting AzureCluster %s/%s", clusterScope.Namespace(), clusterScope.ClusterName())
 	}
@@ -364,5 +553,122 @@ func (r *AzureMachineReconciler) reconcileDelete(ctx context.Context, machineSco
 		}
 	}()
 
+	// The network interfaces are deleted so the static IP address can be claimed by another machine.
+	releaseStaticIPAddress(machineScope)
+
 	return reconcile.Result{}, nil
 }
+
+// staticIPAddressClaims tracks the static IP addresses claimed by this controller, keyed by cluster and address,
+// so that concurrent reconciles do not hand out the same address before the claim is visible in the cache. The claims
+// that outlive the process are the status of the AzureMachines and the network interfaces of the cluster.
+var staticIPAddressClaims = struct {
+	sync.Mutex
+	claims map[string]string
+}{claims: map[string]string{}}
+
+func staticIPAddressClaimKey(machineScope *scope.MachineScope, address string) string {
+	return fmt.Sprintf("%s/%s/%s", machineScope.Namespace(), machineScope.ClusterName(), address)
+}
+
+// claimStaticIPAddress assigns a free address of the machine's static IP address pool to the AzureMachine. An address
+// is free if it is neither claimed by another AzureMachine of the cluster nor assigned to a network interface in the
+// resource group of the cluster or of its virtual network. If the network interface of the machine already has an
+// address of the pool, e.g. because the status of the AzureMachine was lost, that address is claimed again.
+func (r *AzureMachineReconciler) claimStaticIPAddress(ctx context.Context, machineScope *scope.MachineScope, nicClient networkinterfaces.Client) error {
+	pool := machineScope.StaticIPAddressPool()
+	if len(pool) == 0 || machineScope.AzureMachine.Status.StaticIPAddress != "" {
+		return nil
+	}
+
+	// Look up the addresses in use before taking the lock, so that reconciles of other clusters are not held up by
+	// calls to Azure. Addresses claimed in the meantime are still seen in staticIPAddressClaims.
+	claimed, err := GetClaimedStaticIPAddresses(ctx, r.Client, machineScope.Namespace(), machineScope.ClusterName(), machineScope.Name())
+	if err != nil {
+		return err
+	}
+	inUse, err := GetPrivateIPAddressesInUse(ctx, nicClient, machineScope.ResourceGroup())
+	if err != nil {
+		return err
+	}
+	if vnetResourceGroup := machineScope.Vnet().ResourceGroup; vnetResourceGroup != "" && vnetResourceGroup != machineScope.ResourceGroup() {
+		vnetInUse, err := GetPrivateIPAddressesInUse(ctx, nicClient, vnetResourceGroup)
+		if err != nil {
+			return err
+		}
+		for address, nicName := range vnetInUse {
+			if _, ok := inUse[address]; !ok {
+				inUse[address] = nicName
+			}
+		}
+	}
+
+	address, err := pickStaticIPAddress(machineScope, pool, claimed, inUse)
+	if err != nil {
+		return err
+	}
+
+	machineScope.SetStaticIPAddress(address)
+	// Persist the claim right away so that other machines see it, and give the address back if it is not persisted.
+	if err := machineScope.PatchObject(ctx); err != nil {
+		staticIPAddressClaims.Lock()
+		delete(staticIPAddressClaims.claims, staticIPAddressClaimKey(machineScope, address))
+		staticIPAddressClaims.Unlock()
+		machineScope.SetStaticIPAddress("")
+		return errors.Wrapf(err, "failed to persist claim of static IP address %s", address)
+	}
+	machineScope.Info("Claimed static IP address", "address", address)
+	return nil
+}
+
+// pickStaticIPAddress picks the address of the pool already assigned to the network interface of the machine, or else
+// the first address neither claimed nor in use, and records the claim of the machine in staticIPAddressClaims.
+func pickStaticIPAddress(machineScope *scope.MachineScope, pool []string, claimed map[string]struct{}, inUse map[string]string) (string, error) {
+	staticIPAddressClaims.Lock()
+	defer staticIPAddressClaims.Unlock()
+
+	address := ""
+	nicName := azure.GenerateNICName(machineScope.Name())
+	for _, candidate := range pool {
+		if inUse[candidate] == nicName {
+			address = candidate
+			break
+		}
+	}
+	if address == "" {
+		for _, candidate := range pool {
+			if _, ok := claimed[candidate]; ok {
+				continue
+			}
+			if _, ok := inUse[candidate]; ok {
+				continue
+			}
+			if owner, ok := staticIPAddressClaims.claims[staticIPAddressClaimKey(machineScope, candidate)]; ok && owner != machineScope.Name() {
+				continue
+			}
+			address = candidate
+			break
+		}
+	}
+	if address == "" {
+		return "", errors.Errorf("no free static IP address left in pool %v", pool)
+	}
+
+	staticIPAddressClaims.claims[staticIPAddressClaimKey(machineScope, address)] = machineScope.Name()
+	return address, nil
+}
+
+// releaseStaticIPAddress releases the static IP address claimed by the AzureMachine, if any.
+func releaseStaticIPAddress(machineScope *scope.MachineScope) {
+	address := machineScope.AzureMachine.Status.StaticIPAddress
+	if address == "" {
+		return
+	}
+
+	staticIPAddressClaims.Lock()
+	defer staticIPAddressClaims.Unlock()
+
+	delete(staticIPAddressClaims.claims, staticIPAddressClaimKey(machineScope, address))
+	machineScope.SetStaticIPAddress("")
+	machineScope.Info("Released static IP address", "address", address)
+}
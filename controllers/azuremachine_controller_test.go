@@ -22,20 +22,28 @@ import (
 	"time"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	kuberecord "k8s.io/client-go/tools/record"
 	"k8s.io/klog/klogr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachines"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachines/mock_virtualmachines"
 	"sigs.k8s.io/cluster-api-provider-azure/internal/test"
 	"sigs.k8s.io/cluster-api-provider-azure/internal/test/record"
 )
@@ -322,6 +330,199 @@ func TestConditions(t *testing.T) {
 
 }
 
+func TestReconcileBootstrapCheck(t *testing.T) {
+	testcases := []struct {
+		name                  string
+		statuses              []infrav1.VMExtensionStatus
+		expectedBootstrapped  bool
+		expectedCondition     clusterv1.Condition
+		expectedFailureReason *capierrors.MachineStatusError
+	}{
+		{
+			name:                 "bootstrap check not installed yet",
+			statuses:             nil,
+			expectedBootstrapped: false,
+			expectedCondition: clusterv1.Condition{
+				Type:     infrav1.BootstrapSucceededCondition,
+				Status:   v1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityInfo,
+				Reason:   infrav1.BootstrapInProgressReason,
+			},
+		},
+		{
+			name:                 "bootstrap succeeded",
+			statuses:             []infrav1.VMExtensionStatus{{Name: azure.BootstrapCheckExtensionName, ProvisioningState: "Succeeded"}},
+			expectedBootstrapped: true,
+			expectedCondition: clusterv1.Condition{
+				Type:   infrav1.BootstrapSucceededCondition,
+				Status: v1.ConditionTrue,
+			},
+		},
+		{
+			name:                 "bootstrap failed",
+			statuses:             []infrav1.VMExtensionStatus{{Name: azure.BootstrapCheckExtensionName, ProvisioningState: "Failed", Message: "kubeadm join failed"}},
+			expectedBootstrapped: false,
+			expectedCondition: clusterv1.Condition{
+				Type:     infrav1.BootstrapSucceededCondition,
+				Status:   v1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityError,
+				Reason:   infrav1.BootstrapFailedReason,
+			},
+			expectedFailureReason: func() *capierrors.MachineStatusError { e := capierrors.CreateMachineError; return &e }(),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			azureMachine := &infrav1.AzureMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-machine"},
+				Spec:       infrav1.AzureMachineSpec{VerifyBootstrap: true},
+				Status:     infrav1.AzureMachineStatus{VMExtensions: tc.statuses},
+			}
+			machineScope, err := scope.NewMachineScope(scope.MachineScopeParams{
+				Client:       fake.NewFakeClientWithScheme(setupScheme(g), azureMachine),
+				Machine:      &clusterv1.Machine{},
+				AzureMachine: azureMachine,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			recorder := kuberecord.NewFakeRecorder(1)
+			reconciler := &AzureMachineReconciler{
+				Log:      klogr.New(),
+				Recorder: recorder,
+			}
+
+			g.Expect(reconciler.reconcileBootstrapCheck(machineScope)).To(Equal(tc.expectedBootstrapped))
+			conditions := machineScope.AzureMachine.GetConditions()
+			g.Expect(conditions).To(HaveLen(1))
+			g.Expect(conditionsMatch(conditions[0], tc.expectedCondition)).To(BeTrue())
+			g.Expect(machineScope.AzureMachine.Status.FailureReason).To(Equal(tc.expectedFailureReason))
+			if tc.expectedFailureReason != nil {
+				g.Expect(recorder.Events).To(Receive(ContainSubstring("kubeadm join failed")))
+			}
+		})
+	}
+}
+
+func TestReconcilePowerState(t *testing.T) {
+	testcases := []struct {
+		name               string
+		powerState         infrav1.VMPowerState
+		policy             infrav1.PowerStatePolicy
+		inPlaceUpdate      bool
+		expectStart        bool
+		expectedRunning    bool
+		expectedPowerState infrav1.VMPowerState
+		expectedConditions []clusterv1.Condition
+	}{
+		{
+			name:               "running VM",
+			powerState:         infrav1.VMPowerStateRunning,
+			expectedRunning:    true,
+			expectedPowerState: infrav1.VMPowerStateRunning,
+		},
+		{
+			name:               "deallocated VM is left alone by default",
+			powerState:         infrav1.VMPowerStateDeallocated,
+			expectedRunning:    false,
+			expectedPowerState: infrav1.VMPowerStateDeallocated,
+			expectedConditions: []clusterv1.Condition{{
+				Type:     infrav1.VMRunningCondition,
+				Status:   v1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityWarning,
+				Reason:   infrav1.VMStoppedReason,
+			}},
+		},
+		{
+			name:               "stopped VM is started with the Start policy",
+			powerState:         infrav1.VMPowerStateStopped,
+			policy:             infrav1.PowerStatePolicyStart,
+			expectStart:        true,
+			expectedRunning:    true,
+			expectedPowerState: infrav1.VMPowerStateRunning,
+		},
+		{
+			name:               "stopping VM is not started with the Start policy",
+			powerState:         infrav1.VMPowerStateStopping,
+			policy:             infrav1.PowerStatePolicyStart,
+			expectedRunning:    false,
+			expectedPowerState: infrav1.VMPowerStateStopping,
+			expectedConditions: []clusterv1.Condition{{
+				Type:     infrav1.VMRunningCondition,
+				Status:   v1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityWarning,
+				Reason:   infrav1.VMStoppedReason,
+			}},
+		},
+		{
+			name:               "VM deallocated by an in-place update is left to the update",
+			powerState:         infrav1.VMPowerStateDeallocated,
+			inPlaceUpdate:      true,
+			expectedRunning:    true,
+			expectedPowerState: infrav1.VMPowerStateDeallocated,
+			expectedConditions: []clusterv1.Condition{{
+				Type:     infrav1.InPlaceUpdatedCondition,
+				Status:   v1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityInfo,
+				Reason:   infrav1.VMResizingReason,
+			}},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			azureMachine := &infrav1.AzureMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-machine"},
+				Spec: infrav1.AzureMachineSpec{
+					ProviderID:       to.StringPtr("azure:///subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachines/my-machine"),
+					PowerStatePolicy: tc.policy,
+				},
+			}
+			if tc.inPlaceUpdate {
+				conditions.MarkFalse(azureMachine, infrav1.InPlaceUpdatedCondition, infrav1.VMResizingReason, clusterv1.ConditionSeverityInfo, "")
+			}
+			machineScope, err := scope.NewMachineScope(scope.MachineScopeParams{
+				Client:       fake.NewFakeClientWithScheme(setupScheme(g), azureMachine),
+				Machine:      &clusterv1.Machine{},
+				AzureMachine: azureMachine,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			machineScope.SetVMPowerState(tc.powerState, "Ready")
+
+			vmClient := mock_virtualmachines.NewMockClient(mockCtrl)
+			if tc.expectStart {
+				vmClient.EXPECT().Start(gomock.Any(), "my-rg", "my-machine").Return(nil)
+			}
+			ams := &azureMachineService{
+				machineScope: machineScope,
+				virtualMachinesSvc: &virtualmachines.Service{
+					Scope: &scope.ClusterScope{
+						Logger:       klogr.New(),
+						AzureCluster: &infrav1.AzureCluster{Spec: infrav1.AzureClusterSpec{ResourceGroup: "my-rg"}},
+					},
+					Client: vmClient,
+				},
+			}
+			reconciler := &AzureMachineReconciler{
+				Log:      klogr.New(),
+				Recorder: kuberecord.NewFakeRecorder(1),
+			}
+
+			running, err := reconciler.reconcilePowerState(context.TODO(), machineScope, ams, &infrav1.VM{PowerState: tc.powerState, VMAgentStatus: "Ready"})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(running).To(Equal(tc.expectedRunning))
+			g.Expect(machineScope.AzureMachine.Status.PowerState).To(Equal(tc.expectedPowerState))
+			g.Expect(machineScope.AzureMachine.GetConditions()).To(HaveLen(len(tc.expectedConditions)))
+			for i, c := range machineScope.AzureMachine.GetConditions() {
+				g.Expect(conditionsMatch(c, tc.expectedConditions[i])).To(BeTrue())
+			}
+		})
+	}
+}
+
 func conditionsMatch(i, j clusterv1.Condition) bool {
 	return i.Type == j.Type &&
 		i.Status == j.Status &&
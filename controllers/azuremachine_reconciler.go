@@ -18,7 +18,9 @@ package controllers
 
 import (
 	"context"
-	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/inboundnatrules"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
@@ -30,9 +32,14 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/availabilitysets"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/bootdiagnostics"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/privatedns"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicips"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachineextensions"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachineimages"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachines"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
@@ -48,11 +55,17 @@ type azureMachineService struct {
 	roleAssignmentsSvc   azure.Service
 	disksSvc             azure.Service
 	publicIPsSvc         azure.Service
+	privateDNSSvc        azure.Service
+	availabilitySetsSvc  azure.Service
+	vmExtensionsSvc      azure.Service
+	bootDiagnosticsSvc   *bootdiagnostics.Service
+	vmImagesSvc          *virtualmachineimages.Service
 	skuCache             *resourceskus.Cache
+	imageCatalog         *azure.ImageCatalog
 }
 
 // newAzureMachineService populates all the services based on input scope
-func newAzureMachineService(machineScope *scope.MachineScope, clusterScope *scope.ClusterScope) *azureMachineService {
+func newAzureMachineService(machineScope *scope.MachineScope, clusterScope *scope.ClusterScope, imageCatalog *azure.ImageCatalog) *azureMachineService {
 	cache := resourceskus.NewCache(clusterScope, clusterScope.Location())
 
 	return &azureMachineService{
@@ -64,7 +77,13 @@ func newAzureMachineService(machineScope *scope.MachineScope, clusterScope *scop
 		roleAssignmentsSvc:   roleassignments.NewService(machineScope),
 		disksSvc:             disks.NewService(machineScope),
 		publicIPsSvc:         publicips.NewService(machineScope),
+		privateDNSSvc:        privatedns.NewService(machineScope),
+		availabilitySetsSvc:  availabilitysets.NewService(machineScope),
+		vmExtensionsSvc:      virtualmachineextensions.NewService(machineScope),
+		bootDiagnosticsSvc:   bootdiagnostics.NewService(machineScope),
+		vmImagesSvc:          virtualmachineimages.NewService(machineScope),
 		skuCache:             cache,
+		imageCatalog:         imageCatalog,
 	}
 }
 
@@ -85,7 +104,13 @@ func (s *azureMachineService) Reconcile(ctx context.Context) (*infrav1.VM, error
 		return nil, errors.Wrap(err, "unable to create VM network interface")
 	}
 
-	vm, vmErr := s.reconcileVirtualMachine(ctx, azure.GenerateNICName(s.machineScope.Name()))
+	// NICs are attached in the order of the NIC specs, the first one being the primary NIC.
+	nicNames := []string{}
+	for _, nicSpec := range s.machineScope.NICSpecs() {
+		nicNames = append(nicNames, nicSpec.Name)
+	}
+
+	vm, vmErr := s.reconcileVirtualMachine(ctx, nicNames)
 	if vmErr != nil {
 		return nil, errors.Wrapf(vmErr, "failed to create VM %s ", s.machineScope.Name())
 	}
@@ -98,13 +123,135 @@ func (s *azureMachineService) Reconcile(ctx context.Context) (*infrav1.VM, error
 	return vm, nil
 }
 
+// ReconcilePrivateDNS creates or updates the private DNS record of the machine from its addresses.
+func (s *azureMachineService) ReconcilePrivateDNS(ctx context.Context) error {
+	return errors.Wrap(s.privateDNSSvc.Reconcile(ctx), "unable to create private DNS record")
+}
+
+// ReconcileVMExtensions installs, updates and removes the VM extensions of the machine once its VM is running.
+func (s *azureMachineService) ReconcileVMExtensions(ctx context.Context) error {
+	return errors.Wrap(s.vmExtensionsSvc.Reconcile(ctx), "unable to reconcile VM extensions")
+}
+
+// CaptureSerialConsoleLog stores the end of the serial console log of the machine in a Secret and returns the name
+// of the Secret, or an empty string if the boot diagnostics of the machine are not enabled.
+func (s *azureMachineService) CaptureSerialConsoleLog(ctx context.Context) (string, error) {
+	log, err := s.bootDiagnosticsSvc.GetSerialConsoleLog(ctx, s.machineScope.Name())
+	if err != nil || log == nil {
+		return "", err
+	}
+	return s.machineScope.StoreSerialConsoleLog(ctx, log)
+}
+
+// UpdateInPlace resizes the VM and grows the OS disk of the machine to match its spec. The VM is deallocated first
+// when its OS disk is grown or the new VM size is not available on its current hardware, and started again
+// afterwards, also when the resize or the growth fails. A VM in an availability set cannot be moved to other hardware
+// on its own, so such a resize fails before the VM is deallocated. progress is called before each step; resume
+// restarts the VM of an update that was interrupted after it was deallocated. UpdateInPlace returns whether the VM was
+// updated.
+func (s *azureMachineService) UpdateInPlace(ctx context.Context, vm *infrav1.VM, resume bool, progress func(reason string) error) (bool, error) {
+	vmSpec := &virtualmachines.Spec{
+		Name:   s.machineScope.Name(),
+		Size:   s.machineScope.AzureMachine.Spec.VMSize,
+		OSDisk: s.machineScope.AzureMachine.Spec.OSDisk,
+	}
+
+	diskSizeGB, err := s.virtualMachinesSvc.GetOSDiskSizeGB(ctx, vmSpec)
+	if err != nil {
+		return false, err
+	}
+	resize := !strings.EqualFold(vm.VMSize, vmSpec.Size)
+	grow := vmSpec.OSDisk.DiskSizeGB > diskSizeGB
+	if !resize && !grow && !resume {
+		return false, nil
+	}
+
+	deallocate := grow
+	if resize {
+		available, err := s.virtualMachinesSvc.IsSizeAvailable(ctx, vmSpec)
+		if err != nil {
+			return false, err
+		}
+		if !available {
+			// Azure only moves a VM in an availability set to hardware with the new size when every VM of the set is
+			// deallocated, so deallocating this VM alone would leave it stopped.
+			inAvailabilitySet, err := s.virtualMachinesSvc.InAvailabilitySet(ctx, vmSpec)
+			if err != nil {
+				return false, err
+			}
+			if inAvailabilitySet {
+				return false, errors.Errorf("VM size %s is not available on the hardware of the availability set of VM %s", vmSpec.Size, vmSpec.Name)
+			}
+			deallocate = true
+		}
+	}
+
+	if deallocate {
+		if err := progress(infrav1.VMDeallocatingReason); err != nil {
+			return false, err
+		}
+		if err := s.virtualMachinesSvc.Deallocate(ctx, vmSpec); err != nil {
+			return false, err
+		}
+	}
+	if err := s.resizeAndGrow(ctx, vmSpec, resize, grow, progress); err != nil {
+		if deallocate || resume {
+			// start the VM again rather than leaving it deallocated until the update succeeds
+			if startErr := s.virtualMachinesSvc.Start(ctx, vmSpec); startErr != nil {
+				return false, errors.Wrapf(err, "failed to start VM again: %s", startErr.Error())
+			}
+		}
+		return false, err
+	}
+	if deallocate || resume {
+		if err := progress(infrav1.VMStartingReason); err != nil {
+			return false, err
+		}
+		if err := s.virtualMachinesSvc.Start(ctx, vmSpec); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// resizeAndGrow resizes the VM and grows its OS disk, as requested, calling progress before each step.
+func (s *azureMachineService) resizeAndGrow(ctx context.Context, vmSpec *virtualmachines.Spec, resize, grow bool, progress func(reason string) error) error {
+	if resize {
+		if err := progress(infrav1.VMResizingReason); err != nil {
+			return err
+		}
+		if err := s.virtualMachinesSvc.Resize(ctx, vmSpec); err != nil {
+			return err
+		}
+	}
+	if grow {
+		if err := progress(infrav1.OSDiskGrowingReason); err != nil {
+			return err
+		}
+		if err := s.virtualMachinesSvc.GrowOSDisk(ctx, vmSpec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartVM starts the stopped or deallocated VM of the machine.
+func (s *azureMachineService) StartVM(ctx context.Context) error {
+	return s.virtualMachinesSvc.Start(ctx, &virtualmachines.Spec{Name: s.machineScope.Name()})
+}
+
 // Delete deletes all the services in pre determined order
 func (s *azureMachineService) Delete(ctx context.Context) error {
 	vmSpec := &virtualmachines.Spec{
 		Name: s.machineScope.Name(),
 	}
 
-	err := s.virtualMachinesSvc.Delete(ctx, vmSpec)
+	err := s.privateDNSSvc.Delete(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete private DNS record")
+	}
+
+	err = s.virtualMachinesSvc.Delete(ctx, vmSpec)
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete machine")
 	}
@@ -126,7 +273,12 @@ func (s *azureMachineService) Delete(ctx context.Context) error {
 
 	err = s.disksSvc.Delete(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "Failed to delete OS disk of machine %s", s.machineScope.Name())
+		return errors.Wrapf(err, "Failed to delete disks of machine %s", s.machineScope.Name())
+	}
+
+	err = s.availabilitySetsSvc.Delete(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete availability set")
 	}
 
 	return nil
@@ -157,77 +309,123 @@ func (s *azureMachineService) VMIfExists(ctx context.Context, id *string) (*infr
 	return vm, nil
 }
 
-// getVirtualMachineZone gets a random availability zones from available set,
-// this will hopefully be an input from upstream machinesets so all the vms are balanced
+// zonePlacementError is returned when the VM cannot be placed in an availability zone allowed by the zone placement
+// policy of its machine.
+type zonePlacementError struct {
+	error
+}
+
+// getVirtualMachineZone gets the availability zone of the VM from the failure domain of its machine, which is
+// hopefully an input from upstream machinesets so all the vms are balanced, following the zone placement policy of
+// the machine when the zone cannot be used. The chosen zone is recorded in the status of the machine.
 func (s *azureMachineService) getVirtualMachineZone(ctx context.Context) (string, error) {
 	vmName := s.machineScope.AzureMachine.Name
 	vmSize := s.machineScope.AzureMachine.Spec.VMSize
 	location := s.machineScope.AzureMachine.Spec.Location
+	fallback := s.machineScope.ZonePlacementPolicy() == infrav1.ZonePlacementPolicyFallback
 
-	zones, err := s.skuCache.GetZonesWithVMSize(ctx, vmSize, location)
+	skuZones, err := s.skuCache.GetZonesWithVMSize(ctx, vmSize, location)
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to get zones for VM size %s", vmSize)
 	}
 
-	if len(zones) <= 0 {
-		return "", nil
+	// only consider the zones the cluster is allowed to use
+	var zones []string
+	for _, zone := range skuZones {
+		if s.clusterScope.IsFailureDomainAllowed(zone) {
+			zones = append(zones, zone)
+		}
 	}
 
 	zone := s.machineScope.AvailabilityZone()
-	var selectedZone string
 
-	// DEPRECATED: to support old clients
-	if zone == "" && s.machineScope.AzureMachine.Spec.AvailabilityZone.ID != nil {
-		zone = *s.machineScope.AzureMachine.Spec.AvailabilityZone.ID
+	if zone == "" {
+		for _, candidate := range zones {
+			if fallback && s.machineScope.IsZoneExcluded(candidate) {
+				continue
+			}
+			klog.Infof("Selecting first available AZ %s for %s as no availability zone was set", candidate, vmName)
+			s.machineScope.SetZonePlacement(candidate, infrav1.ZonePlacementReasonSelected, "")
+			return candidate, nil
+		}
+		s.machineScope.SetZonePlacement("", infrav1.ZonePlacementReasonNoZone, fmt.Sprintf("VM size %s is not offered in an allowed availability zone of location %s", vmSize, location))
+		return "", nil
 	}
 
-	if zone != "" {
-		for _, allowedZone := range zones {
-			if allowedZone == zone {
-				selectedZone = zone
-				break
-			}
+	for _, allowedZone := range zones {
+		if allowedZone == zone && !(fallback && s.machineScope.IsZoneExcluded(zone)) {
+			klog.Infof("Selected availability zone %s for %s", zone, vmName)
+			s.machineScope.SetZonePlacement(zone, infrav1.ZonePlacementReasonFailureDomain, "")
+			return zone, nil
 		}
-	} else {
-		klog.Infof("Selecting first available AZ as no availability zone was set or user-provided availability zone is not supported for VM size %s in location %s", vmSize, location)
-		selectedZone = zones[0]
 	}
 
-	klog.Infof("Selected availability zone %s for %s", selectedZone, vmName)
+	locationZones, err := s.skuCache.GetZones(ctx, location)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get zones for location %s", location)
+	}
+	if !containsString(locationZones, zone) {
+		// The failure domain is not an availability zone, e.g. a fault domain of the availability set of the VM.
+		s.machineScope.SetZonePlacement("", infrav1.ZonePlacementReasonNoZone, fmt.Sprintf("failure domain %s is not an availability zone of location %s", zone, location))
+		return "", nil
+	}
+
+	reason := fmt.Sprintf("VM size %s is not offered in availability zone %s or the zone is not allowed", vmSize, zone)
+	if s.machineScope.IsZoneExcluded(zone) {
+		reason = fmt.Sprintf("allocation of the VM failed in availability zone %s", zone)
+	}
+	if !fallback {
+		return "", zonePlacementError{errors.Errorf("cannot place VM %s in its availability zone: %s", vmName, reason)}
+	}
 
-	return selectedZone, nil
+	// Fall back to another zone offering the VM size among the failure domains Cluster API spreads the machines of
+	// the cluster across.
+	for _, candidate := range zones {
+		fd, ok := s.clusterScope.FailureDomains()[candidate]
+		if candidate == zone || !ok || (s.machineScope.IsControlPlane() && !fd.ControlPlane) || s.machineScope.IsZoneExcluded(candidate) {
+			continue
+		}
+		klog.Infof("Selected fallback availability zone %s for %s: %s", candidate, vmName, reason)
+		s.machineScope.SetZonePlacement(candidate, infrav1.ZonePlacementReasonFallback, reason)
+		return candidate, nil
+	}
+	return "", zonePlacementError{errors.Errorf("cannot place VM %s in its availability zone: %s, and no other failure domain of the cluster offers VM size %s", vmName, reason, vmSize)}
 }
 
-func (s *azureMachineService) reconcileVirtualMachine(ctx context.Context, nicName string) (*infrav1.VM, error) {
-	decoded, err := base64.StdEncoding.DecodeString(s.machineScope.AzureMachine.Spec.SSHPublicKey)
+func (s *azureMachineService) reconcileVirtualMachine(ctx context.Context, nicNames []string) (*infrav1.VM, error) {
+	sshKeys, err := s.machineScope.GetSSHPublicKeys(ctx)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to decode ssh public key")
+		return nil, errors.Wrap(err, "failed to get SSH public keys")
 	}
 
 	var vmZone string
-	azSupported := s.isAvailabilityZoneSupported()
-	if azSupported {
-		useAZ := true
+	useAZ := true
 
-		if s.machineScope.AzureMachine.Spec.AvailabilityZone.Enabled != nil {
-			useAZ = *s.machineScope.AzureMachine.Spec.AvailabilityZone.Enabled
-		}
+	if s.machineScope.AzureMachine.Spec.AvailabilityZone.Enabled != nil {
+		useAZ = *s.machineScope.AzureMachine.Spec.AvailabilityZone.Enabled
+	}
 
-		if useAZ {
-			var zoneErr error
-			vmZone, zoneErr = s.getVirtualMachineZone(ctx)
-			if zoneErr != nil {
-				return nil, errors.Wrap(zoneErr, "failed to get availability zone")
-			}
+	if useAZ {
+		var zoneErr error
+		vmZone, zoneErr = s.getVirtualMachineZone(ctx)
+		if zoneErr != nil {
+			return nil, errors.Wrap(zoneErr, "failed to get availability zone")
 		}
+	} else {
+		s.machineScope.SetZonePlacement("", infrav1.ZonePlacementReasonNoZone, "availability zones are disabled")
 	}
 
-	nicNames := []string{nicName}
-	if s.machineScope.AzureMachine.Spec.AllocatePublicIP == true {
-		nicNames = append(nicNames, azure.GeneratePublicNICName(s.machineScope.Name()))
+	// Without an availability zone, the VM is placed in the availability set of its node group so that
+	// it is spread across fault domains.
+	var availabilitySetID string
+	if asSpec := s.machineScope.AvailabilitySetSpec(); vmZone == "" && asSpec != nil {
+		if err := s.availabilitySetsSvc.Reconcile(ctx); err != nil {
+			return nil, errors.Wrap(err, "failed to reconcile availability set")
+		}
+		availabilitySetID = azure.AvailabilitySetID(s.clusterScope.SubscriptionID(), s.clusterScope.ResourceGroup(), asSpec.Name)
 	}
 
-	image, err := getVMImage(s.machineScope)
+	image, err := s.resolveVMImage(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get VM image")
 	}
@@ -237,10 +435,24 @@ func (s *azureMachineService) reconcileVirtualMachine(ctx context.Context, nicNa
 		return nil, errors.Wrap(err, "failed to retrieve bootstrap data")
 	}
 
+	var adminPassword string
+	if s.machineScope.IsWindows() {
+		adminPassword, err = s.machineScope.GetWindowsAdminPassword(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get Windows admin password")
+		}
+	}
+
+	bootDiagnosticsStorageURI, err := s.bootDiagnosticsSvc.StorageURI(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get boot diagnostics storage URI")
+	}
+
 	vmSpec := &virtualmachines.Spec{
 		Name:                   s.machineScope.Name(),
 		NICNames:               nicNames,
-		SSHKeyData:             string(decoded),
+		AdminUsername:          s.machineScope.AdminUsername(),
+		SSHKeys:                sshKeys,
 		Size:                   s.machineScope.AzureMachine.Spec.VMSize,
 		OSDisk:                 s.machineScope.AzureMachine.Spec.OSDisk,
 		DataDisks:              s.machineScope.AzureMachine.Spec.DataDisks,
@@ -250,12 +462,25 @@ func (s *azureMachineService) reconcileVirtualMachine(ctx context.Context, nicNa
 		Identity:               s.machineScope.AzureMachine.Spec.Identity,
 		UserAssignedIdentities: s.machineScope.AzureMachine.Spec.UserAssignedIdentities,
 		SpotVMOptions:          s.machineScope.AzureMachine.Spec.SpotVMOptions,
+		AvailabilitySetID:      availabilitySetID,
+		AdminPassword:          adminPassword,
+		WindowsRemoteAccess:    s.machineScope.WindowsRemoteAccess(),
+		BootDiagnostics:        s.machineScope.BootDiagnostics(),
+		DiagnosticsStorageURI:  bootDiagnosticsStorageURI,
 	}
 
 	err = s.virtualMachinesSvc.Reconcile(ctx, vmSpec)
 	if err != nil {
+		// A VM whose provisioning fails is left in the failed state, the error of its creation describing why.
+		if vm, getErr := s.virtualMachinesSvc.Get(ctx, vmSpec); getErr == nil && vm != nil && vm.State == infrav1.VMStateFailed {
+			return nil, s.deleteFailedVirtualMachine(ctx, vmSpec, err.Error())
+		}
 		return nil, errors.Wrapf(err, "failed to reconcile virtual machine")
 	}
+	if s.machineScope.SSHPublicKeysHash() == "" {
+		// record the keys the VM is created with
+		s.machineScope.SetSSHPublicKeysHash(azure.GenerateSSHPublicKeysHash(sshKeys))
+	}
 
 	newVM, err := s.virtualMachinesSvc.Get(ctx, vmSpec)
 	if err != nil {
@@ -263,17 +488,11 @@ func (s *azureMachineService) reconcileVirtualMachine(ctx context.Context, nicNa
 	}
 	if newVM != nil {
 		if newVM.State == infrav1.VMStateFailed {
-			// If VM failed provisioning, delete it so it can be recreated
-			err = s.virtualMachinesSvc.Delete(ctx, vmSpec)
+			provisioningErr, err := s.virtualMachinesSvc.GetProvisioningError(ctx, vmSpec)
 			if err != nil {
-				return nil, errors.Wrapf(err, "failed to delete machine")
-			}
-
-			err = s.disksSvc.Delete(ctx)
-			if err != nil && !azure.ResourceNotFound(err) {
-				return nil, errors.Wrapf(err, "failed to delete OS disk of machine %s", s.machineScope.Name())
+				s.machineScope.Error(err, "failed to get provisioning error of failed VM")
 			}
-			return nil, errors.Errorf("virtual machine %s is deleted, retry creating in next reconcile", s.machineScope.Name())
+			return nil, s.deleteFailedVirtualMachine(ctx, vmSpec, provisioningErr)
 		} else if newVM.State != infrav1.VMStateSucceeded {
 			return nil, errors.Errorf("virtual machine %s is still in provisioning state %s, reconcile", s.machineScope.Name(), newVM.State)
 		}
@@ -281,6 +500,63 @@ func (s *azureMachineService) reconcileVirtualMachine(ctx context.Context, nicNa
 	return newVM, nil
 }
 
+// deleteFailedVirtualMachine deletes a VM that failed provisioning, along with its disks, so that it can be created
+// again after a backoff, and records the failed attempt with the error reported by Azure. The serial console log of
+// the VM is kept beforehand to help troubleshooting.
+func (s *azureMachineService) deleteFailedVirtualMachine(ctx context.Context, vmSpec *virtualmachines.Spec, provisioningErr string) error {
+	secretName, err := s.CaptureSerialConsoleLog(ctx)
+	if err != nil {
+		s.machineScope.Error(err, "failed to capture serial console log of failed VM")
+	}
+
+	err = s.virtualMachinesSvc.Delete(ctx, vmSpec)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete machine")
+	}
+
+	err = s.disksSvc.Delete(ctx)
+	if err != nil && !azure.ResourceNotFound(err) {
+		return errors.Wrapf(err, "failed to delete disks of machine %s", s.machineScope.Name())
+	}
+
+	if provisioningErr == "" {
+		provisioningErr = "provisioning state is Failed"
+	}
+	if vmSpec.Zone != "" && isAllocationFailure(provisioningErr) {
+		s.machineScope.ExcludeZone(vmSpec.Zone)
+	}
+	s.machineScope.RecordVMProvisioningFailure(provisioningErr)
+	// the VM is created again with the keys of the spec at that time
+	s.machineScope.SetSSHPublicKeysHash("")
+
+	message := fmt.Sprintf("virtual machine %s failed to provision and is deleted, retry creating in %s: %s", s.machineScope.Name(), s.machineScope.VMProvisioningBackoff().Round(time.Second), provisioningErr)
+	if secretName != "" {
+		message += fmt.Sprintf("; its serial console log is stored in secret %s", secretName)
+	}
+	return errors.New(message)
+}
+
+// isAllocationFailure returns true if the provisioning error of a VM reports that Azure could not allocate it, e.g.
+// for lack of capacity in its availability zone.
+func isAllocationFailure(provisioningErr string) bool {
+	for _, code := range []string{"AllocationFailed", "OverconstrainedAllocationRequest", "OverconstrainedZonalAllocationRequest"} {
+		if strings.Contains(provisioningErr, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString returns true if the slice contains the string.
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // GetControlPlaneMachines retrieves all non-deleted control plane nodes from a MachineList
 func GetControlPlaneMachines(machineList *clusterv1.MachineList) []*clusterv1.Machine {
 	var cpm []*clusterv1.Machine
@@ -293,29 +569,49 @@ func GetControlPlaneMachines(machineList *clusterv1.MachineList) []*clusterv1.Ma
 	return cpm
 }
 
-// isAvailabilityZoneSupported determines if Availability Zones are supported in a selected location
-// based on SupportedAvailabilityZoneLocations. Returns true if supported.
-func (s *azureMachineService) isAvailabilityZoneSupported() bool {
-	azSupported := false
-
-	for _, supportedLocation := range azure.SupportedAvailabilityZoneLocations {
-		if s.machineScope.Location() == supportedLocation {
-			azSupported = true
+// resolveVMImage returns the image to create the VM of the machine from, with a "latest" Marketplace image version
+// resolved to a concrete version, and records it in the status of the machine. The image recorded in the status is
+// reused when the VM is created again, and the image recorded on the AzureMachineTemplate of the machine is used when
+// the machine pins its image version.
+func (s *azureMachineService) resolveVMImage(ctx context.Context) (*infrav1.Image, error) {
+	image, err := getVMImage(s.machineScope, s.imageCatalog)
+	if err != nil {
+		return nil, err
+	}
+	if resolved := s.machineScope.ResolvedImage(); resolved != nil && virtualmachineimages.IsResolvedFrom(resolved, image) {
+		return resolved, nil
+	}
 
-			return azSupported
+	if s.machineScope.PinImageVersion() {
+		pinned, err := s.machineScope.GetPinnedImage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if pinned != nil && virtualmachineimages.IsResolvedFrom(pinned, image) {
+			s.machineScope.SetResolvedImage(pinned)
+			return pinned, nil
 		}
 	}
 
-	s.machineScope.V(2).Info("Availability Zones are not supported in the selected location", "location", s.machineScope.Location())
-	return azSupported
+	resolved, err := s.vmImagesSvc.ResolveImage(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	if s.machineScope.PinImageVersion() {
+		if err := s.machineScope.PinImage(ctx, resolved); err != nil {
+			return nil, err
+		}
+	}
+	s.machineScope.SetResolvedImage(resolved)
+	return resolved, nil
 }
 
 // Pick image from the machine configuration, or use a default one.
-func getVMImage(scope *scope.MachineScope) (*infrav1.Image, error) {
+func getVMImage(scope *scope.MachineScope, imageCatalog *azure.ImageCatalog) (*infrav1.Image, error) {
 	// Use custom Marketplace image, Image ID or a Shared Image Gallery image if provided
 	if scope.AzureMachine.Spec.Image != nil {
 		return scope.AzureMachine.Spec.Image, nil
 	}
 	scope.Info("No image specified for machine, using default", "machine", scope.AzureMachine.GetName())
-	return azure.GetDefaultUbuntuImage(to.String(scope.Machine.Spec.Version))
+	return imageCatalog.GetDefaultImage(scope.AzureMachine.Spec.OSDisk.OSType, to.String(scope.Machine.Spec.Version))
 }
@@ -17,14 +17,17 @@ limitations under the License.
 package controllers
 
 import (
-	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
-	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"context"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -114,36 +117,179 @@ func TestGetControlPlaneMachines(t *testing.T) {
 	}
 }
 
-func TestIsAvailabilityZoneSupported(t *testing.T) {
+func TestGetVirtualMachineZone(t *testing.T) {
 	g := NewWithT(t)
 
-	clusterScope := &scope.ClusterScope{
-		AzureCluster: &infrav1.AzureCluster{
-			Spec: infrav1.AzureClusterSpec{
-				Location: "",
+	skus := []compute.ResourceSku{
+		{
+			Name:      to.StringPtr("Standard_D2s_v3"),
+			Kind:      to.StringPtr(string(resourceskus.VirtualMachines)),
+			Locations: &[]string{"eastus"},
+			LocationInfo: &[]compute.ResourceSkuLocationInfo{
+				{
+					Location: to.StringPtr("eastus"),
+					Zones:    &[]string{"1", "2", "3"},
+				},
+			},
+		},
+		{
+			Name:      to.StringPtr("Standard_D2s_v3"),
+			Kind:      to.StringPtr(string(resourceskus.VirtualMachines)),
+			Locations: &[]string{"local"},
+			LocationInfo: &[]compute.ResourceSkuLocationInfo{
+				{
+					Location: to.StringPtr("local"),
+				},
 			},
 		},
 	}
 
-	s := azureMachineService{
-		machineScope: &scope.MachineScope{
-			Logger:           log.Log.Logger,
-			ClusterDescriber: clusterScope,
+	tests := []struct {
+		name                  string
+		location              string
+		failureDomain         *string
+		allowedFailureDomains []string
+		policy                infrav1.ZonePlacementPolicy
+		excludedZones         []string
+		expected              string
+		expectedReason        infrav1.ZonePlacementReason
+		expectedErr           string
+	}{
+		{
+			name:           "selects the first zone of the VM size",
+			location:       "eastus",
+			expected:       "1",
+			expectedReason: infrav1.ZonePlacementReasonSelected,
+		},
+		{
+			name:           "selects the requested zone",
+			location:       "eastus",
+			failureDomain:  to.StringPtr("2"),
+			expected:       "2",
+			expectedReason: infrav1.ZonePlacementReasonFailureDomain,
+		},
+		{
+			name:           "ignores a failure domain that is not a zone",
+			location:       "eastus",
+			failureDomain:  to.StringPtr("4"),
+			expected:       "",
+			expectedReason: infrav1.ZonePlacementReasonNoZone,
+		},
+		{
+			name:                  "selects the first allowed zone",
+			location:              "eastus",
+			allowedFailureDomains: []string{"3"},
+			expected:              "3",
+			expectedReason:        infrav1.ZonePlacementReasonSelected,
+		},
+		{
+			name:           "selects no zone when the location has none",
+			location:       "local",
+			expected:       "",
+			expectedReason: infrav1.ZonePlacementReasonNoZone,
+		},
+		{
+			name:           "selects no zone for a fault domain",
+			location:       "local",
+			failureDomain:  to.StringPtr("fd-1"),
+			expected:       "",
+			expectedReason: infrav1.ZonePlacementReasonNoZone,
+		},
+		{
+			name:                  "fails when the requested zone is not allowed with the strict policy",
+			location:              "eastus",
+			failureDomain:         to.StringPtr("1"),
+			allowedFailureDomains: []string{"2", "3"},
+			expectedErr:           "cannot place VM my-vm in its availability zone: VM size Standard_D2s_v3 is not offered in availability zone 1 or the zone is not allowed",
+		},
+		{
+			name:                  "falls back to another zone with the fallback policy",
+			location:              "eastus",
+			failureDomain:         to.StringPtr("1"),
+			allowedFailureDomains: []string{"2", "3"},
+			policy:                infrav1.ZonePlacementPolicyFallback,
+			expected:              "2",
+			expectedReason:        infrav1.ZonePlacementReasonFallback,
+		},
+		{
+			name:           "retries the requested zone after an allocation failure with the strict policy",
+			location:       "eastus",
+			failureDomain:  to.StringPtr("1"),
+			excludedZones:  []string{"1"},
+			expected:       "1",
+			expectedReason: infrav1.ZonePlacementReasonFailureDomain,
+		},
+		{
+			name:           "falls back to another zone after an allocation failure with the fallback policy",
+			location:       "eastus",
+			failureDomain:  to.StringPtr("1"),
+			policy:         infrav1.ZonePlacementPolicyFallback,
+			excludedZones:  []string{"1", "2"},
+			expected:       "3",
+			expectedReason: infrav1.ZonePlacementReasonFallback,
+		},
+		{
+			name:          "fails when no other zone is available with the fallback policy",
+			location:      "eastus",
+			failureDomain: to.StringPtr("1"),
+			policy:        infrav1.ZonePlacementPolicyFallback,
+			excludedZones: []string{"1", "2", "3"},
+			expectedErr:   "cannot place VM my-vm in its availability zone: allocation of the VM failed in availability zone 1, and no other failure domain of the cluster offers VM size Standard_D2s_v3",
 		},
 	}
 
-	for _, l := range azure.SupportedAvailabilityZoneLocations {
-		clusterScope.AzureCluster.Spec.Location = l
-		g.Expect(s.isAvailabilityZoneSupported()).To(BeTrue())
-	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clusterScope := &scope.ClusterScope{
+				AzureCluster: &infrav1.AzureCluster{
+					Spec: infrav1.AzureClusterSpec{
+						Location:              tc.location,
+						AllowedFailureDomains: tc.allowedFailureDomains,
+					},
+					Status: infrav1.AzureClusterStatus{
+						FailureDomains: clusterv1.FailureDomains{
+							"1": clusterv1.FailureDomainSpec{ControlPlane: true},
+							"2": clusterv1.FailureDomainSpec{ControlPlane: true},
+							"3": clusterv1.FailureDomainSpec{ControlPlane: true},
+						},
+					},
+				},
+			}
 
-	unSupportedLocations := []string{
-		"randomregion",
-		"unsupportedregion",
-	}
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Logger:           log.Log.Logger,
+					ClusterDescriber: clusterScope,
+					Machine: &clusterv1.Machine{
+						Spec: clusterv1.MachineSpec{
+							FailureDomain: tc.failureDomain,
+						},
+					},
+					AzureMachine: &infrav1.AzureMachine{
+						ObjectMeta: v1.ObjectMeta{Name: "my-vm"},
+						Spec: infrav1.AzureMachineSpec{
+							Location:            tc.location,
+							VMSize:              "Standard_D2s_v3",
+							ZonePlacementPolicy: tc.policy,
+						},
+						Status: infrav1.AzureMachineStatus{
+							ZonePlacement: &infrav1.ZonePlacementStatus{ExcludedZones: tc.excludedZones},
+						},
+					},
+				},
+				clusterScope: clusterScope,
+				skuCache:     resourceskus.NewStaticCache(skus),
+			}
 
-	for _, l := range unSupportedLocations {
-		clusterScope.AzureCluster.Spec.Location = l
-		g.Expect(s.isAvailabilityZoneSupported()).To(BeFalse())
+			zone, err := s.getVirtualMachineZone(context.Background())
+			if tc.expectedErr != "" {
+				g.Expect(err).To(MatchError(tc.expectedErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(zone).To(Equal(tc.expected))
+			g.Expect(s.machineScope.AzureMachine.Status.ZonePlacement.Zone).To(Equal(tc.expected))
+			g.Expect(s.machineScope.AzureMachine.Status.ZonePlacement.Reason).To(Equal(tc.expectedReason))
+		})
 	}
 }
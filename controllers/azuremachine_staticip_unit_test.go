@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+)
+
+func TestPickStaticIPAddress(t *testing.T) {
+	g := NewWithT(t)
+
+	clusterScope := &scope.ClusterScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"}},
+	}
+	newMachineScope := func(name string) *scope.MachineScope {
+		return &scope.MachineScope{
+			Logger:           log.Log.Logger,
+			ClusterDescriber: clusterScope,
+			AzureMachine: &infrav1.AzureMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			},
+		}
+	}
+	pool := []string{"10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.13"}
+	claimed := map[string]struct{}{"10.0.0.10": {}}
+	inUse := map[string]string{"10.0.0.11": "other-nic", "10.0.0.13": "machine-b-nic"}
+
+	// the first address neither claimed nor in use is picked and recorded
+	machineA := newMachineScope("machine-a")
+	address, err := pickStaticIPAddress(machineA, pool, claimed, inUse)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(address).To(Equal("10.0.0.12"))
+	defer releaseStaticIPAddress(machineA)
+	machineA.SetStaticIPAddress(address)
+
+	// the address of the network interface of the machine is picked again
+	machineB := newMachineScope("machine-b")
+	address, err = pickStaticIPAddress(machineB, pool, claimed, inUse)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(address).To(Equal("10.0.0.13"))
+	defer releaseStaticIPAddress(machineB)
+	machineB.SetStaticIPAddress(address)
+
+	// an address recorded for another machine is not picked, even if it is not persisted yet
+	_, err = pickStaticIPAddress(newMachineScope("machine-c"), pool, claimed, inUse)
+	g.Expect(err).To(MatchError(ContainSubstring("no free static IP address left")))
+}
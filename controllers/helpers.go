@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
 )
 
@@ -89,6 +90,48 @@ func AzureClusterToAzureMachinesMapper(c client.Client, scheme *runtime.Scheme,
 	}), nil
 }
 
+// GetClaimedStaticIPAddresses returns the static IP addresses claimed by the AzureMachines of a cluster,
+// except the AzureMachine with the given name.
+func GetClaimedStaticIPAddresses(ctx context.Context, c client.Client, namespace, clusterName, exclude string) (map[string]struct{}, error) {
+	azureMachines := &infrav1.AzureMachineList{}
+	if err := c.List(ctx, azureMachines, client.InNamespace(namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list AzureMachines of cluster %s/%s", namespace, clusterName)
+	}
+
+	claimed := make(map[string]struct{})
+	for _, azureMachine := range azureMachines.Items {
+		if azureMachine.Name == exclude || azureMachine.Status.StaticIPAddress == "" {
+			continue
+		}
+		claimed[azureMachine.Status.StaticIPAddress] = struct{}{}
+	}
+	return claimed, nil
+}
+
+// GetPrivateIPAddressesInUse returns the private IP addresses of the network interfaces in a resource group, mapped
+// to the name of the network interface they are assigned to. Unlike the status of the AzureMachines, the network
+// interfaces keep the static IP addresses of machines whose status was lost, e.g. when moved by clusterctl.
+func GetPrivateIPAddressesInUse(ctx context.Context, nicClient networkinterfaces.Client, resourceGroup string) (map[string]string, error) {
+	nics, err := nicClient.List(ctx, resourceGroup)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list network interfaces in resource group %s", resourceGroup)
+	}
+
+	inUse := make(map[string]string)
+	for _, nic := range nics {
+		if nic.Name == nil || nic.InterfacePropertiesFormat == nil || nic.IPConfigurations == nil {
+			continue
+		}
+		for _, ipConfig := range *nic.IPConfigurations {
+			if ipConfig.InterfaceIPConfigurationPropertiesFormat == nil || ipConfig.PrivateIPAddress == nil {
+				continue
+			}
+			inUse[*ipConfig.PrivateIPAddress] = *nic.Name
+		}
+	}
+	return inUse, nil
+}
+
 // GetOwnerClusterName returns the name of the owning Cluster by finding a clusterv1.Cluster in the ownership references.
 func GetOwnerClusterName(obj metav1.ObjectMeta) (string, bool) {
 	for _, ref := range obj.OwnerReferences {
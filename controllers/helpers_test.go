@@ -17,10 +17,13 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/gomega"
 
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces/mock_networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/internal/test/mock_log"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
@@ -72,6 +76,62 @@ func TestAzureClusterToAzureMachinesMapper(t *testing.T) {
 	g.Expect(requests).To(HaveLen(2))
 }
 
+func TestGetClaimedStaticIPAddresses(t *testing.T) {
+	g := NewWithT(t)
+	scheme := setupScheme(g)
+	clusterName := "my-cluster"
+	initObjects := []runtime.Object{
+		newAzureMachineWithStaticIPAddress(clusterName, "my-machine-0", "10.0.0.10"),
+		newAzureMachineWithStaticIPAddress(clusterName, "my-machine-1", "10.0.0.11"),
+		newAzureMachineWithStaticIPAddress(clusterName, "my-machine-2", ""),
+		newAzureMachineWithStaticIPAddress("other-cluster", "other-machine-0", "10.0.0.12"),
+	}
+	client := fake.NewFakeClientWithScheme(scheme, initObjects...)
+
+	claimed, err := GetClaimedStaticIPAddresses(context.TODO(), client, "default", clusterName, "my-machine-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(claimed).To(HaveLen(1))
+	g.Expect(claimed).To(HaveKey("10.0.0.10"))
+}
+
+func TestGetPrivateIPAddressesInUse(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	nicClient := mock_networkinterfaces.NewMockClient(mockCtrl)
+	nicClient.EXPECT().List(context.TODO(), "my-rg").Return([]network.Interface{
+		newNetworkInterface("my-machine-0-nic", "10.0.0.10", "10.0.0.20"),
+		newNetworkInterface("my-machine-1-nic", "10.0.0.11"),
+		{Name: to.StringPtr("my-machine-2-nic")},
+	}, nil)
+
+	inUse, err := GetPrivateIPAddressesInUse(context.TODO(), nicClient, "my-rg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(inUse).To(Equal(map[string]string{
+		"10.0.0.10": "my-machine-0-nic",
+		"10.0.0.20": "my-machine-0-nic",
+		"10.0.0.11": "my-machine-1-nic",
+	}))
+}
+
+func newNetworkInterface(name string, addresses ...string) network.Interface {
+	ipConfigs := []network.InterfaceIPConfiguration{}
+	for _, address := range addresses {
+		ipConfigs = append(ipConfigs, network.InterfaceIPConfiguration{
+			InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+				PrivateIPAddress: to.StringPtr(address),
+			},
+		})
+	}
+	return network.Interface{
+		Name: to.StringPtr(name),
+		InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+			IPConfigurations: &ipConfigs,
+		},
+	}
+}
+
 func setupScheme(g *WithT) *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	g.Expect(infrav1.AddToScheme(scheme)).ToNot(HaveOccurred())
@@ -102,6 +162,21 @@ func newMachineWithInfrastructureRef(clusterName, machineName string) *clusterv1
 	return m
 }
 
+func newAzureMachineWithStaticIPAddress(clusterName, machineName, address string) *infrav1.AzureMachine {
+	return &infrav1.AzureMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: clusterName,
+			},
+			Name:      machineName,
+			Namespace: "default",
+		},
+		Status: infrav1.AzureMachineStatus{
+			StaticIPAddress: address,
+		},
+	}
+}
+
 func newCluster(name string) *clusterv1.Cluster {
 	return &clusterv1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
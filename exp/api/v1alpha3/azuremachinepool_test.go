@@ -79,6 +79,101 @@ func TestAzureMachinePool_Validate(t *testing.T) {
 				g.Expect(actual.Error()).To(gomega.ContainSubstring("You must supply a ID, Marketplace or SharedGallery image details"))
 			},
 		},
+		{
+			Name: "HasReservedVMExtensionName",
+			Factory: func(_ *gomega.GomegaWithT) *exp.AzureMachinePool {
+				return &exp.AzureMachinePool{
+					Spec: exp.AzureMachinePoolSpec{
+						Template: exp.AzureMachineTemplate{
+							VMExtensions: []infrav1.VMExtension{
+								{
+									Name:      "capz-monitoring-agent",
+									Publisher: "Microsoft.Azure.Monitor",
+									Type:      "AzureMonitorLinuxAgent",
+									Version:   "1.5",
+								},
+							},
+						},
+					},
+				}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual error) {
+				g.Expect(actual).To(gomega.HaveOccurred())
+				g.Expect(actual.Error()).To(gomega.ContainSubstring("name prefix is reserved"))
+			},
+		},
+		{
+			Name: "HasBootDiagnosticsWithoutStorageAccount",
+			Factory: func(_ *gomega.GomegaWithT) *exp.AzureMachinePool {
+				return &exp.AzureMachinePool{
+					Spec: exp.AzureMachinePoolSpec{
+						Template: exp.AzureMachineTemplate{
+							BootDiagnostics: &infrav1.BootDiagnostics{},
+						},
+					},
+				}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual error) {
+				g.Expect(actual).To(gomega.HaveOccurred())
+				g.Expect(actual.Error()).To(gomega.ContainSubstring("storageAccount.name"))
+			},
+		},
+		{
+			Name: "HasRetainedDataDisk",
+			Factory: func(_ *gomega.GomegaWithT) *exp.AzureMachinePool {
+				lun := int32(0)
+				return &exp.AzureMachinePool{
+					Spec: exp.AzureMachinePoolSpec{
+						Template: exp.AzureMachineTemplate{
+							DataDisks: []infrav1.DataDisk{
+								{
+									NameSuffix:     "data",
+									DiskSizeGB:     64,
+									Lun:            &lun,
+									RetainOnDelete: true,
+								},
+							},
+						},
+					},
+				}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual error) {
+				g.Expect(actual).To(gomega.HaveOccurred())
+				g.Expect(actual.Error()).To(gomega.ContainSubstring("data disks of machine pools cannot be retained on delete"))
+			},
+		},
+		{
+			Name: "HasReservedAdminUsername",
+			Factory: func(_ *gomega.GomegaWithT) *exp.AzureMachinePool {
+				return &exp.AzureMachinePool{
+					Spec: exp.AzureMachinePoolSpec{
+						Template: exp.AzureMachineTemplate{
+							AdminUsername: "root",
+						},
+					},
+				}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual error) {
+				g.Expect(actual).To(gomega.HaveOccurred())
+				g.Expect(actual.Error()).To(gomega.ContainSubstring("adminUsername"))
+			},
+		},
+		{
+			Name: "HasInvalidAdditionalSSHPublicKey",
+			Factory: func(_ *gomega.GomegaWithT) *exp.AzureMachinePool {
+				return &exp.AzureMachinePool{
+					Spec: exp.AzureMachinePoolSpec{
+						Template: exp.AzureMachineTemplate{
+							AdditionalSSHPublicKeys: []string{"ssh-rsa not-a-key"},
+						},
+					},
+				}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual error) {
+				g.Expect(actual).To(gomega.HaveOccurred())
+				g.Expect(actual.Error()).To(gomega.ContainSubstring("additionalSSHPublicKeys"))
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -92,3 +187,107 @@ func TestAzureMachinePool_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestAzureMachinePool_ValidateEphemeralOSDiskUpdate(t *testing.T) {
+	newPool := func(diffDiskSettings *infrav1.DiffDiskSettings) *exp.AzureMachinePool {
+		return &exp.AzureMachinePool{
+			Spec: exp.AzureMachinePoolSpec{
+				Template: exp.AzureMachineTemplate{
+					OSDisk: infrav1.OSDisk{
+						DiffDiskSettings: diffDiskSettings,
+						ManagedDisk: infrav1.ManagedDisk{
+							StorageAccountType: "Standard_LRS",
+						},
+					},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		Name    string
+		Old     *exp.AzureMachinePool
+		New     *exp.AzureMachinePool
+		WantErr bool
+	}{
+		{
+			Name:    "UnchangedEphemeralOSDisk",
+			Old:     newPool(&infrav1.DiffDiskSettings{Option: "Local"}),
+			New:     newPool(&infrav1.DiffDiskSettings{Option: "Local"}),
+			WantErr: false,
+		},
+		{
+			Name:    "AddedEphemeralOSDisk",
+			Old:     newPool(nil),
+			New:     newPool(&infrav1.DiffDiskSettings{Option: "Local"}),
+			WantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			g := gomega.NewGomegaWithT(t)
+			err := c.New.ValidateEphemeralOSDiskUpdate(c.Old)
+			if c.WantErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestAzureMachinePool_ValidateNetworkInterfaceUpdate(t *testing.T) {
+	newPool := func(enableIPForwarding bool, secondaryIPCount int32) *exp.AzureMachinePool {
+		return &exp.AzureMachinePool{
+			Spec: exp.AzureMachinePoolSpec{
+				Template: exp.AzureMachineTemplate{
+					EnableIPForwarding: enableIPForwarding,
+					SecondaryIPCount:   secondaryIPCount,
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		Name    string
+		Old     *exp.AzureMachinePool
+		New     *exp.AzureMachinePool
+		WantErr bool
+	}{
+		{
+			Name:    "UnchangedNetworkInterface",
+			Old:     newPool(true, 2),
+			New:     newPool(true, 2),
+			WantErr: false,
+		},
+		{
+			Name:    "ChangedIPForwarding",
+			Old:     newPool(false, 2),
+			New:     newPool(true, 2),
+			WantErr: true,
+		},
+		{
+			Name:    "ChangedSecondaryIPCount",
+			Old:     newPool(true, 2),
+			New:     newPool(true, 3),
+			WantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			g := gomega.NewGomegaWithT(t)
+			err := c.New.ValidateNetworkInterfaceUpdate(c.Old)
+			if c.WantErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+		})
+	}
+}
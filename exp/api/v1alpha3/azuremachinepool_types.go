@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/cluster-api/errors"
 
@@ -31,7 +32,7 @@ type (
 
 		// Image is used to provide details of an image to use during Virtual Machine creation.
 		// If image details are omitted the image will default the Azure Marketplace "capi" offer,
-		// which is based on Ubuntu.
+		// which is based on Ubuntu, or the "capi-windows" offer for Windows machines.
 		// +kubebuilder:validation:nullable
 		// +optional
 		Image *infrav1.Image `json:"image,omitempty"`
@@ -39,18 +40,60 @@ type (
 		// OSDisk contains the operating system disk information for a Virtual Machine
 		OSDisk infrav1.OSDisk `json:"osDisk"`
 
+		// WindowsConfiguration specifies the operating system settings of the Virtual Machines when OSDisk.OSType is Windows.
+		// +optional
+		WindowsConfiguration *infrav1.WindowsConfiguration `json:"windowsConfiguration,omitempty"`
+
 		// DataDisks specifies the list of data disks to be created for a Virtual Machine
 		// +optional
 		DataDisks []infrav1.DataDisk `json:"dataDisks,omitempty"`
 
-		// SSHPublicKey is the SSH public key string base64 encoded to add to a Virtual Machine
-		SSHPublicKey string `json:"sshPublicKey"`
+		// SSHPublicKey is the SSH public key string base64 encoded to add to a Virtual Machine. Defaults to the
+		// public key generated for the cluster.
+		// +optional
+		SSHPublicKey string `json:"sshPublicKey,omitempty"`
+
+		// AdminUsername is the name of the administrator account of the Virtual Machines. Defaults to capi.
+		// +optional
+		AdminUsername string `json:"adminUsername,omitempty"`
+
+		// AdditionalSSHPublicKeys are SSH public keys in the OpenSSH authorized_keys format authorized for the
+		// administrator account, in addition to SSHPublicKey. Changes are applied to new instances, and to
+		// existing instances when they are upgraded to the latest model of the scale set.
+		// +optional
+		AdditionalSSHPublicKeys []string `json:"additionalSSHPublicKeys,omitempty"`
+
+		// SSHPublicKeysSecretRef is a reference to a Secret in the namespace of the machine pool holding additional
+		// SSH public keys in the OpenSSH authorized_keys format under the `authorized_keys` key.
+		// +optional
+		SSHPublicKeysSecretRef *corev1.LocalObjectReference `json:"sshPublicKeysSecretRef,omitempty"`
 
 		// AcceleratedNetworking enables or disables Azure accelerated networking. If omitted, it will be set based on
 		// whether the requested VMSize supports accelerated networking.
 		// If AcceleratedNetworking is set to true with a VMSize that does not support it, Azure will return an error.
 		// +optional
 		AcceleratedNetworking *bool `json:"acceleratedNetworking,omitempty"`
+
+		// EnableIPForwarding enables IP forwarding on the network interfaces of the Virtual Machines in the scale set.
+		// Immutable.
+		// +optional
+		EnableIPForwarding bool `json:"enableIPForwarding,omitempty"`
+
+		// SecondaryIPCount is the number of secondary private IP configurations to add to the network interface
+		// of each Virtual Machine in the scale set. Immutable.
+		// +kubebuilder:validation:Minimum=0
+		// +optional
+		SecondaryIPCount int32 `json:"secondaryIPCount,omitempty"`
+
+		// VMExtensions specifies the VM extensions to install on the Virtual Machines in the scale set.
+		// Changes are applied to new instances, and to existing instances when they are upgraded to the
+		// latest model of the scale set.
+		// +optional
+		VMExtensions []infrav1.VMExtension `json:"vmExtensions,omitempty"`
+
+		// BootDiagnostics enables the boot diagnostics of the Virtual Machines in the scale set.
+		// +optional
+		BootDiagnostics *infrav1.BootDiagnostics `json:"bootDiagnostics,omitempty"`
 	}
 
 	// AzureMachinePoolSpec defines the desired state of AzureMachinePool
@@ -90,6 +133,19 @@ type (
 		// +optional
 		ProvisioningState *infrav1.VMState `json:"provisioningState,omitempty"`
 
+		// VMExtensions is the provisioning state of the VM extensions in the model of the scale set.
+		// +optional
+		VMExtensions []infrav1.VMExtensionStatus `json:"vmExtensions,omitempty"`
+
+		// Image is the image in the model of the scale set, with a "latest" Marketplace image version resolved to
+		// the concrete version used, so that scale-outs keep booting the same image.
+		// +optional
+		Image *infrav1.Image `json:"image,omitempty"`
+
+		// Instances is the observed state of the instances of the scale set.
+		// +optional
+		Instances []AzureMachinePoolInstanceStatus `json:"instances,omitempty"`
+
 		// ErrorReason will be set in the event that there is a terminal problem
 		// reconciling the MachinePool and will contain a succinct value suitable
 		// for machine interpretation.
@@ -129,6 +185,21 @@ type (
 		FailureMessage *string `json:"failureMessage,omitempty"`
 	}
 
+	// AzureMachinePoolInstanceStatus defines the observed state of an instance of the scale set of an AzureMachinePool.
+	AzureMachinePoolInstanceStatus struct {
+		// ProviderID is the provider identification of the instance.
+		// +optional
+		ProviderID string `json:"providerID,omitempty"`
+
+		// InstanceID is the identifier of the instance in the scale set.
+		// +optional
+		InstanceID string `json:"instanceID,omitempty"`
+
+		// SecondaryIPAddresses contains the secondary private IP addresses allocated to the instance's network interface.
+		// +optional
+		SecondaryIPAddresses []string `json:"secondaryIPAddresses,omitempty"`
+	}
+
 	// +kubebuilder:object:root=true
 	// +kubebuilder:subresource:status
 	// +kubebuilder:resource:path=azuremachinepools,scope=Namespaced,categories=cluster-api,shortName=amp
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"reflect"
+
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -52,13 +54,36 @@ var _ webhook.Validator = &AzureMachinePool{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (amp *AzureMachinePool) ValidateCreate() error {
 	azuremachinepoollog.Info("validate create", "name", amp.Name)
-	return amp.Validate()
+
+	var errs []error
+	if err := amp.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := amp.ValidateEphemeralOSDisk(); err != nil {
+		errs = append(errs, err)
+	}
+	return kerrors.NewAggregate(errs)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (amp *AzureMachinePool) ValidateUpdate(old runtime.Object) error {
 	azuremachinepoollog.Info("validate update", "name", amp.Name)
-	return amp.Validate()
+	oldAMP := old.(*AzureMachinePool)
+
+	var errs []error
+	if err := amp.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := amp.ValidateAdminUsernameUpdate(oldAMP); err != nil {
+		errs = append(errs, err)
+	}
+	if err := amp.ValidateEphemeralOSDiskUpdate(oldAMP); err != nil {
+		errs = append(errs, err)
+	}
+	if err := amp.ValidateNetworkInterfaceUpdate(oldAMP); err != nil {
+		errs = append(errs, err)
+	}
+	return kerrors.NewAggregate(errs)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -71,6 +96,13 @@ func (amp *AzureMachinePool) ValidateDelete() error {
 func (amp *AzureMachinePool) Validate() error {
 	validators := []func() error{
 		amp.ValidateImage,
+		amp.ValidateSecondaryIPCount,
+		amp.ValidateWindowsConfiguration,
+		amp.ValidateAdminUsername,
+		amp.ValidateSSHPublicKeys,
+		amp.ValidateDataDisks,
+		amp.ValidateVMExtensions,
+		amp.ValidateBootDiagnostics,
 	}
 
 	var errs []error
@@ -99,3 +131,108 @@ func (amp *AzureMachinePool) ValidateImage() error {
 	}
 	return nil
 }
+
+// ValidateSecondaryIPCount of an AzureMachinePool
+func (amp *AzureMachinePool) ValidateSecondaryIPCount() error {
+	if errs := infrav1.ValidateSecondaryIPCount(amp.Spec.Template.SecondaryIPCount, field.NewPath("template", "secondaryIPCount")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateNetworkInterfaceUpdate validates that IP forwarding and the secondary IP count of the template are not
+// changed, as the network profile of an existing scale set is left to the cloud provider and is not updated
+func (amp *AzureMachinePool) ValidateNetworkInterfaceUpdate(old *AzureMachinePool) error {
+	allErrs := field.ErrorList{}
+	if old.Spec.Template.EnableIPForwarding != amp.Spec.Template.EnableIPForwarding {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("template", "enableIPForwarding"), amp.Spec.Template.EnableIPForwarding,
+			"changing IP forwarding after machine pool creation is not allowed"))
+	}
+	if old.Spec.Template.SecondaryIPCount != amp.Spec.Template.SecondaryIPCount {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("template", "secondaryIPCount"), amp.Spec.Template.SecondaryIPCount,
+			"changing the secondary IP count after machine pool creation is not allowed"))
+	}
+	if len(allErrs) > 0 {
+		return allErrs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateWindowsConfiguration of an AzureMachinePool
+func (amp *AzureMachinePool) ValidateWindowsConfiguration() error {
+	if errs := infrav1.ValidateWindowsConfiguration(amp.Spec.Template.OSDisk.OSType, amp.Spec.Template.WindowsConfiguration, field.NewPath("template", "windowsConfiguration")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateAdminUsername validates the administrator username of the template
+func (amp *AzureMachinePool) ValidateAdminUsername() error {
+	if errs := infrav1.ValidateAdminUsername(amp.Spec.Template.OSDisk.OSType, amp.Spec.Template.AdminUsername, field.NewPath("template", "adminUsername")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateAdminUsernameUpdate validates that the administrator username of the template is not changed, as the
+// administrator account of the instances cannot be changed in the model of the scale set
+func (amp *AzureMachinePool) ValidateAdminUsernameUpdate(old *AzureMachinePool) error {
+	if errs := infrav1.ValidateAdminUsernameUpdate(old.Spec.Template.AdminUsername, amp.Spec.Template.AdminUsername, field.NewPath("template", "adminUsername")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateSSHPublicKeys validates the additional SSH public keys of the template
+func (amp *AzureMachinePool) ValidateSSHPublicKeys() error {
+	errs := infrav1.ValidateAdditionalSSHPublicKeys(amp.Spec.Template.AdditionalSSHPublicKeys, field.NewPath("template", "additionalSSHPublicKeys"))
+	errs = append(errs, infrav1.ValidateSSHPublicKeysSecretRef(amp.Spec.Template.SSHPublicKeysSecretRef, field.NewPath("template", "sshPublicKeysSecretRef"))...)
+	if len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateEphemeralOSDisk of an AzureMachinePool
+func (amp *AzureMachinePool) ValidateEphemeralOSDisk() error {
+	if errs := infrav1.ValidateEphemeralOSDisk(amp.Spec.Template.OSDisk, field.NewPath("template", "osDisk")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateEphemeralOSDiskUpdate validates the ephemeral OS disk of the template when it is changed, so that machine
+// pools created with an ephemeral OS disk before it was rejected can still be updated and deleted
+func (amp *AzureMachinePool) ValidateEphemeralOSDiskUpdate(old *AzureMachinePool) error {
+	if reflect.DeepEqual(old.Spec.Template.OSDisk.DiffDiskSettings, amp.Spec.Template.OSDisk.DiffDiskSettings) {
+		return nil
+	}
+	return amp.ValidateEphemeralOSDisk()
+}
+
+// ValidateDataDisks validates the data disks of the template and rejects disks retained on delete
+func (amp *AzureMachinePool) ValidateDataDisks() error {
+	fieldPath := field.NewPath("template", "dataDisks")
+	errs := infrav1.ValidateDataDisks(amp.Spec.Template.DataDisks, fieldPath)
+	errs = append(errs, infrav1.ValidateDataDiskRetention(amp.Spec.Template.DataDisks, fieldPath)...)
+	if len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateVMExtensions validates the VM extensions of the template
+func (amp *AzureMachinePool) ValidateVMExtensions() error {
+	if errs := infrav1.ValidateVMExtensions(amp.Spec.Template.VMExtensions, field.NewPath("template", "vmExtensions")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
+// ValidateBootDiagnostics validates the boot diagnostics of the template
+func (amp *AzureMachinePool) ValidateBootDiagnostics() error {
+	if errs := infrav1.ValidateBootDiagnostics(amp.Spec.Template.BootDiagnostics, field.NewPath("template", "bootDiagnostics")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
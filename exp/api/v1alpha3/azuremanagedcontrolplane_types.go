@@ -55,8 +55,10 @@ type AzureManagedControlPlaneSpec struct {
 	// +kubebuilder:validation:Enum=Calico;Azure
 	NetworkPolicy *string `json:"networkPolicy,omitempty"`
 
-	// SSHPublicKey is a string literal containing an ssh public key.
-	SSHPublicKey string `json:"sshPublicKey"`
+	// SSHPublicKey is a string literal containing an ssh public key. Defaults to the public key generated for the
+	// cluster.
+	// +optional
+	SSHPublicKey string `json:"sshPublicKey,omitempty"`
 
 	// DefaultPoolRef is the specification for the default pool, without which an AKS cluster cannot be created.
 	// TODO(ace): consider defaulting and making optional pointer?
@@ -27,6 +27,8 @@ type (
 		Name             string          `json:"name,omitempty"`
 		AvailabilityZone string          `json:"availabilityZone,omitempty"`
 		State            infrav1.VMState `json:"vmState,omitempty"`
+		// SecondaryIPAddresses contains the private IP addresses of the secondary IP configurations of the instance.
+		SecondaryIPAddresses []string `json:"secondaryIPAddresses,omitempty"`
 	}
 
 	VMSS struct {
@@ -40,5 +42,7 @@ type (
 		Identity  infrav1.VMIdentity `json:"identity,omitempty"`
 		Tags      infrav1.Tags       `json:"tags,omitempty"`
 		Instances []VMSSVM           `json:"instances,omitempty"`
+		// Extensions is the provisioning state of the VM extensions in the model of the scale set.
+		Extensions []infrav1.VMExtensionStatus `json:"extensions,omitempty"`
 	}
 )
@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	apiv1alpha3 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/errors"
@@ -53,6 +54,26 @@ func (in *AzureMachinePool) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMachinePoolInstanceStatus) DeepCopyInto(out *AzureMachinePoolInstanceStatus) {
+	*out = *in
+	if in.SecondaryIPAddresses != nil {
+		in, out := &in.SecondaryIPAddresses, &out.SecondaryIPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolInstanceStatus.
+func (in *AzureMachinePoolInstanceStatus) DeepCopy() *AzureMachinePoolInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMachinePoolInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMachinePoolList) DeepCopyInto(out *AzureMachinePoolList) {
 	*out = *in
@@ -121,6 +142,23 @@ func (in *AzureMachinePoolStatus) DeepCopyInto(out *AzureMachinePoolStatus) {
 		*out = new(apiv1alpha3.VMState)
 		**out = **in
 	}
+	if in.VMExtensions != nil {
+		in, out := &in.VMExtensions, &out.VMExtensions
+		*out = make([]apiv1alpha3.VMExtensionStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(apiv1alpha3.Image)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]AzureMachinePoolInstanceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.FailureReason != nil {
 		in, out := &in.FailureReason, &out.FailureReason
 		*out = new(errors.MachineStatusError)
@@ -152,6 +190,11 @@ func (in *AzureMachineTemplate) DeepCopyInto(out *AzureMachineTemplate) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.OSDisk.DeepCopyInto(&out.OSDisk)
+	if in.WindowsConfiguration != nil {
+		in, out := &in.WindowsConfiguration, &out.WindowsConfiguration
+		*out = new(apiv1alpha3.WindowsConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DataDisks != nil {
 		in, out := &in.DataDisks, &out.DataDisks
 		*out = make([]apiv1alpha3.DataDisk, len(*in))
@@ -159,11 +202,33 @@ func (in *AzureMachineTemplate) DeepCopyInto(out *AzureMachineTemplate) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AdditionalSSHPublicKeys != nil {
+		in, out := &in.AdditionalSSHPublicKeys, &out.AdditionalSSHPublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SSHPublicKeysSecretRef != nil {
+		in, out := &in.SSHPublicKeysSecretRef, &out.SSHPublicKeysSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	if in.AcceleratedNetworking != nil {
 		in, out := &in.AcceleratedNetworking, &out.AcceleratedNetworking
 		*out = new(bool)
 		**out = **in
 	}
+	if in.VMExtensions != nil {
+		in, out := &in.VMExtensions, &out.VMExtensions
+		*out = make([]apiv1alpha3.VMExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BootDiagnostics != nil {
+		in, out := &in.BootDiagnostics, &out.BootDiagnostics
+		*out = new(apiv1alpha3.BootDiagnostics)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachineTemplate.
@@ -502,6 +567,13 @@ func (in *VMSS) DeepCopyInto(out *VMSS) {
 	if in.Instances != nil {
 		in, out := &in.Instances, &out.Instances
 		*out = make([]VMSSVM, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]apiv1alpha3.VMExtensionStatus, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -519,6 +591,11 @@ func (in *VMSS) DeepCopy() *VMSS {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VMSSVM) DeepCopyInto(out *VMSSVM) {
 	*out = *in
+	if in.SecondaryIPAddresses != nil {
+		in, out := &in.SecondaryIPAddresses, &out.SecondaryIPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMSSVM.
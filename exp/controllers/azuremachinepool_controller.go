@@ -18,7 +18,6 @@ package controllers
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -48,8 +47,10 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/bootdiagnostics"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/scalesets"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachineimages"
 	"sigs.k8s.io/cluster-api-provider-azure/controllers"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
@@ -63,6 +64,8 @@ type (
 		Scheme           *runtime.Scheme
 		Recorder         record.EventRecorder
 		ReconcileTimeout time.Duration
+		// ImageCatalog holds the default images of the machine pools that don't specify one.
+		ImageCatalog *azure.ImageCatalog
 	}
 
 	// azureMachinePoolService provides structure and behavior around the operations needed to reconcile Azure Machine Pools
@@ -70,7 +73,10 @@ type (
 		machinePoolScope           *scope.MachinePoolScope
 		clusterScope               *scope.ClusterScope
 		virtualMachinesScaleSetSvc *scalesets.Service
+		bootDiagnosticsSvc         *bootdiagnostics.Service
+		vmImagesSvc                *virtualmachineimages.Service
 		skuCache                   *resourceskus.Cache
+		imageCatalog               *azure.ImageCatalog
 	}
 
 	// annotationReaderWriter provides an interface to read and write annotations
@@ -132,7 +138,7 @@ func (r *AzureMachinePoolReconciler) SetupWithManager(mgr ctrl.Manager, options
 // +kubebuilder:rbac:groups=exp.infrastructure.cluster.x-k8s.io,resources=azuremachinepools/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=exp.cluster.x-k8s.io,resources=machinepools;machinepools/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
-// +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch;create
 
 func (r *AzureMachinePoolReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
 	ctx, cancel := context.WithTimeout(context.Background(), reconciler.DefaultedLoopTimeout(r.ReconcileTimeout))
@@ -252,7 +258,12 @@ func (r *AzureMachinePoolReconciler) reconcileNormal(ctx context.Context, machin
 		return reconcile.Result{}, nil
 	}
 
-	ams := newAzureMachinePoolService(machinePoolScope, clusterScope)
+	// Default the SSH public key to the key generated for the cluster.
+	if err := machinePoolScope.SetDefaultSSHPublicKey(ctx); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to set default SSH public key")
+	}
+
+	ams := newAzureMachinePoolService(machinePoolScope, clusterScope, r.ImageCatalog)
 
 	// Get or create the virtual machine.
 	vmss, err := ams.CreateOrUpdate(ctx)
@@ -263,16 +274,24 @@ func (r *AzureMachinePoolReconciler) reconcileNormal(ctx context.Context, machin
 	// Make sure Spec.ProviderID is always set.
 	machinePoolScope.AzureMachinePool.Spec.ProviderID = fmt.Sprintf("azure:///%s", vmss.ID)
 	providerIDList := make([]string, len(vmss.Instances))
+	instances := make([]infrav1exp.AzureMachinePoolInstanceStatus, len(vmss.Instances))
 	var readyCount int32
 	for i, vm := range vmss.Instances {
 		providerIDList[i] = fmt.Sprintf("azure:///%s", vm.ID)
+		instances[i] = infrav1exp.AzureMachinePoolInstanceStatus{
+			ProviderID:           providerIDList[i],
+			InstanceID:           vm.InstanceID,
+			SecondaryIPAddresses: vm.SecondaryIPAddresses,
+		}
 		if vm.State == infrav1.VMStateSucceeded {
 			readyCount++
 		}
 	}
 	machinePoolScope.AzureMachinePool.Spec.ProviderIDList = providerIDList
+	machinePoolScope.AzureMachinePool.Status.Instances = instances
 	machinePoolScope.AzureMachinePool.Status.ProvisioningState = &vmss.State
 	machinePoolScope.AzureMachinePool.Status.Replicas = int32(len(providerIDList))
+	machinePoolScope.AzureMachinePool.Status.VMExtensions = vmss.Extensions
 	machinePoolScope.SetAnnotation("cluster-api-provider-azure", "true")
 
 	switch vmss.State {
@@ -298,7 +317,7 @@ func (r *AzureMachinePoolReconciler) reconcileNormal(ctx context.Context, machin
 func (r *AzureMachinePoolReconciler) reconcileDelete(ctx context.Context, machinePoolScope *scope.MachinePoolScope, clusterScope *scope.ClusterScope) (_ reconcile.Result, reterr error) {
 	machinePoolScope.Info("Handling deleted AzureMachinePool")
 
-	if err := newAzureMachinePoolService(machinePoolScope, clusterScope).Delete(ctx); err != nil {
+	if err := newAzureMachinePoolService(machinePoolScope, clusterScope, r.ImageCatalog).Delete(ctx); err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "error deleting AzureCluster %s/%s", clusterScope.Namespace(), clusterScope.ClusterName())
 	}
 
@@ -482,13 +501,16 @@ func (r *AzureMachinePoolReconciler) Annotation(rw annotationReaderWriter, annot
 }
 
 // newAzureMachinePoolService populates all the services based on input scope
-func newAzureMachinePoolService(machinePoolScope *scope.MachinePoolScope, clusterScope *scope.ClusterScope) *azureMachinePoolService {
+func newAzureMachinePoolService(machinePoolScope *scope.MachinePoolScope, clusterScope *scope.ClusterScope, imageCatalog *azure.ImageCatalog) *azureMachinePoolService {
 	cache := resourceskus.NewCache(clusterScope, clusterScope.Location())
 	return &azureMachinePoolService{
 		machinePoolScope:           machinePoolScope,
 		clusterScope:               clusterScope,
 		virtualMachinesScaleSetSvc: scalesets.NewService(machinePoolScope, cache),
+		bootDiagnosticsSvc:         bootdiagnostics.NewService(machinePoolScope),
+		vmImagesSvc:                virtualmachineimages.NewService(machinePoolScope),
 		skuCache:                   cache,
+		imageCatalog:               imageCatalog,
 	}
 }
 
@@ -499,12 +521,12 @@ func (s *azureMachinePoolService) CreateOrUpdate(ctx context.Context) (*infrav1e
 		replicas = int64(to.Int32(s.machinePoolScope.MachinePool.Spec.Replicas))
 	}
 
-	decoded, err := base64.StdEncoding.DecodeString(ampSpec.Template.SSHPublicKey)
+	sshKeys, err := s.machinePoolScope.GetSSHPublicKeys(ctx)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to base64 decode ssh public key")
+		return nil, errors.Wrap(err, "failed to get SSH public keys")
 	}
 
-	image, err := getVMImage(s.machinePoolScope)
+	image, err := s.resolveVMImage(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get VMSS image")
 	}
@@ -514,6 +536,24 @@ func (s *azureMachinePoolService) CreateOrUpdate(ctx context.Context) (*infrav1e
 		return nil, errors.Wrap(err, "failed to retrieve bootstrap data")
 	}
 
+	var adminPassword string
+	if ampSpec.Template.OSDisk.OSType == infrav1.WindowsOSType {
+		adminPassword, err = s.machinePoolScope.GetWindowsAdminPassword(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get Windows admin password")
+		}
+	}
+
+	extensions, err := s.machinePoolScope.VMExtensionSpecs(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get VM extensions")
+	}
+
+	bootDiagnosticsStorageURI, err := s.bootDiagnosticsSvc.StorageURI(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get boot diagnostics storage URI")
+	}
+
 	vmssSpec := &scalesets.Spec{
 		Name:                   s.machinePoolScope.Name(),
 		ResourceGroup:          s.clusterScope.ResourceGroup(),
@@ -522,7 +562,8 @@ func (s *azureMachinePoolService) CreateOrUpdate(ctx context.Context) (*infrav1e
 		MachinePoolName:        s.machinePoolScope.Name(),
 		Sku:                    ampSpec.Template.VMSize,
 		Capacity:               replicas,
-		SSHKeyData:             string(decoded),
+		AdminUsername:          s.machinePoolScope.AdminUsername(),
+		SSHKeys:                sshKeys,
 		Image:                  image,
 		OSDisk:                 ampSpec.Template.OSDisk,
 		DataDisks:              ampSpec.Template.DataDisks,
@@ -531,6 +572,13 @@ func (s *azureMachinePoolService) CreateOrUpdate(ctx context.Context) (*infrav1e
 		SubnetID:               s.clusterScope.AzureCluster.Spec.NetworkSpec.GetNodeSubnet().ID,
 		PublicLoadBalancerName: s.clusterScope.ClusterName(),
 		AcceleratedNetworking:  ampSpec.Template.AcceleratedNetworking,
+		EnableIPForwarding:     ampSpec.Template.EnableIPForwarding,
+		SecondaryIPCount:       ampSpec.Template.SecondaryIPCount,
+		AdminPassword:          adminPassword,
+		WindowsRemoteAccess:    s.machinePoolScope.WindowsRemoteAccess(),
+		Extensions:             extensions,
+		BootDiagnostics:        ampSpec.Template.BootDiagnostics,
+		DiagnosticsStorageURI:  bootDiagnosticsStorageURI,
 	}
 
 	err = s.virtualMachinesScaleSetSvc.Reconcile(ctx, vmssSpec)
@@ -621,12 +669,32 @@ func getMachinePoolByName(ctx context.Context, c client.Client, namespace, name
 	return m, nil
 }
 
+// resolveVMImage returns the image of the model of the scale set, with a "latest" Marketplace image version resolved
+// to a concrete version, and records it in the status of the machine pool. The image recorded in the status is reused
+// as long as the image of the machine pool does not change, so that scale-outs keep booting the same image.
+func (s *azureMachinePoolService) resolveVMImage(ctx context.Context) (*infrav1.Image, error) {
+	image, err := getVMImage(s.machinePoolScope, s.imageCatalog)
+	if err != nil {
+		return nil, err
+	}
+	if resolved := s.machinePoolScope.AzureMachinePool.Status.Image; resolved != nil && virtualmachineimages.IsResolvedFrom(resolved, image) {
+		return resolved, nil
+	}
+
+	resolved, err := s.vmImagesSvc.ResolveImage(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	s.machinePoolScope.AzureMachinePool.Status.Image = resolved
+	return resolved, nil
+}
+
 // Pick image from the machine configuration, or use a default one.
-func getVMImage(scope *scope.MachinePoolScope) (*infrav1.Image, error) {
+func getVMImage(scope *scope.MachinePoolScope, imageCatalog *azure.ImageCatalog) (*infrav1.Image, error) {
 	// Use custom Marketplace image, Image ID or a Shared Image Gallery image if provided
 	if scope.AzureMachinePool.Spec.Template.Image != nil {
 		return scope.AzureMachinePool.Spec.Template.Image, nil
 	}
 	scope.Info("No image specified for machine pool, using default", "machinePool", scope.AzureMachinePool.GetName())
-	return azure.GetDefaultUbuntuImage(to.String(scope.MachinePool.Spec.Template.Spec.Version))
+	return imageCatalog.GetDefaultImage(scope.AzureMachinePool.Spec.Template.OSDisk.OSType, to.String(scope.MachinePool.Spec.Template.Spec.Version))
 }
@@ -277,7 +277,7 @@ func Test_newAzureMachinePoolService(t *testing.T) {
 		},
 	}
 
-	subject := newAzureMachinePoolService(mps, cs)
+	subject := newAzureMachinePoolService(mps, cs, nil)
 	mockCtrl := gomock.NewController(t)
 	svcMock := mock_scalesets.NewMockClient(mockCtrl)
 	svcMock.EXPECT().Delete(gomock.Any(), "resourceGroup", "poolName").Return(nil)
@@ -51,6 +51,11 @@ func newAzureManagedControlPlaneReconciler(scope *scope.ManagedControlPlaneScope
 
 // Reconcile reconciles all the services in pre determined order
 func (r *azureManagedControlPlaneReconciler) Reconcile(ctx context.Context, scope *scope.ManagedControlPlaneScope) error {
+	scope.Logger.V(2).Info("Reconciling SSH key")
+	if err := scope.SetDefaultSSHPublicKey(ctx); err != nil {
+		return errors.Wrapf(err, "failed to reconcile SSH key")
+	}
+
 	managedClusterSpec := &managedclusters.Spec{
 		Name:          scope.ControlPlane.Name,
 		ResourceGroup: scope.ControlPlane.Spec.ResourceGroup,
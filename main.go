@@ -37,6 +37,7 @@ import (
 
 	infrav1alpha2 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha2"
 	infrav1alpha3 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/controllers"
 	infrav1alpha3exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
 	infrav1controllersexp "sigs.k8s.io/cluster-api-provider-azure/exp/controllers"
@@ -82,6 +83,8 @@ var (
 	healthAddr                  string
 	webhookPort                 int
 	reconcileTimeout            time.Duration
+	maxVMProvisioningAttempts   int
+	defaultImageCatalog         string
 )
 
 func InitFlags(fs *pflag.FlagSet) {
@@ -154,6 +157,18 @@ func InitFlags(fs *pflag.FlagSet) {
 		"The maximum duration a reconcile loop can run (e.g. 90m)",
 	)
 
+	fs.IntVar(&maxVMProvisioningAttempts,
+		"max-vm-provisioning-attempts",
+		reconciler.DefaultMaxVMProvisioningAttempts,
+		"Number of times the VM of an AzureMachine is created before the AzureMachine is marked as failed",
+	)
+
+	fs.StringVar(&defaultImageCatalog,
+		"default-image-catalog",
+		"",
+		"Path to a YAML or JSON file mapping the Azure environment, OS type and Kubernetes version of machines to their default image, e.g. mounted from a ConfigMap. If unspecified, the Azure Marketplace images are used.",
+	)
+
 	feature.MutableGates.AddFlag(fs)
 }
 
@@ -200,11 +215,23 @@ func main() {
 	// Initialize event recorder.
 	record.InitFromRecorder(mgr.GetEventRecorderFor("azure-controller"))
 
+	var imageCatalog *azure.ImageCatalog
+	if defaultImageCatalog != "" {
+		imageCatalog, err = azure.LoadImageCatalog(defaultImageCatalog, os.Getenv("AZURE_ENVIRONMENT"))
+		if err != nil {
+			setupLog.Error(err, "unable to load default image catalog")
+			os.Exit(1)
+		}
+		setupLog.Info("Using default image catalog", "path", defaultImageCatalog, "images", len(imageCatalog.Images))
+	}
+
 	if webhookPort == 0 {
 		if err = (&controllers.AzureMachineReconciler{
-			Client:   mgr.GetClient(),
-			Log:      ctrl.Log.WithName("controllers").WithName("AzureMachine"),
-			Recorder: mgr.GetEventRecorderFor("azuremachine-reconciler"),
+			Client:                    mgr.GetClient(),
+			Log:                       ctrl.Log.WithName("controllers").WithName("AzureMachine"),
+			Recorder:                  mgr.GetEventRecorderFor("azuremachine-reconciler"),
+			MaxVMProvisioningAttempts: maxVMProvisioningAttempts,
+			ImageCatalog:              imageCatalog,
 		}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: azureMachineConcurrency}); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "AzureMachine")
 			os.Exit(1)
@@ -221,9 +248,10 @@ func main() {
 		setupLog.V(1).Info(fmt.Sprintf("%+v\n", feature.Gates))
 		if feature.Gates.Enabled(capifeature.MachinePool) {
 			if err = (&infrav1controllersexp.AzureMachinePoolReconciler{
-				Client:   mgr.GetClient(),
-				Log:      ctrl.Log.WithName("controllers").WithName("AzureMachinePool"),
-				Recorder: mgr.GetEventRecorderFor("azurecluster-reconciler"),
+				Client:       mgr.GetClient(),
+				Log:          ctrl.Log.WithName("controllers").WithName("AzureMachinePool"),
+				Recorder:     mgr.GetEventRecorderFor("azurecluster-reconciler"),
+				ImageCatalog: imageCatalog,
 			}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: azureMachinePoolConcurrency}); err != nil {
 				setupLog.Error(err, "unable to create controller", "controller", "AzureMachinePool")
 				os.Exit(1)
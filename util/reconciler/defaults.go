@@ -25,6 +25,9 @@ const (
 	DefaultLoopTimeout = 90 * time.Minute
 	// DefaultMappingTimeout is the default timeout for a controller request mapping func
 	DefaultMappingTimeout = 60 * time.Second
+	// DefaultMaxVMProvisioningAttempts is the default number of times the VM of a machine is created before the
+	// machine is marked as failed
+	DefaultMaxVMProvisioningAttempts = 5
 )
 
 // DefaultedLoopTimeout will default the timeout if it is zero valued
@@ -35,3 +38,12 @@ func DefaultedLoopTimeout(timeout time.Duration) time.Duration {
 
 	return timeout
 }
+
+// DefaultedMaxVMProvisioningAttempts will default the maximum number of VM provisioning attempts if it is zero valued
+func DefaultedMaxVMProvisioningAttempts(attempts int) int {
+	if attempts <= 0 {
+		return DefaultMaxVMProvisioningAttempts
+	}
+
+	return attempts
+}